@@ -0,0 +1,280 @@
+package bayesnet
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+)
+
+type PriorType int
+
+const (
+	// relative frequencies; parent configurations never observed get a uniform distribution
+	MaximumLikelihood PriorType = iota
+	// a Dirichlet prior adding Strength pseudo-counts to every cell of every table
+	Dirichlet
+	// the BDeu prior with equivalent sample size Strength spread uniformly over the
+	// cells of each table (Heckerman, Geiger & Chickering, 1995)
+	BDeu
+)
+
+/*
+Prior
+
+Selects how conditional probability tables are estimated from counts. Estimates under a
+Dirichlet or BDeu prior are posterior means.
+*/
+type Prior struct {
+	Type     PriorType
+	Strength float64
+}
+
+/*
+BayesNet
+
+A discrete Bayesian network: a DAG over categorical nodes with a conditional
+probability table for every node given its parents.
+The table of a node is a Factor over its parents followed by the node itself.
+*/
+type BayesNet struct {
+	graph *graph.Graph
+	nodes []*graph.Node
+	index map[*graph.Node]int
+	cards []int
+	cpts  []*Factor
+}
+
+/*
+FitBayesNet
+
+Estimates the conditional probability tables of the DAG g from categorical data with
+one row per sample and one column per node, in the order of the graph's node list.
+States are coded 0 .. k-1; the number of states k of a node is the size of its domain
+(see graph.Node.SetDomain) and otherwise one more than the largest state observed.
+*/
+func FitBayesNet(g *graph.Graph, data *mat.Dense, prior Prior) (*BayesNet, error) {
+	if !graph.IsDag(g) {
+		return nil, fmt.Errorf("graph must be a DAG")
+	}
+	rows, cols := data.Dims()
+	nodes := g.GetNodes()
+	if cols != len(nodes) {
+		return nil, fmt.Errorf("data has %d columns but the graph has %d nodes", cols, len(nodes))
+	}
+	if prior.Type != MaximumLikelihood && prior.Strength <= 0 {
+		return nil, fmt.Errorf("prior strength must be positive")
+	}
+	cards := make([]int, len(nodes))
+	for j, n := range nodes {
+		cards[j] = len(n.GetDomain())
+		observed := 0
+		for i := 0; i < rows; i++ {
+			v := data.At(i, j)
+			if v < 0 || v != math.Trunc(v) {
+				return nil, fmt.Errorf("value %v of %s is not a state code", v, n.GetName())
+			}
+			if int(v) >= observed {
+				observed = int(v) + 1
+			}
+		}
+		if cards[j] == 0 {
+			cards[j] = observed
+		} else if observed > cards[j] {
+			return nil, fmt.Errorf("value %d of %s is outside its domain of %d states", observed-1, n.GetName(), cards[j])
+		}
+		if cards[j] == 0 {
+			cards[j] = 1
+		}
+	}
+	bn := newBayesNet(g, cards)
+	for j := range nodes {
+		cpt := bn.cpts[j]
+		columns := make([]int, len(cpt.variables))
+		for k, v := range cpt.variables {
+			columns[k] = bn.index[v]
+		}
+		states := make([]int, len(columns))
+		for i := 0; i < rows; i++ {
+			for k, c := range columns {
+				states[k] = int(data.At(i, c))
+			}
+			cpt.values[cpt.offset(states)]++
+		}
+		pseudo := 0.0
+		switch prior.Type {
+		case Dirichlet:
+			pseudo = prior.Strength
+		case BDeu:
+			pseudo = prior.Strength / float64(len(cpt.values))
+		}
+		r := cards[j]
+		for start := 0; start < len(cpt.values); start += r {
+			row := cpt.values[start : start+r]
+			total := 0.0
+			for k := range row {
+				row[k] += pseudo
+				total += row[k]
+			}
+			for k := range row {
+				if total > 0 {
+					row[k] /= total
+				} else {
+					row[k] = 1 / float64(r)
+				}
+			}
+		}
+	}
+	return bn, nil
+}
+
+/*
+NewBayesNet
+
+Returns a Bayesian network over the DAG g whose tables are uniform distributions; the
+number of states of every node is the size of its domain.
+Use SetProbability to fill in the tables.
+*/
+func NewBayesNet(g *graph.Graph) (*BayesNet, error) {
+	if !graph.IsDag(g) {
+		return nil, fmt.Errorf("graph must be a DAG")
+	}
+	nodes := g.GetNodes()
+	cards := make([]int, len(nodes))
+	for i, n := range nodes {
+		cards[i] = len(n.GetDomain())
+		if cards[i] == 0 {
+			return nil, fmt.Errorf("node %s has no domain", n.GetName())
+		}
+	}
+	bn := newBayesNet(g, cards)
+	for j, cpt := range bn.cpts {
+		for k := range cpt.values {
+			cpt.values[k] = 1 / float64(cards[j])
+		}
+	}
+	return bn, nil
+}
+
+func newBayesNet(g *graph.Graph, cards []int) *BayesNet {
+	bn := &BayesNet{
+		graph: g,
+		nodes: g.GetNodes(),
+		index: map[*graph.Node]int{},
+		cards: cards,
+	}
+	for i, n := range bn.nodes {
+		bn.index[n] = i
+	}
+	for _, n := range bn.nodes {
+		variables := append(g.GetParents(n), n)
+		c := make([]int, len(variables))
+		for k, v := range variables {
+			c[k] = cards[bn.index[v]]
+		}
+		bn.cpts = append(bn.cpts, newFactor(variables, c))
+	}
+	return bn
+}
+
+func (bn *BayesNet) GetGraph() *graph.Graph {
+	return bn.graph
+}
+
+func (bn *BayesNet) GetNodes() []*graph.Node {
+	return bn.nodes
+}
+
+/*
+GetNumStates
+
+Returns the number of states of the node.
+*/
+func (bn *BayesNet) GetNumStates(node *graph.Node) int {
+	return bn.cards[bn.index[node]]
+}
+
+/*
+GetCPT
+
+Returns the conditional probability table of the node, a Factor over its parents
+followed by the node.
+*/
+func (bn *BayesNet) GetCPT(node *graph.Node) *Factor {
+	return bn.cpts[bn.index[node]]
+}
+
+/*
+SetProbability
+
+Sets P(node = state | parents = parentStates), the parent states following the order of
+the table's variables.
+*/
+func (bn *BayesNet) SetProbability(node *graph.Node, parentStates []int, state int, p float64) {
+	cpt := bn.GetCPT(node)
+	cpt.values[cpt.offset(append(append([]int{}, parentStates...), state))] = p
+}
+
+/*
+Intervene
+
+Returns the network after the intervention do(node = state) for every entry of values:
+the edges into the intervened nodes are cut (graph.Do) and their tables replaced by
+point masses on the chosen states. Queries on the result are interventional queries on
+the original network.
+*/
+func (bn *BayesNet) Intervene(values map[*graph.Node]int) (*BayesNet, error) {
+	var intervened []*graph.Node
+	for _, n := range bn.nodes {
+		if s, ok := values[n]; ok {
+			if s < 0 || s >= bn.GetNumStates(n) {
+				return nil, fmt.Errorf("state %d of %s out of range", s, n.GetName())
+			}
+			intervened = append(intervened, n)
+		}
+	}
+	if len(intervened) != len(values) {
+		return nil, fmt.Errorf("intervened nodes must belong to the network")
+	}
+	mutilated := newBayesNet(graph.Do(bn.graph, intervened), bn.cards)
+	for j, n := range bn.nodes {
+		if s, ok := values[n]; ok {
+			mutilated.cpts[j].values[s] = 1
+		} else {
+			copy(mutilated.cpts[j].values, bn.cpts[j].values)
+		}
+	}
+	return mutilated, nil
+}
+
+/*
+Sample
+
+Draws n samples by forward sampling in causal order, returned with one column per node
+in the order of the graph's node list.
+*/
+func (bn *BayesNet) Sample(n int, rng *rand.Rand) *mat.Dense {
+	data := mat.NewDense(n, len(bn.nodes), nil)
+	order := graph.GetCausalOrdering(bn.graph)
+	for i := 0; i < n; i++ {
+		for _, node := range order {
+			cpt := bn.GetCPT(node)
+			states := make([]int, len(cpt.variables))
+			for k, v := range cpt.variables[:len(states)-1] {
+				states[k] = int(data.At(i, bn.index[v]))
+			}
+			u := rng.Float64()
+			last := len(states) - 1
+			for s := 0; s < cpt.cards[last]; s++ {
+				states[last] = s
+				u -= cpt.values[cpt.offset(states)]
+				if u < 0 {
+					break
+				}
+			}
+			data.Set(i, bn.index[node], float64(states[last]))
+		}
+	}
+	return data
+}
@@ -0,0 +1,220 @@
+package bayesnet
+
+import (
+	"GoCausal/graph"
+	"fmt"
+)
+
+/*
+Factor
+
+A non-negative table over the joint states of a list of categorical variables, such as
+a conditional probability table or the result of a query. Values are stored with the
+last variable varying fastest.
+*/
+type Factor struct {
+	variables []*graph.Node
+	cards     []int
+	values    []float64
+}
+
+func newFactor(variables []*graph.Node, cards []int) *Factor {
+	size := 1
+	for _, c := range cards {
+		size *= c
+	}
+	return &Factor{
+		variables: append([]*graph.Node{}, variables...),
+		cards:     append([]int{}, cards...),
+		values:    make([]float64, size),
+	}
+}
+
+func (f *Factor) GetVariables() []*graph.Node {
+	return f.variables
+}
+
+func (f *Factor) GetCardinalities() []int {
+	return f.cards
+}
+
+/*
+Get
+
+Returns the value for the given states, one per variable in the factor's order.
+*/
+func (f *Factor) Get(states ...int) float64 {
+	return f.values[f.offset(states)]
+}
+
+/*
+Values
+
+Returns a copy of the table, last variable varying fastest.
+*/
+func (f *Factor) Values() []float64 {
+	return append([]float64{}, f.values...)
+}
+
+func (f *Factor) offset(states []int) int {
+	if len(states) != len(f.variables) {
+		panic(fmt.Sprintf("factor has %d variables, got %d states", len(f.variables), len(states)))
+	}
+	offset := 0
+	for i, s := range states {
+		offset = offset*f.cards[i] + s
+	}
+	return offset
+}
+
+// states decodes a table offset into one state per variable.
+func (f *Factor) states(offset int, dst []int) {
+	for i := len(f.cards) - 1; i >= 0; i-- {
+		dst[i] = offset % f.cards[i]
+		offset /= f.cards[i]
+	}
+}
+
+func (f *Factor) position(v *graph.Node) int {
+	for i, u := range f.variables {
+		if u == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// product returns the factor over the union of the variables of f and g.
+func (f *Factor) product(g *Factor) *Factor {
+	variables := append([]*graph.Node{}, f.variables...)
+	cards := append([]int{}, f.cards...)
+	for i, v := range g.variables {
+		if f.position(v) < 0 {
+			variables = append(variables, v)
+			cards = append(cards, g.cards[i])
+		}
+	}
+	result := newFactor(variables, cards)
+	fromF := make([]int, len(f.variables))
+	fromG := make([]int, len(g.variables))
+	gPositions := make([]int, len(g.variables))
+	for i, v := range g.variables {
+		gPositions[i] = result.position(v)
+	}
+	states := make([]int, len(variables))
+	for offset := range result.values {
+		result.states(offset, states)
+		copy(fromF, states[:len(f.variables)])
+		for i, p := range gPositions {
+			fromG[i] = states[p]
+		}
+		result.values[offset] = f.values[f.offset(fromF)] * g.values[g.offset(fromG)]
+	}
+	return result
+}
+
+// sumOut returns the factor with v marginalized out.
+func (f *Factor) sumOut(v *graph.Node) *Factor {
+	p := f.position(v)
+	if p < 0 {
+		return f
+	}
+	variables := append(append([]*graph.Node{}, f.variables[:p]...), f.variables[p+1:]...)
+	cards := append(append([]int{}, f.cards[:p]...), f.cards[p+1:]...)
+	result := newFactor(variables, cards)
+	states := make([]int, len(f.variables))
+	rest := make([]int, len(variables))
+	for offset, value := range f.values {
+		f.states(offset, states)
+		copy(rest, states[:p])
+		copy(rest[p:], states[p+1:])
+		result.values[result.offset(rest)] += value
+	}
+	return result
+}
+
+// marginal sums out every variable not in keep.
+func (f *Factor) marginal(keep []*graph.Node) *Factor {
+	result := f
+	for _, v := range f.variables {
+		found := false
+		for _, k := range keep {
+			if k == v {
+				found = true
+			}
+		}
+		if !found {
+			result = result.sumOut(v)
+		}
+	}
+	return result
+}
+
+// reduce fixes the observed variables to their states and drops them from the factor.
+func (f *Factor) reduce(evidence map[*graph.Node]int) *Factor {
+	var variables []*graph.Node
+	var cards []int
+	for i, v := range f.variables {
+		if _, ok := evidence[v]; !ok {
+			variables = append(variables, v)
+			cards = append(cards, f.cards[i])
+		}
+	}
+	if len(variables) == len(f.variables) {
+		return f
+	}
+	result := newFactor(variables, cards)
+	states := make([]int, len(f.variables))
+	rest := make([]int, 0, len(variables))
+	for offset, value := range f.values {
+		f.states(offset, states)
+		rest = rest[:0]
+		keep := true
+		for i, v := range f.variables {
+			if s, ok := evidence[v]; ok {
+				keep = keep && states[i] == s
+			} else {
+				rest = append(rest, states[i])
+			}
+		}
+		if keep {
+			result.values[result.offset(rest)] = value
+		}
+	}
+	return result
+}
+
+// normalize scales the table to sum to one and returns the previous total.
+func (f *Factor) normalize() float64 {
+	total := 0.0
+	for _, v := range f.values {
+		total += v
+	}
+	if total > 0 {
+		for i := range f.values {
+			f.values[i] /= total
+		}
+	}
+	return total
+}
+
+// reorder returns the factor with its variables in the given order.
+func (f *Factor) reorder(variables []*graph.Node) *Factor {
+	cards := make([]int, len(variables))
+	positions := make([]int, len(variables))
+	for i, v := range variables {
+		positions[i] = f.position(v)
+		cards[i] = f.cards[positions[i]]
+	}
+	result := newFactor(variables, cards)
+	states := make([]int, len(variables))
+	original := make([]int, len(f.variables))
+	for offset := range result.values {
+		result.states(offset, states)
+		for i, p := range positions {
+			original[p] = states[i]
+		}
+		result.values[offset] = f.values[f.offset(original)]
+	}
+	return result
+}
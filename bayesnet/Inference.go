@@ -0,0 +1,351 @@
+package bayesnet
+
+import (
+	"GoCausal/graph"
+	"GoCausal/utils"
+	"fmt"
+)
+
+func (bn *BayesNet) checkQuery(query []*graph.Node, evidence map[*graph.Node]int) error {
+	for _, n := range query {
+		if _, ok := bn.index[n]; !ok {
+			return fmt.Errorf("node %s is not in the network", n.GetName())
+		}
+		if _, ok := evidence[n]; ok {
+			return fmt.Errorf("node %s is both queried and observed", n.GetName())
+		}
+	}
+	for n, s := range evidence {
+		if _, ok := bn.index[n]; !ok {
+			return fmt.Errorf("node %s is not in the network", n.GetName())
+		}
+		if s < 0 || s >= bn.GetNumStates(n) {
+			return fmt.Errorf("state %d of %s out of range", s, n.GetName())
+		}
+	}
+	return nil
+}
+
+/*
+Query
+
+Returns the posterior distribution P(query | evidence) as a Factor over the query nodes
+in the given order, computed by variable elimination. Only the ancestors of the query
+and evidence nodes are involved; the remaining variables are eliminated greedily,
+always picking the one with the fewest neighbours in the interaction graph.
+Returns an error if the evidence has probability zero.
+*/
+func (bn *BayesNet) Query(query []*graph.Node, evidence map[*graph.Node]int) (*Factor, error) {
+	if err := bn.checkQuery(query, evidence); err != nil {
+		return nil, err
+	}
+	relevant := append([]*graph.Node{}, query...)
+	for n := range evidence {
+		relevant = append(relevant, n)
+	}
+	ancestors := utils.NewSet(bn.graph.GetAncestors(relevant)...)
+
+	var factors []*Factor
+	hidden := utils.NewOrderedSet[*graph.Node]()
+	for j, n := range bn.nodes {
+		if !ancestors.Contains(n) {
+			continue
+		}
+		factors = append(factors, bn.cpts[j].reduce(evidence))
+		if _, observed := evidence[n]; !observed {
+			hidden.Add(n)
+		}
+	}
+	for _, n := range query {
+		hidden.Remove(n)
+	}
+
+	for hidden.Size() > 0 {
+		v := minNeighbours(factors, hidden.Values())
+		hidden.Remove(v)
+		var involved, rest []*Factor
+		for _, f := range factors {
+			if f.position(v) >= 0 {
+				involved = append(involved, f)
+			} else {
+				rest = append(rest, f)
+			}
+		}
+		factors = append(rest, multiply(involved).sumOut(v))
+	}
+	result := multiply(factors)
+	if result.normalize() == 0 {
+		return nil, fmt.Errorf("evidence has probability zero")
+	}
+	return result.reorder(query), nil
+}
+
+// minNeighbours returns the candidate sharing a factor with the fewest other variables.
+func minNeighbours(factors []*Factor, candidates []*graph.Node) *graph.Node {
+	var best *graph.Node
+	bestCount := -1
+	for _, v := range candidates {
+		neighbours := utils.NewSet[*graph.Node]()
+		for _, f := range factors {
+			if f.position(v) >= 0 {
+				neighbours.AddAll(f.variables...)
+			}
+		}
+		if bestCount < 0 || neighbours.Size() < bestCount {
+			best = v
+			bestCount = neighbours.Size()
+		}
+	}
+	return best
+}
+
+func multiply(factors []*Factor) *Factor {
+	result := newFactor(nil, nil)
+	result.values[0] = 1
+	for _, f := range factors {
+		result = result.product(f)
+	}
+	return result
+}
+
+/*
+JunctionTree
+
+A junction tree compiled from a Bayesian network: the cliques of a triangulation of its
+moral graph joined into a tree with the running intersection property, each holding the
+product of the conditional probability tables assigned to it. Calibrating it with some
+evidence yields the posterior marginal of every node at once.
+*/
+type JunctionTree struct {
+	bn        *BayesNet
+	cliques   [][]*graph.Node
+	neighbors [][]int
+	// product of the tables assigned to each clique, before any evidence
+	potentials []*Factor
+}
+
+/*
+NewJunctionTree
+
+Compiles the network: the moral graph is triangulated by eliminating, at each step, the
+node whose elimination adds the fewest fill-in edges, the maximal cliques produced are
+joined by a maximum-weight spanning tree on separator sizes, and every table is assigned
+to a clique containing its family.
+A network without nodes gives a tree without cliques.
+*/
+func NewJunctionTree(bn *BayesNet) *JunctionTree {
+	adjacency := map[*graph.Node]*utils.Set[*graph.Node]{}
+	for _, n := range bn.nodes {
+		adjacency[n] = utils.NewSet[*graph.Node]()
+	}
+	for _, cpt := range bn.cpts {
+		for i, a := range cpt.variables {
+			for _, b := range cpt.variables[i+1:] {
+				adjacency[a].Add(b)
+				adjacency[b].Add(a)
+			}
+		}
+	}
+
+	var cliques [][]*graph.Node
+	remaining := utils.NewOrderedSet(bn.nodes...)
+	for remaining.Size() > 0 {
+		var v *graph.Node
+		best := -1
+		for _, n := range remaining.Values() {
+			fill := 0
+			neighbours := adjacency[n].Values()
+			for i, a := range neighbours {
+				for _, b := range neighbours[i+1:] {
+					if !adjacency[a].Contains(b) {
+						fill++
+					}
+				}
+			}
+			if best < 0 || fill < best {
+				v, best = n, fill
+			}
+		}
+		neighbours := adjacency[v].Values()
+		for i, a := range neighbours {
+			for _, b := range neighbours[i+1:] {
+				adjacency[a].Add(b)
+				adjacency[b].Add(a)
+			}
+		}
+		clique := bn.sorted(append(neighbours, v))
+		maximal := true
+		for _, c := range cliques {
+			if utils.NewSet(clique...).IsSubsetOf(utils.NewSet(c...)) {
+				maximal = false
+				break
+			}
+		}
+		if maximal {
+			cliques = append(cliques, clique)
+		}
+		for _, n := range neighbours {
+			adjacency[n].Remove(v)
+		}
+		remaining.Remove(v)
+	}
+
+	jt := &JunctionTree{bn: bn, cliques: cliques, neighbors: make([][]int, len(cliques))}
+	// Prim's algorithm for the maximum-weight spanning tree
+	inTree := make([]bool, len(cliques))
+	if len(cliques) > 0 {
+		inTree[0] = true
+	}
+	for added := 1; added < len(cliques); added++ {
+		from, to, weight := -1, -1, -1
+		for i := range cliques {
+			if !inTree[i] {
+				continue
+			}
+			for j := range cliques {
+				if inTree[j] {
+					continue
+				}
+				w := utils.NewSet(cliques[i]...).Intersection(utils.NewSet(cliques[j]...)).Size()
+				if w > weight {
+					from, to, weight = i, j, w
+				}
+			}
+		}
+		inTree[to] = true
+		jt.neighbors[from] = append(jt.neighbors[from], to)
+		jt.neighbors[to] = append(jt.neighbors[to], from)
+	}
+
+	for _, clique := range cliques {
+		cards := make([]int, len(clique))
+		for i, n := range clique {
+			cards[i] = bn.GetNumStates(n)
+		}
+		f := newFactor(clique, cards)
+		for i := range f.values {
+			f.values[i] = 1
+		}
+		jt.potentials = append(jt.potentials, f)
+	}
+	for _, cpt := range bn.cpts {
+		family := utils.NewSet(cpt.variables...)
+		for i, clique := range cliques {
+			if family.IsSubsetOf(utils.NewSet(clique...)) {
+				jt.potentials[i] = jt.potentials[i].product(cpt).reorder(clique)
+				break
+			}
+		}
+	}
+	return jt
+}
+
+func (bn *BayesNet) sorted(nodes []*graph.Node) []*graph.Node {
+	set := utils.NewSet(nodes...)
+	var result []*graph.Node
+	for _, n := range bn.nodes {
+		if set.Contains(n) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+func (jt *JunctionTree) GetCliques() [][]*graph.Node {
+	return jt.cliques
+}
+
+/*
+calibrate
+
+Enters the evidence and passes messages towards clique 0 and back (Shafer-Shenoy),
+returning the unnormalized belief of every clique.
+*/
+func (jt *JunctionTree) calibrate(evidence map[*graph.Node]int) []*Factor {
+	k := len(jt.cliques)
+	potentials := make([]*Factor, k)
+	for i, p := range jt.potentials {
+		potentials[i] = p.reduce(evidence)
+	}
+	messages := map[[2]int]*Factor{}
+	var message func(from, to int) *Factor
+	message = func(from, to int) *Factor {
+		if m, ok := messages[[2]int{from, to}]; ok {
+			return m
+		}
+		f := potentials[from]
+		for _, n := range jt.neighbors[from] {
+			if n != to {
+				f = f.product(message(n, from))
+			}
+		}
+		m := f.marginal(jt.cliques[to])
+		messages[[2]int{from, to}] = m
+		return m
+	}
+	beliefs := make([]*Factor, k)
+	for i := range jt.cliques {
+		b := potentials[i]
+		for _, n := range jt.neighbors[i] {
+			b = b.product(message(n, i))
+		}
+		beliefs[i] = b
+	}
+	return beliefs
+}
+
+/*
+Marginals
+
+Returns the posterior distribution of every unobserved node given the evidence,
+computed in one calibration of the tree.
+Returns an error if the evidence has probability zero.
+*/
+func (jt *JunctionTree) Marginals(evidence map[*graph.Node]int) (map[*graph.Node][]float64, error) {
+	if err := jt.bn.checkQuery(nil, evidence); err != nil {
+		return nil, err
+	}
+	beliefs := jt.calibrate(evidence)
+	marginals := map[*graph.Node][]float64{}
+	for _, n := range jt.bn.nodes {
+		if _, observed := evidence[n]; observed {
+			continue
+		}
+		for i, clique := range jt.cliques {
+			if utils.NewSet(clique...).Contains(n) {
+				m := beliefs[i].marginal([]*graph.Node{n})
+				if m.normalize() == 0 {
+					return nil, fmt.Errorf("evidence has probability zero")
+				}
+				marginals[n] = m.values
+				break
+			}
+		}
+	}
+	return marginals, nil
+}
+
+/*
+Query
+
+Returns P(query | evidence) as a Factor over the query nodes in the given order.
+The query nodes must lie together in one clique of the tree; use BayesNet.Query for
+arbitrary sets.
+*/
+func (jt *JunctionTree) Query(query []*graph.Node, evidence map[*graph.Node]int) (*Factor, error) {
+	if err := jt.bn.checkQuery(query, evidence); err != nil {
+		return nil, err
+	}
+	wanted := utils.NewSet(query...)
+	for i, clique := range jt.cliques {
+		if !wanted.IsSubsetOf(utils.NewSet(clique...)) {
+			continue
+		}
+		f := jt.calibrate(evidence)[i].marginal(query)
+		if f.normalize() == 0 {
+			return nil, fmt.Errorf("evidence has probability zero")
+		}
+		return f.reorder(query), nil
+	}
+	return nil, fmt.Errorf("query nodes do not share a clique of the junction tree")
+}
@@ -0,0 +1,258 @@
+package bayesnet
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomNetwork returns a Bayesian network over a random DAG with two or three states
+// per node and tables drawn uniformly from the simplex.
+func randomNetwork(rng *rand.Rand, n int, p float64) *BayesNet {
+	nodes := make([]*graph.Node, n)
+	for i := range nodes {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(fmt.Sprintf("X%d", i+1))
+		nodes[i].SetDomain([]string{"a", "b", "c"}[:2+rng.Intn(2)])
+	}
+	g := graph.NewGraph(nodes)
+	order := rng.Perm(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < p {
+				g.AddDirectedEdge(nodes[order[i]], nodes[order[j]])
+			}
+		}
+	}
+	bn, err := NewBayesNet(g)
+	if err != nil {
+		panic(err)
+	}
+	for j, cpt := range bn.cpts {
+		card := bn.cards[j]
+		for start := 0; start < len(cpt.values); start += card {
+			total := 0.0
+			for k := start; k < start+card; k++ {
+				cpt.values[k] = -math.Log(rng.Float64())
+				total += cpt.values[k]
+			}
+			for k := start; k < start+card; k++ {
+				cpt.values[k] /= total
+			}
+		}
+	}
+	return bn
+}
+
+// bruteMarginal returns P(node | evidence) by summing the joint distribution over all
+// states of the network.
+func bruteMarginal(bn *BayesNet, node *graph.Node, evidence map[*graph.Node]int) []float64 {
+	return bruteIntervention(bn, node, evidence, nil)
+}
+
+// bruteIntervention returns P(node | do(intervention), evidence) by summing the joint
+// distribution of the mutilated network, in which the table of every intervened node
+// is a point mass on its chosen state, over all states of the network.
+func bruteIntervention(bn *BayesNet, node *graph.Node, evidence, intervention map[*graph.Node]int) []float64 {
+	states := make([]int, len(bn.nodes))
+	marginal := make([]float64, bn.GetNumStates(node))
+	for {
+		consistent := true
+		for n, s := range evidence {
+			if states[bn.index[n]] != s {
+				consistent = false
+			}
+		}
+		if consistent {
+			p := 1.0
+			for j, cpt := range bn.cpts {
+				if s, ok := intervention[bn.nodes[j]]; ok {
+					if states[j] != s {
+						p = 0
+					}
+					continue
+				}
+				family := make([]int, len(cpt.variables))
+				for k, v := range cpt.variables {
+					family[k] = states[bn.index[v]]
+				}
+				p *= cpt.Get(family...)
+			}
+			marginal[states[bn.index[node]]] += p
+		}
+		i := 0
+		for ; i < len(states); i++ {
+			states[i]++
+			if states[i] < bn.cards[i] {
+				break
+			}
+			states[i] = 0
+		}
+		if i == len(states) {
+			break
+		}
+	}
+	total := 0.0
+	for _, p := range marginal {
+		total += p
+	}
+	for k := range marginal {
+		marginal[k] /= total
+	}
+	return marginal
+}
+
+func TestInferenceMatchesEnumeration(t *testing.T) {
+	rng := rand.New(rand.NewSource(37))
+	for trial := 0; trial < 50; trial++ {
+		bn := randomNetwork(rng, 3+rng.Intn(5), 0.2+0.5*rng.Float64())
+		evidence := map[*graph.Node]int{}
+		for _, n := range bn.nodes {
+			if rng.Float64() < 0.3 {
+				evidence[n] = rng.Intn(bn.GetNumStates(n))
+			}
+		}
+		jt := NewJunctionTree(bn)
+		marginals, err := jt.Marginals(evidence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range bn.nodes {
+			if _, observed := evidence[n]; observed {
+				continue
+			}
+			want := bruteMarginal(bn, n, evidence)
+			ve, err := bn.Query([]*graph.Node{n}, evidence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := jt.Query([]*graph.Node{n}, evidence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, p := range want {
+				if math.Abs(ve.values[k]-p) > 1e-9 || math.Abs(marginals[n][k]-p) > 1e-9 || math.Abs(query.values[k]-p) > 1e-9 {
+					t.Fatalf("P(%s = %d | evidence): enumeration %.6f, variable elimination %.6f, junction tree %.6f and %.6f",
+						n.GetName(), k, p, ve.values[k], marginals[n][k], query.values[k])
+				}
+			}
+		}
+	}
+}
+
+func TestJunctionTreeOfEmptyNetwork(t *testing.T) {
+	bn, err := NewBayesNet(graph.NewGraph(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jt := NewJunctionTree(bn)
+	if len(jt.GetCliques()) != 0 {
+		t.Errorf("want no cliques, got %d", len(jt.GetCliques()))
+	}
+	marginals, err := jt.Marginals(nil)
+	if err != nil || len(marginals) != 0 {
+		t.Errorf("want no marginals, got %v, %v", marginals, err)
+	}
+}
+
+func TestInterventionMatchesEnumeration(t *testing.T) {
+	// Z --> X --> Y with Z --> Y: Z confounds the effect of X on Y
+	nodes := make([]*graph.Node, 3)
+	for i, name := range []string{"Z", "X", "Y"} {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(name)
+		nodes[i].SetDomain([]string{"0", "1"})
+	}
+	z, x, y := nodes[0], nodes[1], nodes[2]
+	g := graph.NewGraph(nodes)
+	g.AddDirectedEdge(z, x)
+	g.AddDirectedEdge(z, y)
+	g.AddDirectedEdge(x, y)
+	bn, err := NewBayesNet(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for zs := 0; zs < 2; zs++ {
+		px := 0.1 + 0.8*float64(zs)
+		bn.SetProbability(x, []int{zs}, 1, px)
+		bn.SetProbability(x, []int{zs}, 0, 1-px)
+		for xs := 0; xs < 2; xs++ {
+			// the parents of Y are in node order, Z then X
+			py := 0.1 + 0.2*float64(xs) + 0.6*float64(zs)
+			bn.SetProbability(y, []int{zs, xs}, 1, py)
+			bn.SetProbability(y, []int{zs, xs}, 0, 1-py)
+		}
+	}
+
+	do := map[*graph.Node]int{x: 1}
+	mutilated, err := bn.Intervene(do)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mutilated.GetGraph().IsAdjacentTo(z, x) || !mutilated.GetGraph().IsDirectedFromTo(x, y) {
+		t.Fatalf("do(X) must cut Z --> X only:\n%s", mutilated.GetGraph().ToString())
+	}
+	// P(Y = 1 | do(X = 1)) = sum_z P(z) P(Y = 1 | X = 1, z) = 0.6, while P(Y = 1 | X = 1) = 0.84
+	want := bruteIntervention(bn, y, nil, do)
+	observed := bruteMarginal(bn, y, map[*graph.Node]int{x: 1})
+	if math.Abs(want[1]-0.6) > 1e-12 || math.Abs(observed[1]-0.84) > 1e-12 {
+		t.Fatalf("enumeration: got P(Y = 1 | do(X = 1)) = %.3f and P(Y = 1 | X = 1) = %.3f", want[1], observed[1])
+	}
+	ve, err := mutilated.Query([]*graph.Node{y}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	marginals, err := NewJunctionTree(mutilated).Marginals(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range want {
+		if math.Abs(ve.values[k]-want[k]) > 1e-9 || math.Abs(marginals[y][k]-want[k]) > 1e-9 {
+			t.Errorf("P(Y = %d | do(X = 1)): enumeration %.6f, variable elimination %.6f, junction tree %.6f",
+				k, want[k], ve.values[k], marginals[y][k])
+		}
+	}
+
+	if _, err := bn.Intervene(map[*graph.Node]int{x: 2}); err == nil {
+		t.Error("an intervention on a state out of range must fail")
+	}
+
+	rng := rand.New(rand.NewSource(37))
+	for trial := 0; trial < 30; trial++ {
+		bn := randomNetwork(rng, 3+rng.Intn(4), 0.5)
+		do := map[*graph.Node]int{}
+		evidence := map[*graph.Node]int{}
+		for _, n := range bn.nodes {
+			switch r := rng.Float64(); {
+			case r < 0.25:
+				do[n] = rng.Intn(bn.GetNumStates(n))
+			case r < 0.4:
+				evidence[n] = rng.Intn(bn.GetNumStates(n))
+			}
+		}
+		mutilated, err := bn.Intervene(do)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range bn.nodes {
+			_, intervened := do[n]
+			_, observed := evidence[n]
+			if intervened || observed {
+				continue
+			}
+			want := bruteIntervention(bn, n, evidence, do)
+			got, err := mutilated.Query([]*graph.Node{n}, evidence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, p := range want {
+				if math.Abs(got.values[k]-p) > 1e-9 {
+					t.Fatalf("trial %d: P(%s = %d | do, evidence): enumeration %.6f, intervened network %.6f",
+						trial, n.GetName(), k, p, got.values[k])
+				}
+			}
+		}
+	}
+}
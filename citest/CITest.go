@@ -0,0 +1,41 @@
+package citest
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+CITest
+
+A conditional independence test over a fixed list of variables, typically backed by a
+data set with one column per variable. Test returns the p-value of the null hypothesis
+that x and y are independent given z, together with a test statistic measuring the
+strength of the dependence, e.g. a partial correlation.
+*/
+type CITest interface {
+	GetVariables() []*graph.Node
+	Test(x, y *graph.Node, z []*graph.Node) (pValue, statistic float64, err error)
+}
+
+/*
+Factory
+
+Builds a test over the given variables from data with one row per sample and one column
+per variable, for algorithms that construct the data they test on, such as the lagged
+data of time series methods.
+*/
+type Factory func(variables []*graph.Node, data *mat.Dense) (CITest, error)
+
+/*
+IsIndependent
+
+Returns true if the test does not reject the independence of x and y given z at level alpha.
+*/
+func IsIndependent(test CITest, x, y *graph.Node, z []*graph.Node, alpha float64) (bool, error) {
+	p, _, err := test.Test(x, y, z)
+	if err != nil {
+		return false, err
+	}
+	return p > alpha, nil
+}
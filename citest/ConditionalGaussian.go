@@ -0,0 +1,107 @@
+package citest
+
+import (
+	"GoCausal/data"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"sort"
+)
+
+/*
+ConditionalGaussian
+
+The likelihood ratio test of conditional independence for mixed discrete and continuous
+data under the conditional Gaussian model (Andrews et al., 2018), see
+data.DataSet.ConditionalGaussianLikelihood. With L the log-likelihood and d the number
+of parameters of a set of variables, the statistic
+2 (L(x, y, z) - L(x, z) - L(y, z) + L(z)) is compared to a chi-squared distribution with
+d(x, y, z) - d(x, z) - d(y, z) + d(z) degrees of freedom. Variables with a domain are
+discrete. The statistic returned is the likelihood ratio.
+*/
+type ConditionalGaussian struct {
+	dataSet *data.DataSet
+	fits    map[string]likelihoodFit
+}
+
+type likelihoodFit struct {
+	loglik float64
+	dof    int
+}
+
+/*
+NewConditionalGaussian
+
+Returns the test on data with one row per sample and one column per variable.
+*/
+func NewConditionalGaussian(variables []*graph.Node, matrix *mat.Dense) (*ConditionalGaussian, error) {
+	ds, err := data.NewDataSet(variables, matrix)
+	if err != nil {
+		return nil, err
+	}
+	return &ConditionalGaussian{dataSet: ds, fits: map[string]likelihoodFit{}}, nil
+}
+
+func (t *ConditionalGaussian) GetVariables() []*graph.Node {
+	return t.dataSet.GetVariables()
+}
+
+func (t *ConditionalGaussian) Test(x, y *graph.Node, z []*graph.Node) (float64, float64, error) {
+	lr, df := 0.0, 0
+	for _, term := range []struct {
+		nodes []*graph.Node
+		sign  int
+	}{{append([]*graph.Node{x, y}, z...), 1}, {append([]*graph.Node{x}, z...), -1}, {append([]*graph.Node{y}, z...), -1}, {z, 1}} {
+		fit, err := t.fit(term.nodes)
+		if err != nil {
+			return 0, 0, err
+		}
+		lr += 2 * float64(term.sign) * fit.loglik
+		df += term.sign * fit.dof
+	}
+	if df <= 0 {
+		return 1, lr, nil
+	}
+	if lr < 0 {
+		lr = 0
+	}
+	return distuv.ChiSquared{K: float64(df)}.Survival(lr), lr, nil
+}
+
+// fit returns the likelihood of the variables, cached by their set of columns.
+func (t *ConditionalGaussian) fit(nodes []*graph.Node) (likelihoodFit, error) {
+	columns := make([]int, len(nodes))
+	for k, v := range nodes {
+		columns[k] = t.dataSet.GetColumn(v)
+		if columns[k] < 0 {
+			return likelihoodFit{}, fmt.Errorf("variable %s is not covered by the test", v.GetName())
+		}
+	}
+	sort.Ints(columns)
+	key := fmt.Sprint(columns)
+	if fit, ok := t.fits[key]; ok {
+		return fit, nil
+	}
+	loglik, dof, err := t.dataSet.ConditionalGaussianLikelihood(nodes)
+	if err != nil {
+		return likelihoodFit{}, err
+	}
+	t.fits[key] = likelihoodFit{loglik, dof}
+	return t.fits[key], nil
+}
+
+/*
+NewTest
+
+Returns Fisher's z test if all variables are continuous and the conditional Gaussian
+test otherwise. It can serve as a Factory.
+*/
+func NewTest(variables []*graph.Node, data *mat.Dense) (CITest, error) {
+	for _, v := range variables {
+		if len(v.GetDomain()) > 0 {
+			return NewConditionalGaussian(variables, data)
+		}
+	}
+	return NewFisherZ(variables, data)
+}
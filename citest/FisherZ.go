@@ -0,0 +1,128 @@
+package citest
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+)
+
+/*
+FisherZ
+
+Tests vanishing partial correlation for Gaussian data with Fisher's z-transform.
+The statistic is the partial correlation of x and y given z.
+*/
+type FisherZ struct {
+	variables  []*graph.Node
+	index      map[*graph.Node]int
+	covariance *mat.SymDense
+	sampleSize int
+}
+
+/*
+NewFisherZ
+
+Returns the test on data with one row per sample and one column per variable.
+Missing values (NaN) are rejected; see NewTestwiseDeletion, NewMvpcFisherZ and
+NewMultipleImputationFisherZ for data that has them.
+*/
+func NewFisherZ(variables []*graph.Node, data *mat.Dense) (*FisherZ, error) {
+	n, c := data.Dims()
+	if c != len(variables) {
+		return nil, fmt.Errorf("data has %d columns but %d variables were given", c, len(variables))
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < c; j++ {
+			if math.IsNaN(data.At(i, j)) {
+				return nil, fmt.Errorf("value of %s in sample %d is missing", variables[j].GetName(), i)
+			}
+		}
+	}
+	covariance := mat.NewSymDense(c, nil)
+	stat.CovarianceMatrix(covariance, data, nil)
+	return NewFisherZFromCovariance(variables, covariance, n)
+}
+
+/*
+NewFisherZFromCovariance
+
+Returns the test given the sample covariance matrix of the variables and the sample
+size it was computed from.
+*/
+func NewFisherZFromCovariance(variables []*graph.Node, covariance *mat.SymDense, sampleSize int) (*FisherZ, error) {
+	if covariance.Symmetric() != len(variables) {
+		return nil, fmt.Errorf("covariance has %d rows but %d variables were given", covariance.Symmetric(), len(variables))
+	}
+	t := &FisherZ{
+		variables:  variables,
+		index:      map[*graph.Node]int{},
+		covariance: covariance,
+		sampleSize: sampleSize,
+	}
+	for i, v := range variables {
+		t.index[v] = i
+	}
+	return t, nil
+}
+
+func (t *FisherZ) GetVariables() []*graph.Node {
+	return t.variables
+}
+
+func (t *FisherZ) GetSampleSize() int {
+	return t.sampleSize
+}
+
+func (t *FisherZ) Test(x, y *graph.Node, z []*graph.Node) (float64, float64, error) {
+	columns := make([]int, 0, len(z)+2)
+	for _, v := range append([]*graph.Node{x, y}, z...) {
+		i, ok := t.index[v]
+		if !ok {
+			return 0, 0, fmt.Errorf("variable %s is not covered by the test", v.GetName())
+		}
+		columns = append(columns, i)
+	}
+	dof := t.sampleSize - len(z) - 3
+	if dof < 1 {
+		return 0, 0, fmt.Errorf("%d samples are too few to condition on %d variables", t.sampleSize, len(z))
+	}
+	r, err := PartialCorrelation(t.covariance, columns)
+	if err != nil {
+		return 0, 0, err
+	}
+	return fisherZPValue(r, dof), r, nil
+}
+
+/*
+PartialCorrelation
+
+Returns the partial correlation of the first two of the given rows of a covariance
+matrix given the remaining ones, computed from the inverse of their submatrix.
+*/
+func PartialCorrelation(covariance mat.Symmetric, columns []int) (float64, error) {
+	k := len(columns)
+	sub := mat.NewSymDense(k, nil)
+	for a := 0; a < k; a++ {
+		for b := a; b < k; b++ {
+			sub.SetSym(a, b, covariance.At(columns[a], columns[b]))
+		}
+	}
+	var precision mat.Dense
+	if err := precision.Inverse(sub); err != nil {
+		return 0, fmt.Errorf("covariance of the conditioning set is singular")
+	}
+	r := -precision.At(0, 1) / math.Sqrt(precision.At(0, 0)*precision.At(1, 1))
+	return math.Max(-1, math.Min(1, r)), nil
+}
+
+// fisherZPValue returns the two-sided p-value of a partial correlation estimated with
+// dof = n - |z| - 3.
+func fisherZPValue(r float64, dof int) float64 {
+	// keep the transform finite for perfectly correlated samples
+	r = math.Max(-1+1e-15, math.Min(1-1e-15, r))
+	z := math.Atanh(r) * math.Sqrt(float64(dof))
+	return 2 * distuv.UnitNormal.Survival(math.Abs(z))
+}
@@ -0,0 +1,39 @@
+package citest
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFisherZ(t *testing.T) {
+	// X --> Y --> Z
+	rng := rand.New(rand.NewSource(41))
+	nodes := newTestNodes("X", "Y", "Z")
+	x, y, z := nodes[0], nodes[1], nodes[2]
+	data := mat.NewDense(2000, 3, nil)
+	for s := 0; s < 2000; s++ {
+		vx := rng.NormFloat64()
+		vy := vx + rng.NormFloat64()
+		data.SetRow(s, []float64{vx, vy, vy + rng.NormFloat64()})
+	}
+	test, err := NewFisherZ(nodes, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _, err := test.Test(x, z, nil); err != nil || p > 0.01 {
+		t.Errorf("X and Z are dependent, got p = %g, %v", p, err)
+	}
+	if p, _, err := test.Test(x, z, nodes[1:2]); err != nil || p < 0.01 {
+		t.Errorf("X and Z are independent given Y, got p = %g, %v", p, err)
+	}
+	if p, r, err := test.Test(x, y, nil); err != nil || p > 0.01 || math.Abs(r-math.Sqrt(0.5)) > 0.05 {
+		t.Errorf("X and Y have correlation 0.71, got %g with p = %g, %v", r, p, err)
+	}
+
+	data.Set(5, 1, math.NaN())
+	if _, err := NewFisherZ(nodes, data); err == nil {
+		t.Error("want an error for a missing value")
+	}
+}
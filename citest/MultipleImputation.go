@@ -0,0 +1,173 @@
+package citest
+
+import (
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+	"math/rand"
+)
+
+// rounds of chained regressions run to draw each imputed data set
+const imputationIterations = 10
+
+/*
+MultipleImputationFisherZ
+
+Fisher's z test on data with missing values, coded NaN, by multiple imputation: the
+missing values are filled in several times by chained linear regressions, each column
+being drawn in turn from its regression on all the others with coefficients and noise
+drawn around their estimates, and the test is run on every completed data set. The
+z-transformed partial correlations are pooled with Rubin's rules and the pooled
+estimate is tested against a Student t distribution with the degrees of freedom of
+Barnard & Rubin (1999). Valid when values are missing at random given the observed
+variables.
+*/
+type MultipleImputationFisherZ struct {
+	variables []*graph.Node
+	tests     []*FisherZ
+	records   []TestRecord
+}
+
+/*
+NewMultipleImputationFisherZ
+
+Returns the test on data with one row per sample and one column per variable, drawing
+the given number of imputed data sets from rng.
+*/
+func NewMultipleImputationFisherZ(variables []*graph.Node, data *mat.Dense, imputations int, rng *rand.Rand) (*MultipleImputationFisherZ, error) {
+	_, c := data.Dims()
+	if c != len(variables) {
+		return nil, fmt.Errorf("data has %d columns but %d variables were given", c, len(variables))
+	}
+	if imputations < 2 {
+		return nil, fmt.Errorf("multiple imputation needs at least 2 imputations, got %d", imputations)
+	}
+	t := &MultipleImputationFisherZ{variables: variables}
+	for m := 0; m < imputations; m++ {
+		completed, err := imputeChained(data, rng)
+		if err != nil {
+			return nil, err
+		}
+		test, err := NewFisherZ(variables, completed)
+		if err != nil {
+			return nil, err
+		}
+		t.tests = append(t.tests, test)
+	}
+	return t, nil
+}
+
+func (t *MultipleImputationFisherZ) GetVariables() []*graph.Node {
+	return t.variables
+}
+
+/*
+GetRecords
+
+Returns a record of every test run so far, in order; all samples are used.
+*/
+func (t *MultipleImputationFisherZ) GetRecords() []TestRecord {
+	return t.records
+}
+
+func (t *MultipleImputationFisherZ) Test(x, y *graph.Node, z []*graph.Node) (float64, float64, error) {
+	m := float64(len(t.tests))
+	estimates := make([]float64, len(t.tests))
+	for k, test := range t.tests {
+		_, r, err := test.Test(x, y, z)
+		if err != nil {
+			return 0, 0, err
+		}
+		// keep the transform finite for perfectly correlated samples
+		estimates[k] = math.Atanh(math.Max(-1+1e-15, math.Min(1-1e-15, r)))
+	}
+	n := t.tests[0].GetSampleSize()
+	dof := float64(n - len(z) - 3)
+	mean, between := stat.MeanVariance(estimates, nil)
+	within := 1 / dof
+	total := within + (1+1/m)*between
+	statistic := math.Abs(mean) / math.Sqrt(total)
+	var p float64
+	if between == 0 {
+		p = 2 * distuv.UnitNormal.Survival(statistic)
+	} else {
+		lambda := (1 + 1/m) * between / total
+		old := (m - 1) / (lambda * lambda)
+		observed := (dof + 1) / (dof + 3) * dof * (1 - lambda)
+		p = 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: 1 / (1/old + 1/observed)}.Survival(statistic)
+	}
+	t.records = append(t.records, TestRecord{X: x, Y: y, Z: z, Rows: n, PValue: p})
+	return p, math.Tanh(mean), nil
+}
+
+/*
+imputeChained
+
+Returns a copy of data whose NaN entries are filled in, starting from the column means,
+by rounds of regressions of every incomplete column on all the others over the samples
+where it is observed. Imputed values are drawn from the fitted model with coefficients
+perturbed by their standard errors, so that repeated calls give proper multiple
+imputations.
+*/
+func imputeChained(data *mat.Dense, rng *rand.Rand) (*mat.Dense, error) {
+	n, c := data.Dims()
+	completed := mat.DenseCopyOf(data)
+	observed := make([][]int, c)
+	missing := make([][]int, c)
+	for j := 0; j < c; j++ {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			if math.IsNaN(data.At(i, j)) {
+				missing[j] = append(missing[j], i)
+			} else {
+				observed[j] = append(observed[j], i)
+				sum += data.At(i, j)
+			}
+		}
+		if len(observed[j]) == 0 {
+			return nil, fmt.Errorf("column %d has no observed value", j)
+		}
+		for _, i := range missing[j] {
+			completed.Set(i, j, sum/float64(len(observed[j])))
+		}
+	}
+	all := make([]int, c)
+	for j := range all {
+		all[j] = j
+	}
+	for iteration := 0; iteration < imputationIterations; iteration++ {
+		for j := 0; j < c; j++ {
+			if len(missing[j]) == 0 {
+				continue
+			}
+			var regressors []int
+			for k := 0; k < c; k++ {
+				if k != j {
+					regressors = append(regressors, k)
+				}
+			}
+			fit, err := estimate.Regress(selectData(completed, observed[j], all), j, regressors)
+			if err != nil {
+				return nil, fmt.Errorf("imputing column %d: %v", j, err)
+			}
+			intercept := fit.Intercept + fit.InterceptStandardError*rng.NormFloat64()
+			coefficients := make([]float64, len(regressors))
+			for k := range coefficients {
+				coefficients[k] = fit.Coefficients[k] + fit.StandardErrors[k]*rng.NormFloat64()
+			}
+			sd := math.Sqrt(fit.ResidualVariance)
+			for _, i := range missing[j] {
+				v := intercept + sd*rng.NormFloat64()
+				for k, r := range regressors {
+					v += coefficients[k] * completed.At(i, r)
+				}
+				completed.Set(i, j, v)
+			}
+		}
+	}
+	return completed, nil
+}
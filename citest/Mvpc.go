@@ -0,0 +1,176 @@
+package citest
+
+import (
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"math"
+	"math/rand"
+)
+
+/*
+MvpcFisherZ
+
+Fisher's z test on data with missing values, coded NaN, corrected for the missingness
+mechanism as in MVPC (Tu et al., 2019). At construction, the causes of the missingness
+of every incomplete variable are taken to be the variables its missingness indicator is
+found dependent on at level alpha. A test of x and y given z whose variables have no
+such causes outside x, y and z is run by test-wise deletion, which is then unbiased.
+Otherwise it is run by permutation-based correction: x, y and z are regressed on the
+outside causes w over the samples where all are observed, and virtual samples are built
+from the fitted models and their residuals with values of w drawn from all the samples
+where w is observed, which undoes the selection the missingness made on w.
+*/
+type MvpcFisherZ struct {
+	variables []*graph.Node
+	index     map[*graph.Node]int
+	data      *mat.Dense
+	causes    [][]int
+	rng       *rand.Rand
+	records   []TestRecord
+}
+
+/*
+NewMvpcFisherZ
+
+Returns the test on data with one row per sample and one column per variable; alpha is
+the level at which causes of missingness are detected and rng drives the correction.
+*/
+func NewMvpcFisherZ(variables []*graph.Node, data *mat.Dense, alpha float64, rng *rand.Rand) (*MvpcFisherZ, error) {
+	n, c := data.Dims()
+	if c != len(variables) {
+		return nil, fmt.Errorf("data has %d columns but %d variables were given", c, len(variables))
+	}
+	t := &MvpcFisherZ{variables: variables, index: map[*graph.Node]int{}, data: data, causes: make([][]int, c), rng: rng}
+	for i, v := range variables {
+		t.index[v] = i
+	}
+	indicator := make([]float64, 0, n)
+	values := make([]float64, 0, n)
+	for j := 0; j < c; j++ {
+		if len(completeRows(data, []int{j})) == n {
+			continue
+		}
+		for k := 0; k < c; k++ {
+			if k == j {
+				continue
+			}
+			indicator, values = indicator[:0], values[:0]
+			for _, i := range completeRows(data, []int{k}) {
+				r := 0.0
+				if math.IsNaN(data.At(i, j)) {
+					r = 1
+				}
+				indicator = append(indicator, r)
+				values = append(values, data.At(i, k))
+			}
+			if len(values) < 4 {
+				continue
+			}
+			r := stat.Correlation(indicator, values, nil)
+			if !math.IsNaN(r) && fisherZPValue(r, len(values)-3) <= alpha {
+				t.causes[j] = append(t.causes[j], k)
+			}
+		}
+	}
+	return t, nil
+}
+
+func (t *MvpcFisherZ) GetVariables() []*graph.Node {
+	return t.variables
+}
+
+/*
+GetMissingnessCauses
+
+Returns the variables found to cause the missingness of the given one.
+*/
+func (t *MvpcFisherZ) GetMissingnessCauses(v *graph.Node) []*graph.Node {
+	var causes []*graph.Node
+	for _, k := range t.causes[t.index[v]] {
+		causes = append(causes, t.variables[k])
+	}
+	return causes
+}
+
+/*
+GetRecords
+
+Returns a record of every test run so far, in order, flagging the corrected ones.
+*/
+func (t *MvpcFisherZ) GetRecords() []TestRecord {
+	return t.records
+}
+
+func (t *MvpcFisherZ) Test(x, y *graph.Node, z []*graph.Node) (float64, float64, error) {
+	nodes := append([]*graph.Node{x, y}, z...)
+	columns, err := columnsOf(t.index, nodes)
+	if err != nil {
+		return 0, 0, err
+	}
+	involved := map[int]bool{}
+	for _, j := range columns {
+		involved[j] = true
+	}
+	var outside []int
+	for _, j := range columns {
+		for _, k := range t.causes[j] {
+			if !involved[k] {
+				involved[k] = true
+				outside = append(outside, k)
+			}
+		}
+	}
+
+	rows := completeRows(t.data, append(append([]int{}, columns...), outside...))
+	if len(rows) == 0 {
+		return 0, 0, fmt.Errorf("no sample has %s all observed", namesOf(nodes))
+	}
+	var data *mat.Dense
+	if len(outside) == 0 {
+		data = selectData(t.data, rows, columns)
+	} else if data, err = t.virtualData(rows, columns, outside); err != nil {
+		return 0, 0, err
+	}
+	test, err := NewFisherZ(nodes, data)
+	if err != nil {
+		return 0, 0, err
+	}
+	p, statistic, err := test.Test(x, y, z)
+	if err != nil {
+		return 0, 0, fmt.Errorf("on %d complete samples: %v", len(rows), err)
+	}
+	t.records = append(t.records, TestRecord{X: x, Y: y, Z: z, Rows: len(rows), PValue: p, Corrected: len(outside) > 0})
+	return p, statistic, nil
+}
+
+// virtualData returns, for the given complete rows, virtual samples of the columns
+// whose dependence on the outside causes follows the fitted regressions while the
+// causes follow their distribution over all samples where they are observed.
+func (t *MvpcFisherZ) virtualData(rows, columns, outside []int) (*mat.Dense, error) {
+	all := append(append([]int{}, columns...), outside...)
+	complete := selectData(t.data, rows, all)
+	regressors := make([]int, len(outside))
+	for k := range outside {
+		regressors[k] = len(columns) + k
+	}
+	pool := completeRows(t.data, outside)
+	drawn := t.rng.Perm(len(pool))[:len(rows)]
+	virtual := mat.NewDense(len(rows), len(columns), nil)
+	for l := range columns {
+		fit, err := estimate.Regress(complete, l, regressors)
+		if err != nil {
+			return nil, fmt.Errorf("correcting for missingness: %v", err)
+		}
+		for k := range rows {
+			v := fit.Intercept + fit.Residuals[k]
+			for r, j := range outside {
+				v += fit.Coefficients[r] * t.data.At(pool[drawn[k]], j)
+			}
+			virtual.Set(k, l, v)
+		}
+	}
+	return virtual, nil
+}
@@ -0,0 +1,121 @@
+package citest
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func newTestNodes(names ...string) []*graph.Node {
+	nodes := make([]*graph.Node, len(names))
+	for i, name := range names {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(name)
+	}
+	return nodes
+}
+
+// colliderMissingness samples independent X and Y with their common child W, where X is
+// missing more often the larger W is, so that deleting incomplete samples conditions on
+// a descendant of the collider.
+func colliderMissingness(rng *rand.Rand, samples int) ([]*graph.Node, *mat.Dense) {
+	nodes := newTestNodes("X", "Y", "W")
+	data := mat.NewDense(samples, 3, nil)
+	for s := 0; s < samples; s++ {
+		x, y := rng.NormFloat64(), rng.NormFloat64()
+		w := x + y + 0.5*rng.NormFloat64()
+		if rng.Float64() < 1/(1+math.Exp(-2*w)) {
+			x = math.NaN()
+		}
+		data.SetRow(s, []float64{x, y, w})
+	}
+	return nodes, data
+}
+
+func TestMvpcCorrectsMissingnessCausedByCollider(t *testing.T) {
+	rng := rand.New(rand.NewSource(47))
+	nodes, data := colliderMissingness(rng, 5000)
+	x, y, w := nodes[0], nodes[1], nodes[2]
+
+	deletion, err := NewTestwiseDeletion(nodes, data, func(v []*graph.Node, d *mat.Dense) (CITest, error) {
+		return NewFisherZ(v, d)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _, err := deletion.Test(x, y, nil); err != nil || p > 0.01 {
+		t.Fatalf("test-wise deletion is biased here and should reject, got p = %g, %v", p, err)
+	}
+
+	mvpc, err := NewMvpcFisherZ(nodes, data, 0.01, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	causes := mvpc.GetMissingnessCauses(x)
+	found := false
+	for _, c := range causes {
+		found = found || c == w
+	}
+	if !found {
+		t.Fatalf("W causes the missingness of X, got %v", causes)
+	}
+	p, _, err := mvpc.Test(x, y, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p < 0.01 {
+		t.Errorf("corrected test rejects the true independence of X and Y, p = %g", p)
+	}
+	records := mvpc.GetRecords()
+	if len(records) != 1 || !records[0].Corrected {
+		t.Errorf("want one corrected test record, got %+v", records)
+	}
+	if p, _, err := mvpc.Test(x, w, nil); err != nil || p > 0.01 {
+		t.Errorf("X and W are dependent, got p = %g, %v", p, err)
+	}
+}
+
+func TestMultipleImputationRecoversChain(t *testing.T) {
+	// X --> Y --> Z with Y missing at random given X
+	rng := rand.New(rand.NewSource(47))
+	nodes := newTestNodes("X", "Y", "Z")
+	x, y, z := nodes[0], nodes[1], nodes[2]
+	samples := 3000
+	data := mat.NewDense(samples, 3, nil)
+	for s := 0; s < samples; s++ {
+		vx := rng.NormFloat64()
+		vy := 0.8*vx + rng.NormFloat64()
+		vz := 0.8*vy + rng.NormFloat64()
+		if vx > 0.5 && rng.Float64() < 0.6 {
+			vy = math.NaN()
+		}
+		data.SetRow(s, []float64{vx, vy, vz})
+	}
+	if _, err := NewMultipleImputationFisherZ(nodes, data, 1, rng); err == nil {
+		t.Error("a single imputation must be rejected")
+	}
+	test, err := NewMultipleImputationFisherZ(nodes, data, 10, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		x, y        *graph.Node
+		z           []*graph.Node
+		independent bool
+	}{
+		{x, y, nil, false},
+		{x, z, nil, false},
+		{y, z, []*graph.Node{x}, false},
+		{x, z, []*graph.Node{y}, true},
+	} {
+		p, _, err := test.Test(c.x, c.y, c.z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (p > 0.01) != c.independent {
+			t.Errorf("%s, %s given %s: p = %g, want independent %v", c.x.GetName(), c.y.GetName(), namesOf(c.z), p, c.independent)
+		}
+	}
+}
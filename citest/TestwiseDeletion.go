@@ -0,0 +1,140 @@
+package citest
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+/*
+TestRecord
+
+Describes one test run by a test on data with missing values: the hypothesis, the number
+of samples the test was computed from, its p-value, and whether it was corrected for the
+missingness mechanism.
+*/
+type TestRecord struct {
+	X, Y      *graph.Node
+	Z         []*graph.Node
+	Rows      int
+	PValue    float64
+	Corrected bool
+}
+
+/*
+TestwiseDeletion
+
+Runs a test on data with missing values, coded NaN, using for every hypothesis the
+samples on which x, y and z are all observed. The underlying test is built by the
+factory on those samples and columns. This is unbiased when values are missing
+completely at random, and more generally when the missingness of x, y and z does not
+depend on variables outside them (Tu et al., 2019).
+*/
+type TestwiseDeletion struct {
+	variables []*graph.Node
+	index     map[*graph.Node]int
+	data      *mat.Dense
+	factory   Factory
+	records   []TestRecord
+}
+
+func NewTestwiseDeletion(variables []*graph.Node, data *mat.Dense, factory Factory) (*TestwiseDeletion, error) {
+	_, c := data.Dims()
+	if c != len(variables) {
+		return nil, fmt.Errorf("data has %d columns but %d variables were given", c, len(variables))
+	}
+	t := &TestwiseDeletion{variables: variables, index: map[*graph.Node]int{}, data: data, factory: factory}
+	for i, v := range variables {
+		t.index[v] = i
+	}
+	return t, nil
+}
+
+func (t *TestwiseDeletion) GetVariables() []*graph.Node {
+	return t.variables
+}
+
+/*
+GetRecords
+
+Returns a record of every test run so far, in order.
+*/
+func (t *TestwiseDeletion) GetRecords() []TestRecord {
+	return t.records
+}
+
+func (t *TestwiseDeletion) Test(x, y *graph.Node, z []*graph.Node) (float64, float64, error) {
+	nodes := append([]*graph.Node{x, y}, z...)
+	columns, err := columnsOf(t.index, nodes)
+	if err != nil {
+		return 0, 0, err
+	}
+	rows := completeRows(t.data, columns)
+	if len(rows) == 0 {
+		return 0, 0, fmt.Errorf("no sample has %s all observed", namesOf(nodes))
+	}
+	test, err := t.factory(nodes, selectData(t.data, rows, columns))
+	if err != nil {
+		return 0, 0, err
+	}
+	p, statistic, err := test.Test(x, y, z)
+	if err != nil {
+		return 0, 0, fmt.Errorf("on %d complete samples: %v", len(rows), err)
+	}
+	t.records = append(t.records, TestRecord{X: x, Y: y, Z: z, Rows: len(rows), PValue: p})
+	return p, statistic, nil
+}
+
+func columnsOf(index map[*graph.Node]int, nodes []*graph.Node) ([]int, error) {
+	columns := make([]int, len(nodes))
+	for k, v := range nodes {
+		i, ok := index[v]
+		if !ok {
+			return nil, fmt.Errorf("variable %s is not covered by the test", v.GetName())
+		}
+		columns[k] = i
+	}
+	return columns, nil
+}
+
+// completeRows returns the rows of data with no NaN in the given columns.
+func completeRows(data *mat.Dense, columns []int) []int {
+	n, _ := data.Dims()
+	var rows []int
+	for i := 0; i < n; i++ {
+		complete := true
+		for _, j := range columns {
+			if math.IsNaN(data.At(i, j)) {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// selectData copies the given rows, of which there must be some, and columns of data.
+func selectData(data *mat.Dense, rows, columns []int) *mat.Dense {
+	sub := mat.NewDense(len(rows), len(columns), nil)
+	for k, i := range rows {
+		for l, j := range columns {
+			sub.Set(k, l, data.At(i, j))
+		}
+	}
+	return sub
+}
+
+func namesOf(nodes []*graph.Node) string {
+	names := ""
+	for k, v := range nodes {
+		if k > 0 {
+			names += ", "
+		}
+		names += v.GetName()
+	}
+	return names
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"io"
+)
+
+/*
+runCompare
+
+Compares a learned graph with the true one, reporting the precision and recall of the
+adjacencies and arrowheads and the structural Hamming distance.
+*/
+func runCompare(args []string, stdout io.Writer) error {
+	fs := newFlagSet("compare")
+	positional, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	truth, err := readGraph(positional[0])
+	if err != nil {
+		return err
+	}
+	learned, err := readGraph(positional[1])
+	if err != nil {
+		return err
+	}
+	c, err := graph.CompareGraphs(truth, learned)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout,
+		"adjacency precision %.3f recall %.3f (true positives %d, false positives %d, false negatives %d)\n"+
+			"arrowhead precision %.3f recall %.3f (true positives %d, false positives %d, false negatives %d)\n"+
+			"structural Hamming distance %d\n",
+		c.GetAdjacencyPrecision(), c.GetAdjacencyRecall(),
+		c.AdjacencyTruePositives, c.AdjacencyFalsePositives, c.AdjacencyFalseNegatives,
+		c.GetArrowheadPrecision(), c.GetArrowheadRecall(),
+		c.ArrowheadTruePositives, c.ArrowheadFalsePositives, c.ArrowheadFalseNegatives,
+		c.StructuralHammingDistance)
+	return err
+}
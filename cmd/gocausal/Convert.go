@@ -0,0 +1,29 @@
+package main
+
+import "io"
+
+/*
+runConvert
+
+Reads a graph and writes it in another format, to a file or the standard output.
+*/
+func runConvert(args []string, stdout io.Writer) error {
+	fs := newFlagSet("convert")
+	format := fs.String("format", "", "output format: tetrad, json or dot; by default by the extension of the output, else tetrad")
+	positional, err := parseArgs(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	out := ""
+	if len(positional) == 2 {
+		out = positional[1]
+	}
+	if *format, err = graphFormat(*format, out); err != nil {
+		return &usageError{fs, err}
+	}
+	g, err := readGraph(positional[0])
+	if err != nil {
+		return err
+	}
+	return writeGraph(g, *format, out, stdout)
+}
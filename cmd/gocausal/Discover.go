@@ -0,0 +1,166 @@
+package main
+
+import (
+	"GoCausal/citest"
+	"GoCausal/data"
+	"GoCausal/graph"
+	"GoCausal/score"
+	"GoCausal/search"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+runDiscover
+
+Learns a graph from the data of a delimited file with the chosen algorithm: PC and GES
+give a pattern, FCI, RFCI and GFCI a PAG. Missing values are handled by test-wise
+deletion in the tests; the scores reject them.
+*/
+func runDiscover(args []string, stdout io.Writer) error {
+	fs := newFlagSet("discover")
+	algorithm := fs.String("alg", "pc", "algorithm: pc, fci, rfci, ges or gfci")
+	testName := fs.String("test", "auto", "independence test: fisherz, cg (conditional Gaussian) or auto, which picks by the data")
+	scoreName := fs.String("score", "auto", "score of ges and gfci: bic, cg (conditional Gaussian BIC) or auto, which picks by the data")
+	alpha := fs.Float64("alpha", 0.05, "level of the independence tests")
+	depth := fs.Int("depth", -1, "largest conditioning set; negative for no limit")
+	penalty := fs.Float64("penalty", 1, "penalty discount of the score")
+	delimiter := fs.String("delimiter", "", "field delimiter of the data, a single character or \"tab\"; by default a tab for .tsv and .tab files and a comma otherwise")
+	discrete := fs.String("discrete", "", "comma separated columns to read as discrete even if numeric")
+	format := fs.String("format", "", "output format: tetrad, json or dot; by default by the extension of -out, else tetrad")
+	out := fs.String("out", "", "file to write the graph to; standard output by default")
+	positional, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	options := data.ReaderOptions{Discrete: splitList(*discrete)}
+	switch {
+	case *delimiter == "tab":
+		options.Delimiter = '\t'
+	case utf8.RuneCountInString(*delimiter) == 1:
+		options.Delimiter, _ = utf8.DecodeRuneInString(*delimiter)
+	case *delimiter != "":
+		return &usageError{fs, fmt.Errorf("delimiter must be a single character or \"tab\", got %q", *delimiter)}
+	}
+	if *alpha <= 0 || *alpha >= 1 {
+		return &usageError{fs, fmt.Errorf("alpha must be between 0 and 1, got %v", *alpha)}
+	}
+	if *format, err = graphFormat(*format, *out); err != nil {
+		return &usageError{fs, err}
+	}
+	usesTest := map[string]bool{"pc": true, "fci": true, "rfci": true, "gfci": true}
+	usesScore := map[string]bool{"ges": true, "gfci": true}
+	if !usesTest[*algorithm] && !usesScore[*algorithm] {
+		return &usageError{fs, fmt.Errorf("unknown algorithm %q", *algorithm)}
+	}
+	switch *testName {
+	case "auto", "fisherz", "cg":
+	default:
+		return &usageError{fs, fmt.Errorf("unknown test %q", *testName)}
+	}
+	switch *scoreName {
+	case "auto", "bic", "cg":
+	default:
+		return &usageError{fs, fmt.Errorf("unknown score %q", *scoreName)}
+	}
+
+	ds, err := data.ReadFile(positional[0], options)
+	if err != nil {
+		return err
+	}
+	var test citest.CITest
+	if usesTest[*algorithm] {
+		if test, err = newTest(ds, *testName); err != nil {
+			return err
+		}
+	}
+	var s score.Score
+	if usesScore[*algorithm] {
+		if s, err = newScore(ds, *scoreName, *penalty); err != nil {
+			return err
+		}
+	}
+
+	pcOptions := search.PcOptions{Alpha: *alpha, Depth: *depth}
+	var g *graph.Graph
+	switch *algorithm {
+	case "pc":
+		g, err = search.Pc(test, pcOptions)
+	case "fci":
+		g, err = search.Fci(test, pcOptions)
+	case "rfci":
+		g, err = search.Rfci(test, pcOptions)
+	case "ges":
+		g, err = search.Ges(s)
+	case "gfci":
+		g, err = search.Gfci(test, s, pcOptions)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", strings.ToUpper(*algorithm), err)
+	}
+	return writeGraph(g, *format, *out, stdout)
+}
+
+// newTest returns the named independence test on the data set. With missing values
+// every test uses the samples complete for its variables (test-wise deletion).
+func newTest(ds *data.DataSet, name string) (citest.CITest, error) {
+	var factory citest.Factory
+	switch name {
+	case "auto":
+		factory = citest.NewTest
+	case "fisherz":
+		if !ds.IsContinuous() {
+			return nil, fmt.Errorf("fisherz needs continuous data; use -test cg for discrete columns")
+		}
+		factory = func(variables []*graph.Node, matrix *mat.Dense) (citest.CITest, error) {
+			return citest.NewFisherZ(variables, matrix)
+		}
+	case "cg":
+		factory = func(variables []*graph.Node, matrix *mat.Dense) (citest.CITest, error) {
+			return citest.NewConditionalGaussian(variables, matrix)
+		}
+	default:
+		return nil, fmt.Errorf("unknown test %q", name)
+	}
+	if hasMissingValues(ds) {
+		return citest.NewTestwiseDeletion(ds.GetVariables(), ds.GetData(), factory)
+	}
+	return factory(ds.GetVariables(), ds.GetData())
+}
+
+// newScore returns the named score on the data set, which must have no missing values.
+func newScore(ds *data.DataSet, name string, penalty float64) (score.Score, error) {
+	if hasMissingValues(ds) {
+		return nil, fmt.Errorf("the scores of ges and gfci cannot handle missing values; use pc, fci or rfci")
+	}
+	switch name {
+	case "auto":
+		return search.NewScore(ds, penalty)
+	case "bic":
+		if !ds.IsContinuous() {
+			return nil, fmt.Errorf("bic needs continuous data; use -score cg for discrete columns")
+		}
+		return score.NewBic(ds.GetVariables(), ds.GetData(), penalty)
+	case "cg":
+		return score.NewConditionalGaussianBic(ds, penalty), nil
+	}
+	return nil, fmt.Errorf("unknown score %q", name)
+}
+
+func hasMissingValues(ds *data.DataSet) bool {
+	matrix := ds.GetData()
+	rows, columns := matrix.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < columns; j++ {
+			if math.IsNaN(matrix.At(i, j)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+runDsep
+
+Reports whether two nodes of a graph are d-separated, or m-separated in a MAG, given
+the other nodes named.
+*/
+func runDsep(args []string, stdout io.Writer) error {
+	fs := newFlagSet("dsep")
+	positional, err := parseArgs(fs, args, 3, -1)
+	if err != nil {
+		return err
+	}
+	g, err := readGraph(positional[0])
+	if err != nil {
+		return err
+	}
+	nodes := make([]*graph.Node, len(positional)-1)
+	for i, name := range positional[1:] {
+		if nodes[i] = g.GetNode(name); nodes[i] == nil {
+			return fmt.Errorf("%s has no node %s", positional[0], name)
+		}
+	}
+	x, y, z := nodes[0], nodes[1], nodes[2:]
+	given := ""
+	if len(z) > 0 {
+		given = " given " + strings.Join(positional[3:], ", ")
+	}
+	relation := "d-connected"
+	if g.IsDSeparatedFrom(x, y, z) {
+		relation = "d-separated"
+	}
+	_, err = fmt.Fprintf(stdout, "%s and %s are %s%s\n", x.GetName(), y.GetName(), relation, given)
+	return err
+}
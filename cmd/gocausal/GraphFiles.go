@@ -0,0 +1,97 @@
+package main
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// graph file formats, by name and by file extension
+var (
+	graphFormats    = []string{"tetrad", "json", "dot"}
+	graphExtensions = map[string]string{".json": "json", ".dot": "dot", ".gv": "dot"}
+)
+
+/*
+graphFormat
+
+Returns the format to write a graph to the path in: the given one if not empty, else
+the one of the extension of the path, else the text format of Tetrad.
+*/
+func graphFormat(format, path string) (string, error) {
+	if format == "" {
+		if f, ok := graphExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+			return f, nil
+		}
+		return "tetrad", nil
+	}
+	for _, f := range graphFormats {
+		if f == format {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown graph format %q, expected one of %s", format, strings.Join(graphFormats, ", "))
+}
+
+/*
+readGraph
+
+Reads a graph from a file in JSON if its extension is .json and in the text format of
+Tetrad otherwise; "-" reads from the standard input.
+*/
+func readGraph(path string) (*graph.Graph, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	format, _ := graphFormat("", path)
+	var g *graph.Graph
+	var err error
+	switch format {
+	case "json":
+		g, err = graph.ReadJson(r)
+	case "dot":
+		err = fmt.Errorf("reading graphs in the DOT language is not supported")
+	default:
+		g, err = graph.ReadTetrad(r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return g, nil
+}
+
+/*
+writeGraph
+
+Writes a graph in the given format to a file, or to stdout if the path is empty or "-".
+*/
+func writeGraph(g *graph.Graph, format, path string, stdout io.Writer) error {
+	write := graph.WriteTetrad
+	switch format {
+	case "json":
+		write = graph.WriteJson
+	case "dot":
+		write = graph.WriteDot
+	}
+	if path == "" || path == "-" {
+		return write(g, stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(g, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
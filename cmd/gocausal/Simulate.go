@@ -0,0 +1,73 @@
+package main
+
+import (
+	"GoCausal/data"
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+/*
+runSimulate
+
+Draws a random DAG and a random linear SEM over it, and writes data sampled from the
+model to a file or the standard output, and the DAG to the file given by -graph.
+*/
+func runSimulate(args []string, stdout io.Writer) error {
+	fs := newFlagSet("simulate")
+	numNodes := fs.Int("nodes", 10, "number of variables")
+	numEdges := fs.Int("edges", -1, "number of edges; as many as variables by default, at most all pairs")
+	samples := fs.Int("samples", 1000, "number of samples")
+	seed := fs.Int64("seed", 1, "seed of the random number generator")
+	graphPath := fs.String("graph", "", "file to write the true DAG to")
+	graphFormatName := fs.String("graph-format", "", "format of the DAG: tetrad, json or dot; by default by the extension of -graph, else tetrad")
+	positional, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	if *numEdges < 0 {
+		*numEdges = *numNodes
+		if pairs := *numNodes * (*numNodes - 1) / 2; pairs < *numEdges {
+			*numEdges = pairs
+		}
+	}
+	if *numNodes < 1 || *samples < 1 {
+		return &usageError{fs, fmt.Errorf("nodes and samples must be positive")}
+	}
+	if *graphFormatName, err = graphFormat(*graphFormatName, *graphPath); err != nil {
+		return &usageError{fs, err}
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	nodes := make([]*graph.Node, *numNodes)
+	for i := range nodes {
+		nodes[i] = data.NewContinuousVariable(fmt.Sprintf("X%d", i+1))
+	}
+	dag, err := graph.RandomDag(nodes, *numEdges, rng)
+	if err != nil {
+		return &usageError{fs, err}
+	}
+	sem, err := estimate.RandomLinearSEM(dag, rng)
+	if err != nil {
+		return err
+	}
+	samplesData, err := sem.Simulate(*samples, rng)
+	if err != nil {
+		return err
+	}
+	ds, err := data.NewDataSet(nodes, samplesData)
+	if err != nil {
+		return err
+	}
+	if *graphPath != "" {
+		if err := writeGraph(dag, *graphFormatName, *graphPath, stdout); err != nil {
+			return err
+		}
+	}
+	if len(positional) == 0 || positional[0] == "-" {
+		return data.Write(stdout, ds, data.WriterOptions{})
+	}
+	return data.WriteFile(positional[0], ds, data.WriterOptions{})
+}
@@ -0,0 +1,181 @@
+/*
+gocausal runs causal discovery end to end from the command line.
+
+	gocausal discover [flags] data.csv     learn a graph from data
+	gocausal dsep graph.txt X Y [Z ...]    decide whether X and Y are d-separated given Z
+	gocausal convert [flags] in [out]      convert a graph between formats
+	gocausal compare truth.txt learned.txt compare a learned graph with the true one
+	gocausal simulate [flags] [data.csv]   simulate data from a random linear SEM
+
+Graphs are read from and written to files in the text format of Tetrad, the default,
+or JSON, chosen by the extension .json; they can also be written in the DOT language of
+Graphviz, extension .dot. Data are read from and written to delimited text files with
+a header. The exit code is 0 on success, 1 if the command fails and 2 if it is used
+incorrectly.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// exit codes
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type command struct {
+	synopsis string
+	run      func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"discover": {"discover [flags] data.csv", runDiscover},
+	"dsep":     {"dsep graph.txt X Y [Z ...]", runDsep},
+	"convert":  {"convert [flags] input [output]", runConvert},
+	"compare":  {"compare truth.txt learned.txt", runCompare},
+	"simulate": {"simulate [flags] [data.csv]", runSimulate},
+}
+
+var commandOrder = []string{"discover", "dsep", "convert", "compare", "simulate"}
+
+/*
+usageError
+
+An error in the way a command is invoked, reported with the usage of the command.
+*/
+type usageError struct {
+	fs  *flag.FlagSet
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command named by the first argument and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+		return exitOk
+	}
+	c, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "gocausal: unknown command %q\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+	err := c.run(args[1:], stdout)
+	var usage *usageError
+	switch {
+	case err == nil:
+		return exitOk
+	case errors.As(err, &usage) && errors.Is(err, flag.ErrHelp):
+		printCommandUsage(stdout, c, usage.fs)
+		return exitOk
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "gocausal %s: %v\n", args[0], err)
+		printCommandUsage(stderr, c, usage.fs)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "gocausal %s: %v\n", args[0], err)
+		return exitError
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: gocausal <command> [arguments]\n\ncommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "  %s\n", commands[name].synopsis)
+	}
+	fmt.Fprintln(w, "\nrun gocausal <command> -h for the flags of a command")
+}
+
+func printCommandUsage(w io.Writer, c command, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "usage: gocausal %s\n", c.synopsis)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// newFlagSet returns a flag set for the named command that leaves reporting errors to
+// run.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+/*
+parseArgs
+
+Parses the flags of args, which may come before, between or after the positional
+arguments, and returns the positional arguments, checking there are between min and max
+of them; a negative max means no limit. The arguments after -- are all positional.
+*/
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, &usageError{fs, err}
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			// everything after -- is positional, even if it starts with -
+			positional = append(positional, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	if len(positional) < min || max >= 0 && len(positional) > max {
+		return nil, &usageError{fs, fmt.Errorf("expected %s, got %d", argumentCount(min, max), len(positional))}
+	}
+	return positional, nil
+}
+
+func argumentCount(min, max int) string {
+	switch {
+	case min == 1 && max == 1:
+		return "1 argument"
+	case min == max:
+		return fmt.Sprintf("%d arguments", min)
+	case max < 0:
+		return fmt.Sprintf("at least %d arguments", min)
+	default:
+		return fmt.Sprintf("%d to %d arguments", min, max)
+	}
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeChainWithMissing writes samples of X --> Y --> Z, every tenth value of Y being
+// "NA", and returns the path of the file.
+func writeChainWithMissing(t *testing.T, dir string) string {
+	t.Helper()
+	rng := rand.New(rand.NewSource(50))
+	var b strings.Builder
+	b.WriteString("X,Y,Z\n")
+	for s := 0; s < 2000; s++ {
+		x := rng.NormFloat64()
+		y := x + rng.NormFloat64()
+		z := y + rng.NormFloat64()
+		if s%10 == 0 {
+			fmt.Fprintf(&b, "%g,NA,%g\n", x, z)
+		} else {
+			fmt.Fprintf(&b, "%g,%g,%g\n", x, y, z)
+		}
+	}
+	path := filepath.Join(dir, "missing.csv")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	graphPath := filepath.Join(dir, "truth.txt")
+	dataPath := filepath.Join(dir, "data.csv")
+	missingPath := writeChainWithMissing(t, dir)
+	// later cases read the files the first simulate case writes
+	cases := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout []string
+		stderr []string
+	}{
+		{"simulate", []string{"simulate", "-nodes", "4", "-samples", "500", "-graph", graphPath, dataPath}, exitOk, nil, nil},
+		{"simulate two nodes to stdout", []string{"simulate", "-nodes", "2", "-samples", "3"}, exitOk, []string{"X1,X2\n"}, nil},
+		{"simulate one node", []string{"simulate", "-nodes", "1", "-samples", "3"}, exitOk, []string{"X1\n"}, nil},
+		{"simulate without samples", []string{"simulate", "-samples", "0"}, exitUsage, nil, []string{"must be positive", "usage: gocausal simulate"}},
+		{"simulate too many edges", []string{"simulate", "-nodes", "3", "-edges", "4"}, exitUsage, nil, []string{"usage: gocausal simulate"}},
+		{"no command", nil, exitUsage, nil, []string{"usage: gocausal <command>"}},
+		{"help", []string{"help"}, exitOk, []string{"usage: gocausal <command>"}, nil},
+		{"unknown command", []string{"learn"}, exitUsage, nil, []string{`unknown command "learn"`}},
+		{"command help", []string{"discover", "-h"}, exitOk, []string{"usage: gocausal discover", "-alg"}, nil},
+		{"discover pc", []string{"discover", dataPath}, exitOk, []string{"Graph Nodes:\nX1;X2;X3;X4"}, nil},
+		{"discover ges", []string{"discover", "-alg", "ges", "-format", "json", dataPath}, exitOk, []string{`"X1"`}, nil},
+		{"discover with missing values", []string{"discover", "-alg", "pc", missingPath}, exitOk, []string{"X --- Y", "Y --- Z"}, nil},
+		{"discover fci with missing values", []string{"discover", "-alg", "fci", missingPath}, exitOk, []string{"X o-o Y", "Y o-o Z"}, nil},
+		{"score with missing values", []string{"discover", "-alg", "ges", missingPath}, exitError, nil, []string{"missing values"}},
+		{"fisherz on discrete data", []string{"discover", "-test", "fisherz", "-discrete", "X1", dataPath}, exitError, nil, []string{"fisherz needs continuous data"}},
+		{"bic on discrete data", []string{"discover", "-alg", "ges", "-score", "bic", "-discrete", "X1", dataPath}, exitError, nil, []string{"bic needs continuous data"}},
+		{"unknown algorithm", []string{"discover", "-alg", "lingam", dataPath}, exitUsage, nil, []string{`unknown algorithm "lingam"`}},
+		{"unknown test", []string{"discover", "-test", "kci", dataPath}, exitUsage, nil, []string{`unknown test "kci"`}},
+		{"unknown score", []string{"discover", "-alg", "ges", "-score", "bdeu", dataPath}, exitUsage, nil, []string{`unknown score "bdeu"`}},
+		{"flags after --", []string{"discover", "--", "-alg"}, exitError, nil, []string{"-alg"}},
+		{"bad alpha", []string{"discover", "-alpha", "2", dataPath}, exitUsage, nil, []string{"alpha must be between 0 and 1"}},
+		{"missing data file", []string{"discover", filepath.Join(dir, "none.csv")}, exitError, nil, []string{"gocausal discover:"}},
+		{"too many arguments", []string{"discover", dataPath, dataPath}, exitUsage, nil, []string{"expected 1 argument, got 2"}},
+		{"dsep", []string{"dsep", graphPath, "X1", "X2"}, exitOk, []string{"X1 and X2 are d-"}, nil},
+		{"dsep unknown node", []string{"dsep", graphPath, "X1", "Q"}, exitError, nil, []string{"has no node Q"}},
+		{"compare", []string{"compare", graphPath, graphPath}, exitOk, []string{"structural Hamming distance 0"}, nil},
+		{"convert", []string{"convert", "-format", "dot", graphPath}, exitOk, []string{"digraph"}, nil},
+	}
+	for _, c := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(c.args, &stdout, &stderr)
+		if code != c.code {
+			t.Errorf("%s: exit code %d, want %d; stderr:\n%s", c.name, code, c.code, stderr.String())
+			continue
+		}
+		for _, want := range c.stdout {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("%s: stdout lacks %q:\n%s", c.name, want, stdout.String())
+			}
+		}
+		for _, want := range c.stderr {
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("%s: stderr lacks %q:\n%s", c.name, want, stderr.String())
+			}
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		args       []string
+		positional []string
+		alg        string
+	}{
+		{[]string{"a.csv"}, []string{"a.csv"}, "pc"},
+		{[]string{"-alg", "ges", "a.csv"}, []string{"a.csv"}, "ges"},
+		{[]string{"a.csv", "-alg", "ges", "b.csv"}, []string{"a.csv", "b.csv"}, "ges"},
+		{[]string{"--", "-data.csv"}, []string{"-data.csv"}, "pc"},
+		{[]string{"-alg", "fci", "--", "-data.csv", "-alg", "ges"}, []string{"-data.csv", "-alg", "ges"}, "fci"},
+		{[]string{"a.csv", "--", "-b.csv"}, []string{"a.csv", "-b.csv"}, "pc"},
+	}
+	for _, c := range cases {
+		fs := newFlagSet("test")
+		alg := fs.String("alg", "pc", "")
+		positional, err := parseArgs(fs, c.args, 0, -1)
+		if err != nil {
+			t.Errorf("%q: %v", c.args, err)
+			continue
+		}
+		if strings.Join(positional, " ") != strings.Join(c.positional, " ") || *alg != c.alg {
+			t.Errorf("%q: got %q and -alg %s, want %q and -alg %s", c.args, positional, *alg, c.positional, c.alg)
+		}
+	}
+}
+
+func TestRunDataFileStartingWithDash(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "-data.csv"), []byte("X,Y\n1,2\n2,1\n3,5\n4,3\n5,6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"discover", "-alg", "pc", "--", "-data.csv"}, &stdout, &stderr); code != exitOk {
+		t.Fatalf("exit code %d, want %d; stderr:\n%s", code, exitOk, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "X;Y") {
+		t.Errorf("want the graph over X and Y, got\n%s", stdout.String())
+	}
+}
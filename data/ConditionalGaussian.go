@@ -0,0 +1,125 @@
+package data
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"strings"
+)
+
+/*
+ConditionalGaussianLikelihood
+
+Returns the maximized log-likelihood of the conditional Gaussian model of the variables
+(Lauritzen & Wermuth, 1989) and its number of free parameters: the discrete variables
+follow a multinomial distribution over the cells of their categories, and the continuous
+ones a multivariate Gaussian in each cell with its own mean and covariance. Only the
+cells that occur count. A cell with too few samples to estimate a covariance uses the
+covariance over all samples. The log-likelihood of no variable is zero. Conditional
+likelihoods follow as differences, e.g. of x given z as that of x and z minus that of z.
+Returns an error if a value is missing or the covariance is singular.
+*/
+func (ds *DataSet) ConditionalGaussianLikelihood(variables []*graph.Node) (float64, int, error) {
+	if len(variables) == 0 {
+		return 0, 0, nil
+	}
+	var discrete, continuous []int
+	for _, v := range variables {
+		j := ds.GetColumn(v)
+		if j < 0 {
+			return 0, 0, fmt.Errorf("variable %s is not in the data set", v.GetName())
+		}
+		if ds.IsDiscrete(v) {
+			discrete = append(discrete, j)
+		} else {
+			continuous = append(continuous, j)
+		}
+	}
+	columns := append(append([]int{}, discrete...), continuous...)
+	n := ds.GetNumRows()
+	cells := map[string][]int{}
+	var order []string
+	for i := 0; i < n; i++ {
+		var key strings.Builder
+		for _, j := range columns {
+			if math.IsNaN(ds.data.At(i, j)) {
+				return 0, 0, fmt.Errorf("row %d has a missing value", i)
+			}
+		}
+		for _, j := range discrete {
+			key.WriteRune(rune(ds.data.At(i, j)))
+		}
+		if _, ok := cells[key.String()]; !ok {
+			order = append(order, key.String())
+		}
+		cells[key.String()] = append(cells[key.String()], i)
+	}
+
+	k := len(continuous)
+	loglik := 0.0
+	var pooled *mat.Cholesky
+	for _, key := range order {
+		rows := cells[key]
+		m := float64(len(rows))
+		loglik += m * math.Log(m/float64(n))
+		if k == 0 {
+			continue
+		}
+		mean, covariance := moments(ds.data, rows, continuous)
+		var chol mat.Cholesky
+		if len(rows) <= k || !chol.Factorize(covariance) {
+			if pooled == nil {
+				all := make([]int, n)
+				for i := range all {
+					all[i] = i
+				}
+				_, total := moments(ds.data, all, continuous)
+				pooled = &mat.Cholesky{}
+				if !pooled.Factorize(total) {
+					return 0, 0, fmt.Errorf("covariance of the continuous variables is singular")
+				}
+			}
+			chol = *pooled
+		}
+		x := mat.NewVecDense(k, nil)
+		var solved mat.VecDense
+		quadratic := 0.0
+		for _, i := range rows {
+			for l, j := range continuous {
+				x.SetVec(l, ds.data.At(i, j)-mean[l])
+			}
+			if err := chol.SolveVecTo(&solved, x); err != nil {
+				return 0, 0, fmt.Errorf("covariance of the continuous variables is singular")
+			}
+			quadratic += mat.Dot(x, &solved)
+		}
+		loglik -= (m*(float64(k)*math.Log(2*math.Pi)+chol.LogDet()) + quadratic) / 2
+	}
+	c := len(cells)
+	return loglik, c - 1 + c*(k+k*(k+1)/2), nil
+}
+
+// moments returns the mean and the maximum likelihood covariance of the columns over
+// the rows.
+func moments(data *mat.Dense, rows, columns []int) ([]float64, *mat.SymDense) {
+	k := len(columns)
+	m := float64(len(rows))
+	mean := make([]float64, k)
+	for _, i := range rows {
+		for l, j := range columns {
+			mean[l] += data.At(i, j) / m
+		}
+	}
+	covariance := mat.NewSymDense(k, nil)
+	for a := 0; a < k; a++ {
+		for b := a; b < k; b++ {
+			s := 0.0
+			for _, i := range rows {
+				s += (data.At(i, columns[a]) - mean[a]) * (data.At(i, columns[b]) - mean[b])
+			}
+			covariance.SetSym(a, b, s/m)
+		}
+	}
+	return mean, covariance
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distmv"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestConditionalGaussianLikelihoodOfContinuousData(t *testing.T) {
+	rng := rand.New(rand.NewSource(48))
+	variables := []*graph.Node{NewContinuousVariable("X"), NewContinuousVariable("Y"), NewContinuousVariable("Z")}
+	n := 500
+	matrix := mat.NewDense(n, 3, nil)
+	for i := 0; i < n; i++ {
+		x := rng.NormFloat64()
+		y := 0.5*x + rng.NormFloat64()
+		z := x - y + 2*rng.NormFloat64()
+		matrix.SetRow(i, []float64{x, y, z})
+	}
+	ds, err := NewDataSet(variables, matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subset := range [][]*graph.Node{variables[:1], variables[1:], variables} {
+		columns := make([]int, len(subset))
+		for k, v := range subset {
+			columns[k] = ds.GetColumn(v)
+		}
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		mean, covariance := moments(matrix, all, columns)
+		normal, ok := distmv.NewNormal(mean, covariance, nil)
+		if !ok {
+			t.Fatal("covariance is not positive definite")
+		}
+		want := 0.0
+		row := make([]float64, len(columns))
+		for i := 0; i < n; i++ {
+			for k, j := range columns {
+				row[k] = matrix.At(i, j)
+			}
+			want += normal.LogProb(row)
+		}
+		got, dof, err := ds.ConditionalGaussianLikelihood(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-want) > 1e-8*math.Abs(want) {
+			t.Errorf("%d variables: log-likelihood %.6f, Gaussian log-likelihood %.6f", len(subset), got, want)
+		}
+		k := len(subset)
+		if dof != k+k*(k+1)/2 {
+			t.Errorf("%d variables: %d parameters, want %d", k, dof, k+k*(k+1)/2)
+		}
+	}
+}
+
+func TestConditionalGaussianLikelihoodOfDiscreteData(t *testing.T) {
+	a := NewDiscreteVariable("A", []string{"0", "1"})
+	b := NewDiscreteVariable("B", []string{"0", "1", "2"})
+	// cells (0, 0) twice, (1, 2) once and (1, 0) once; the other cells do not occur
+	matrix := mat.NewDense(4, 2, []float64{0, 0, 0, 0, 1, 2, 1, 0})
+	ds, err := NewDataSet([]*graph.Node{a, b}, matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, dof, err := ds.ConditionalGaussianLikelihood([]*graph.Node{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 2*math.Log(0.5) + 2*math.Log(0.25)
+	if math.Abs(got-want) > 1e-12 || dof != 2 {
+		t.Errorf("got %.6f with %d parameters, want %.6f with 2", got, dof, want)
+	}
+
+	matrix.Set(1, 0, math.NaN())
+	if _, _, err := ds.ConditionalGaussianLikelihood([]*graph.Node{a}); err == nil {
+		t.Error("want an error for a missing value")
+	}
+}
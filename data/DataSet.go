@@ -0,0 +1,167 @@
+package data
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+/*
+DataSet
+
+A table with one row per sample and one column per variable. A variable with a domain
+(see graph.Node.SetDomain) is discrete, its column holding category codes
+0 .. k-1; any other variable is continuous. Missing values are NaN.
+*/
+type DataSet struct {
+	variables []*graph.Node
+	index     map[*graph.Node]int
+	data      *mat.Dense
+}
+
+/*
+NewDataSet
+
+Returns the data set over the variables. Returns an error if the columns do not match
+the variables or a discrete column holds a value that is not a category code.
+*/
+func NewDataSet(variables []*graph.Node, data *mat.Dense) (*DataSet, error) {
+	n, c := data.Dims()
+	if c != len(variables) {
+		return nil, fmt.Errorf("data has %d columns but %d variables were given", c, len(variables))
+	}
+	ds := &DataSet{variables: variables, index: map[*graph.Node]int{}, data: data}
+	for j, v := range variables {
+		if _, ok := ds.index[v]; ok {
+			return nil, fmt.Errorf("variable %s is given twice", v.GetName())
+		}
+		ds.index[v] = j
+		k := len(v.GetDomain())
+		if k == 0 {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			x := data.At(i, j)
+			if !math.IsNaN(x) && (x < 0 || x >= float64(k) || x != math.Trunc(x)) {
+				return nil, fmt.Errorf("value %v in row %d of %s is not one of its %d category codes", x, i, v.GetName(), k)
+			}
+		}
+	}
+	return ds, nil
+}
+
+/*
+NewContinuousVariable
+
+Returns a measured variable with continuous values.
+*/
+func NewContinuousVariable(name string) *graph.Node {
+	node := &graph.Node{}
+	node.SetName(name)
+	node.SetNodeType(graph.MEASURED)
+	return node
+}
+
+/*
+NewDiscreteVariable
+
+Returns a measured variable taking the given categories, coded 0 .. len(categories)-1.
+*/
+func NewDiscreteVariable(name string, categories []string) *graph.Node {
+	node := NewContinuousVariable(name)
+	node.SetDomain(categories)
+	return node
+}
+
+func (ds *DataSet) GetVariables() []*graph.Node {
+	return ds.variables
+}
+
+func (ds *DataSet) GetData() *mat.Dense {
+	return ds.data
+}
+
+func (ds *DataSet) GetNumRows() int {
+	n, _ := ds.data.Dims()
+	return n
+}
+
+/*
+GetVariable
+
+Returns the variable with the given name, or nil if there is none.
+*/
+func (ds *DataSet) GetVariable(name string) *graph.Node {
+	for _, v := range ds.variables {
+		if v.GetName() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+/*
+GetColumn
+
+Returns the column of the variable, or -1 if it is not in the data set.
+*/
+func (ds *DataSet) GetColumn(v *graph.Node) int {
+	if j, ok := ds.index[v]; ok {
+		return j
+	}
+	return -1
+}
+
+func (ds *DataSet) IsDiscrete(v *graph.Node) bool {
+	return len(v.GetDomain()) > 0
+}
+
+/*
+IsContinuous
+
+Returns true if no variable of the data set is discrete.
+*/
+func (ds *DataSet) IsContinuous() bool {
+	for _, v := range ds.variables {
+		if ds.IsDiscrete(v) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+GetCategory
+
+Returns the category label of the value in the given row of a discrete variable, or ""
+if the value is missing or the variable is continuous.
+*/
+func (ds *DataSet) GetCategory(row int, v *graph.Node) string {
+	categories := v.GetDomain()
+	x := ds.data.At(row, ds.index[v])
+	if len(categories) == 0 || math.IsNaN(x) {
+		return ""
+	}
+	return categories[int(x)]
+}
+
+/*
+Subset
+
+Returns the data set restricted to the given variables, in that order.
+*/
+func (ds *DataSet) Subset(variables []*graph.Node) (*DataSet, error) {
+	n, _ := ds.data.Dims()
+	sub := mat.NewDense(n, len(variables), nil)
+	for k, v := range variables {
+		j, ok := ds.index[v]
+		if !ok {
+			return nil, fmt.Errorf("variable %s is not in the data set", v.GetName())
+		}
+		for i := 0; i < n; i++ {
+			sub.Set(i, k, ds.data.At(i, j))
+		}
+	}
+	return NewDataSet(variables, sub)
+}
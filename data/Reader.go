@@ -0,0 +1,167 @@
+package data
+
+import (
+	"GoCausal/graph"
+	"encoding/csv"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ReaderOptions
+
+Delimiter separates the fields; zero means a tab for files ending in .tsv or .tab and a
+comma otherwise. Fields equal to one of MissingMarkers, after trimming spaces, are
+missing values; nil means "", "NA", "NaN", "?" and "*". Columns named in Discrete are
+read as categorical even if all their values are numbers.
+*/
+type ReaderOptions struct {
+	Delimiter      rune
+	MissingMarkers []string
+	Discrete       []string
+}
+
+/*
+ReadFile
+
+Reads a data set from a delimited text file, see Read.
+*/
+func ReadFile(path string, options ReaderOptions) (*DataSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if options.Delimiter == 0 {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".tsv", ".tab":
+			options.Delimiter = '\t'
+		}
+	}
+	ds, err := Read(f, options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return ds, nil
+}
+
+/*
+Read
+
+Reads a data set from delimited text whose first record holds the column names. A
+column is continuous if all its values that are not missing parse as numbers, and
+discrete otherwise; the categories of a discrete column are its distinct values in
+sorted order, numerically when they are all numbers, coded 0 .. k-1. A variable named
+after each column is created, with a domain when discrete. Missing values become NaN.
+*/
+func Read(r io.Reader, options ReaderOptions) (*DataSet, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ','
+	if options.Delimiter != 0 {
+		reader.Comma = options.Delimiter
+	}
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no header")
+	}
+	if len(records) == 1 {
+		return nil, fmt.Errorf("no data rows")
+	}
+	header, rows := records[0], records[1:]
+	names := map[string]bool{}
+	for _, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("empty column name in header")
+		}
+		if names[name] {
+			return nil, fmt.Errorf("column %s appears twice in header", name)
+		}
+		names[name] = true
+	}
+	markers := options.MissingMarkers
+	if markers == nil {
+		markers = []string{"", "NA", "NaN", "?", "*"}
+	}
+	missing := map[string]bool{}
+	for _, m := range markers {
+		missing[m] = true
+	}
+	discrete := map[string]bool{}
+	for _, name := range options.Discrete {
+		if !names[name] {
+			return nil, fmt.Errorf("discrete column %s is not in header", name)
+		}
+		discrete[name] = true
+	}
+
+	matrix := mat.NewDense(len(rows), len(header), nil)
+	var variables []*graph.Node
+	for j, name := range header {
+		name = strings.TrimSpace(name)
+		values := make([]string, len(rows))
+		numbers := true
+		for i, row := range rows {
+			values[i] = strings.TrimSpace(row[j])
+			if missing[values[i]] {
+				continue
+			}
+			if _, err := strconv.ParseFloat(values[i], 64); err != nil {
+				numbers = false
+			}
+		}
+		if numbers && !discrete[name] {
+			for i, v := range values {
+				x := math.NaN()
+				if !missing[v] {
+					x, _ = strconv.ParseFloat(v, 64)
+				}
+				matrix.Set(i, j, x)
+			}
+			variables = append(variables, NewContinuousVariable(name))
+			continue
+		}
+		codes := map[string]int{}
+		for _, v := range values {
+			if !missing[v] {
+				codes[v] = 0
+			}
+		}
+		categories := make([]string, 0, len(codes))
+		for v := range codes {
+			categories = append(categories, v)
+		}
+		sort.Strings(categories)
+		if numbers {
+			// keep the order of numeric levels, 2 before 10
+			sort.SliceStable(categories, func(a, b int) bool {
+				x, _ := strconv.ParseFloat(categories[a], 64)
+				y, _ := strconv.ParseFloat(categories[b], 64)
+				return x < y
+			})
+		}
+		for k, v := range categories {
+			codes[v] = k
+		}
+		for i, v := range values {
+			x := math.NaN()
+			if !missing[v] {
+				x = float64(codes[v])
+			}
+			matrix.Set(i, j, x)
+		}
+		variables = append(variables, NewDiscreteVariable(name, categories))
+	}
+	return NewDataSet(variables, matrix)
+}
@@ -0,0 +1,133 @@
+package data
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	nan := math.NaN()
+	cases := []struct {
+		name    string
+		input   string
+		options ReaderOptions
+		columns []string
+		// nil for a continuous column
+		domains [][]string
+		rows    [][]float64
+		err     string
+	}{
+		{
+			name:    "continuous",
+			input:   "X, Y\n1, 2.5\n-3, 1e2\n",
+			columns: []string{"X", "Y"},
+			domains: [][]string{nil, nil},
+			rows:    [][]float64{{1, 2.5}, {-3, 100}},
+		},
+		{
+			name:    "tab delimited",
+			input:   "X\tY\n1\ta\n2\tb\n",
+			options: ReaderOptions{Delimiter: '\t'},
+			columns: []string{"X", "Y"},
+			domains: [][]string{nil, {"a", "b"}},
+			rows:    [][]float64{{1, 0}, {2, 1}},
+		},
+		{
+			name:    "default missing markers",
+			input:   "X,Y,Z\nNA,a,1\n2,?,*\n,NaN,3\n",
+			columns: []string{"X", "Y", "Z"},
+			domains: [][]string{nil, {"a"}, nil},
+			rows:    [][]float64{{nan, 0, 1}, {2, nan, nan}, {nan, nan, 3}},
+		},
+		{
+			name:    "custom missing markers",
+			input:   "X,Y\n-99,NA\n1,b\n",
+			options: ReaderOptions{MissingMarkers: []string{"-99"}},
+			columns: []string{"X", "Y"},
+			domains: [][]string{nil, {"NA", "b"}},
+			rows:    [][]float64{{nan, 0}, {1, 1}},
+		},
+		{
+			name:    "mixed numbers and text are discrete",
+			input:   "X\n10\nlow\n2\n",
+			columns: []string{"X"},
+			domains: [][]string{{"10", "2", "low"}},
+			rows:    [][]float64{{0}, {2}, {1}},
+		},
+		{
+			name:    "numeric levels sort numerically",
+			input:   "X,Y\n1,1\n10,2\n2,NA\n",
+			options: ReaderOptions{Discrete: []string{"X"}},
+			columns: []string{"X", "Y"},
+			domains: [][]string{{"1", "2", "10"}, nil},
+			rows:    [][]float64{{0, 1}, {2, 2}, {1, nan}},
+		},
+		{
+			name:    "negative and decimal levels",
+			input:   "X\n0.5\n-1\n-2.5\n0.5\n",
+			options: ReaderOptions{Discrete: []string{"X"}},
+			columns: []string{"X"},
+			domains: [][]string{{"-2.5", "-1", "0.5"}},
+			rows:    [][]float64{{2}, {1}, {0}, {2}},
+		},
+		{name: "discrete column not in header", input: "X\n1\n", options: ReaderOptions{Discrete: []string{"Y"}}, err: "discrete column Y is not in header"},
+		{name: "ragged rows", input: "X,Y\n1,2\n3\n", err: "wrong number of fields"},
+		{name: "empty input", input: "", err: "no header"},
+		{name: "header only", input: "X,Y\n", err: "no data rows"},
+		{name: "duplicate column", input: "X,X\n1,2\n", err: "column X appears twice"},
+		{name: "empty column name", input: "X,\n1,2\n", err: "empty column name"},
+	}
+	for _, c := range cases {
+		ds, err := Read(strings.NewReader(c.input), c.options)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: want an error containing %q, got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		for j, v := range ds.GetVariables() {
+			if v.GetName() != c.columns[j] {
+				t.Errorf("%s: column %d is named %q, want %q", c.name, j, v.GetName(), c.columns[j])
+			}
+			if domain := v.GetDomain(); len(domain) != len(c.domains[j]) || len(domain) > 0 && !reflect.DeepEqual(domain, c.domains[j]) {
+				t.Errorf("%s: domain of %s is %v, want %v", c.name, v.GetName(), domain, c.domains[j])
+			}
+		}
+		for i, row := range c.rows {
+			for j, want := range row {
+				got := ds.GetData().At(i, j)
+				if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+					t.Errorf("%s: value (%d, %d) is %v, want %v", c.name, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestReadFileGuessesDelimiter(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"data.tsv", "data.tab"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("X\tY\n1\t2\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ds, err := ReadFile(path, ReaderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ds.GetVariables()) != 2 || ds.GetData().At(0, 1) != 2 {
+			t.Errorf("%s: want two tab separated columns, got %d", name, len(ds.GetVariables()))
+		}
+	}
+	if _, err := ReadFile(filepath.Join(dir, "none.csv"), ReaderOptions{}); err == nil {
+		t.Error("reading a missing file must fail")
+	}
+}
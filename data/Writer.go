@@ -0,0 +1,85 @@
+package data
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+WriterOptions
+
+Delimiter separates the fields; zero means a tab for files ending in .tsv or .tab and a
+comma otherwise. MissingMarker is written for missing values, an empty field by default.
+*/
+type WriterOptions struct {
+	Delimiter     rune
+	MissingMarker string
+}
+
+/*
+WriteFile
+
+Writes the data set to a delimited text file, see Write.
+*/
+func WriteFile(path string, ds *DataSet, options WriterOptions) error {
+	if options.Delimiter == 0 {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".tsv", ".tab":
+			options.Delimiter = '\t'
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, ds, options); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+/*
+Write
+
+Writes the data set as delimited text in the format Read reads: a header with the names
+of the variables, then one record per row with the category labels of the discrete
+variables and the shortest exact representation of the continuous values.
+*/
+func Write(w io.Writer, ds *DataSet, options WriterOptions) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = ','
+	if options.Delimiter != 0 {
+		writer.Comma = options.Delimiter
+	}
+	record := make([]string, len(ds.variables))
+	for j, v := range ds.variables {
+		record[j] = v.GetName()
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	for i := 0; i < ds.GetNumRows(); i++ {
+		for j, v := range ds.variables {
+			x := ds.data.At(i, j)
+			switch {
+			case math.IsNaN(x):
+				record[j] = options.MissingMarker
+			case ds.IsDiscrete(v):
+				record[j] = ds.GetCategory(i, v)
+			default:
+				record[j] = strconv.FormatFloat(x, 'g', -1, 64)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
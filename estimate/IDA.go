@@ -0,0 +1,205 @@
+package estimate
+
+import (
+	"GoCausal/graph"
+	"GoCausal/identify"
+	"GoCausal/utils"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+IdaResult
+
+The multiset of possible causal effects of x on y returned by IDA, one entry per
+orientation of the edges around x that is allowed by the pattern, together with the set
+that was adjusted for to obtain it.
+*/
+type IdaResult struct {
+	Effects        []float64
+	AdjustmentSets [][]*graph.Node
+}
+
+/*
+Min
+
+Returns the smallest possible effect, a lower bound on the true effect.
+*/
+func (r *IdaResult) Min() float64 {
+	min := r.Effects[0]
+	for _, e := range r.Effects[1:] {
+		if e < min {
+			min = e
+		}
+	}
+	return min
+}
+
+/*
+Max
+
+Returns the largest possible effect, an upper bound on the true effect.
+*/
+func (r *IdaResult) Max() float64 {
+	max := r.Effects[0]
+	for _, e := range r.Effects[1:] {
+		if e > max {
+			max = e
+		}
+	}
+	return max
+}
+
+/*
+Ida
+
+Runs local IDA (Maathuis, Kalisch & Bühlmann, 2009) for the effect of x on y given a
+CPDAG over the columns of data. Every subset s of the undirected neighbours of x that
+can be pointed into x without creating a new unshielded collider yields a possible
+parent set pa(x) ∪ s; the possible effect is the coefficient of x in the regression of
+y on x and that parent set, or zero when y is one of the parents.
+Data has one row per sample and one column per node of the pattern, in node order.
+*/
+func Ida(pattern *graph.Graph, data *mat.Dense, x, y *graph.Node) (*IdaResult, error) {
+	return ida(pattern, data, x, y, false)
+}
+
+/*
+OptimalIda
+
+Runs optimal IDA (Witte, Henckel, Maathuis & Kalisch, 2020): for every locally valid
+orientation of the edges around x, the orientation is propagated with Meek's rules and
+the effect is estimated by adjusting for the optimal adjustment set of the resulting
+graph, which gives the possible effects with the smallest asymptotic variance.
+The effect is zero when y is not a possible descendant of x in that graph.
+*/
+func OptimalIda(pattern *graph.Graph, data *mat.Dense, x, y *graph.Node) (*IdaResult, error) {
+	return ida(pattern, data, x, y, true)
+}
+
+func ida(pattern *graph.Graph, data *mat.Dense, x, y *graph.Node, optimal bool) (*IdaResult, error) {
+	if !pattern.ContainsNode(x) || !pattern.ContainsNode(y) {
+		return nil, fmt.Errorf("treatment and outcome must be nodes of the graph")
+	}
+	if x == y {
+		return nil, fmt.Errorf("treatment and outcome must differ")
+	}
+	nodes := pattern.GetNodes()
+	if _, c := data.Dims(); c != len(nodes) {
+		return nil, fmt.Errorf("data has %d columns but the graph has %d nodes", c, len(nodes))
+	}
+	index := map[*graph.Node]int{}
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	parents := pattern.GetParents(x)
+	var siblings []*graph.Node
+	for _, n := range pattern.GetAdjacentNodes(x) {
+		if pattern.IsUndirectedFromTo(x, n) {
+			siblings = append(siblings, n)
+		}
+	}
+
+	result := &IdaResult{}
+	for mask := 0; mask < 1<<len(siblings); mask++ {
+		var into, out []*graph.Node
+		for i, s := range siblings {
+			if mask>>i&1 == 1 {
+				into = append(into, s)
+			} else {
+				out = append(out, s)
+			}
+		}
+		if !locallyValid(pattern, parents, into) {
+			continue
+		}
+		var adjustment []*graph.Node
+		zero := false
+		if optimal {
+			oriented := pattern.Copy()
+			oriented.SetPattern(true)
+			for _, s := range into {
+				oriented.RemoveConnectingEdges(s, x)
+				oriented.AddDirectedEdge(s, x)
+			}
+			for _, s := range out {
+				oriented.RemoveConnectingEdges(x, s)
+				oriented.AddDirectedEdge(x, s)
+			}
+			graph.ApplyMeekRules(oriented)
+			if !possiblyCauses(oriented, x, y) {
+				zero = true
+			} else {
+				var err error
+				adjustment, err = identify.FindOptimalAdjustmentSet(oriented, x, y)
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			adjustment = append(append([]*graph.Node{}, parents...), into...)
+			for _, n := range adjustment {
+				if n == y {
+					zero = true
+				}
+			}
+		}
+
+		effect := 0.0
+		if !zero {
+			regressors := []int{index[x]}
+			for _, n := range adjustment {
+				regressors = append(regressors, index[n])
+			}
+			fit, err := Regress(data, index[y], regressors)
+			if err != nil {
+				return nil, err
+			}
+			effect = fit.Coefficients[0]
+		}
+		result.Effects = append(result.Effects, effect)
+		result.AdjustmentSets = append(result.AdjustmentSets, adjustment)
+	}
+	return result, nil
+}
+
+// locallyValid reports whether pointing the siblings in into at x creates no new
+// unshielded collider at x: they must be pairwise adjacent and adjacent to every parent.
+func locallyValid(g *graph.Graph, parents, into []*graph.Node) bool {
+	for i, a := range into {
+		for _, b := range into[i+1:] {
+			if !g.IsAdjacentTo(a, b) {
+				return false
+			}
+		}
+		for _, p := range parents {
+			if !g.IsAdjacentTo(a, p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// possiblyCauses reports whether a path of directed or undirected edges leads from x to
+// y, every edge traversed from tail to head when directed.
+func possiblyCauses(g *graph.Graph, x, y *graph.Node) bool {
+	visited := utils.NewSet(x)
+	q := utils.NewQueue(x)
+	for q.Size() > 0 {
+		u := q.Pop()
+		for _, v := range g.GetAdjacentNodes(u) {
+			if !g.IsDirectedFromTo(u, v) && !g.IsUndirectedFromTo(u, v) {
+				continue
+			}
+			if v == y {
+				return true
+			}
+			if visited.Add(v) {
+				q.Append(v)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package estimate
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIdaBoundsTrueEffect(t *testing.T) {
+	rng := rand.New(rand.NewSource(35))
+	for trial := 0; trial < 5; trial++ {
+		nodes := newTestNodes(6)
+		dag, err := graph.RandomDag(nodes, 7, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sem, err := RandomLinearSEM(dag, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := sem.Simulate(20000, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cpdag, err := graph.DagToCpdag(dag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range nodes {
+			for _, y := range nodes {
+				if x == y {
+					continue
+				}
+				want := sem.TotalEffect(x, y)
+				tolerance := 0.05 * (1 + math.Abs(want))
+				for _, optimal := range []bool{false, true} {
+					var result *IdaResult
+					if optimal {
+						result, err = OptimalIda(cpdag, data, x, y)
+					} else {
+						result, err = Ida(cpdag, data, x, y)
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					found := false
+					for _, e := range result.Effects {
+						if math.Abs(e-want) < tolerance {
+							found = true
+						}
+					}
+					if !found || result.Min() > want+tolerance || result.Max() < want-tolerance {
+						t.Errorf("trial %d, optimal %v: effect of %s on %s is %.3f, got %v",
+							trial, optimal, x.GetName(), y.GetName(), want, result.Effects)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestIdaKnownPatterns(t *testing.T) {
+	rng := rand.New(rand.NewSource(35))
+	// X1 --> X3 <-- X2 and X3 --> X4: every edge is compelled
+	nodes := newTestNodes(4)
+	dag := graph.NewGraph(nodes)
+	dag.AddDirectedEdge(nodes[0], nodes[2])
+	dag.AddDirectedEdge(nodes[1], nodes[2])
+	dag.AddDirectedEdge(nodes[2], nodes[3])
+	sem, err := RandomLinearSEM(dag, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := sem.Simulate(20000, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpdag, err := graph.DagToCpdag(dag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := nodes[2], nodes[3]
+	for _, optimal := range []bool{false, true} {
+		var result *IdaResult
+		if optimal {
+			result, err = OptimalIda(cpdag, data, x, y)
+		} else {
+			result, err = Ida(cpdag, data, x, y)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := sem.TotalEffect(x, y)
+		if len(result.Effects) != 1 || math.Abs(result.Effects[0]-want) > 0.05 {
+			t.Errorf("optimal %v: X3 has no undirected neighbours, want the single effect %.3f, got %v", optimal, want, result.Effects)
+		}
+		// X1 is a parent of X3, so it is not a possible descendant of X3
+		if optimal {
+			result, err = OptimalIda(cpdag, data, x, nodes[0])
+		} else {
+			result, err = Ida(cpdag, data, x, nodes[0])
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Effects) != 1 || result.Effects[0] != 0 {
+			t.Errorf("optimal %v: effect of X3 on its parent must be 0, got %v", optimal, result.Effects)
+		}
+	}
+
+	// X1 --- X2 --- X3: the effect of X2 on X3 is either zero or the regression slope
+	nodes = newTestNodes(3)
+	dag = graph.NewGraph(nodes)
+	dag.AddDirectedEdge(nodes[0], nodes[1])
+	dag.AddDirectedEdge(nodes[1], nodes[2])
+	sem, err = RandomLinearSEM(dag, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = sem.Simulate(20000, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpdag, err = graph.DagToCpdag(dag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Ida(cpdag, data, nodes[1], nodes[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// X1 and X3 are not adjacent, so they cannot both point into X2
+	if len(result.Effects) != 3 {
+		t.Fatalf("want 3 locally valid orientations, got %v", result.Effects)
+	}
+	want := sem.TotalEffect(nodes[1], nodes[2])
+	zeros := 0
+	for _, e := range result.Effects {
+		if e == 0 {
+			zeros++
+		} else if math.Abs(e-want) > 0.05 {
+			zeros = -1
+		}
+	}
+	if zeros != 1 {
+		t.Errorf("effects of X2 on X3: got %v, want 0 once and %.3f otherwise", result.Effects, want)
+	}
+
+	if _, err := Ida(cpdag, data, nodes[0], nodes[0]); err == nil {
+		t.Error("the treatment must differ from the outcome")
+	}
+	if _, err := Ida(cpdag, data.Slice(0, 10, 0, 2).(*mat.Dense), nodes[0], nodes[1]); err == nil {
+		t.Error("the data must have a column per node")
+	}
+}
@@ -0,0 +1,283 @@
+package estimate
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+)
+
+/*
+LinearSEM
+
+A linear structural equation model over the nodes of a DAG: every node is an intercept
+plus a weighted sum of its parents plus an independent Gaussian error.
+Data matrices exchanged with the model have one column per node, in the order of the
+graph's node list.
+*/
+type LinearSEM struct {
+	graph *graph.Graph
+	nodes []*graph.Node
+	index map[*graph.Node]int
+	order []*graph.Node
+	// coefficients[i][j] is the weight of the edge from node i to node j
+	coefficients   *mat.Dense
+	standardErrors *mat.Dense
+	intercepts     []float64
+	errorVariances []float64
+}
+
+func newLinearSEM(g *graph.Graph) (*LinearSEM, error) {
+	if !graph.IsDag(g) {
+		return nil, fmt.Errorf("graph must be a DAG")
+	}
+	nodes := g.GetNodes()
+	p := len(nodes)
+	if p == 0 {
+		return nil, fmt.Errorf("graph has no nodes")
+	}
+	m := &LinearSEM{
+		graph:          g,
+		nodes:          nodes,
+		index:          map[*graph.Node]int{},
+		order:          graph.GetCausalOrdering(g),
+		intercepts:     make([]float64, p),
+		coefficients:   mat.NewDense(p, p, nil),
+		standardErrors: mat.NewDense(p, p, nil),
+		errorVariances: make([]float64, p),
+	}
+	for i, n := range nodes {
+		m.index[n] = i
+	}
+	return m, nil
+}
+
+/*
+NewLinearSEM
+
+Returns the linear SEM over the DAG g with the given edge coefficients (entry [i][j] for
+the edge from node i to node j), intercepts and error variances, indexed by the graph's
+node list. Coefficients of pairs that are not edges of g must be zero.
+*/
+func NewLinearSEM(g *graph.Graph, coefficients *mat.Dense, intercepts, errorVariances []float64) (*LinearSEM, error) {
+	m, err := newLinearSEM(g)
+	if err != nil {
+		return nil, err
+	}
+	p := len(m.nodes)
+	if r, c := coefficients.Dims(); r != p || c != p {
+		return nil, fmt.Errorf("coefficient matrix must be %d x %d", p, p)
+	}
+	if len(intercepts) != p || len(errorVariances) != p {
+		return nil, fmt.Errorf("intercepts and error variances must have one entry per node")
+	}
+	for i, a := range m.nodes {
+		for j, b := range m.nodes {
+			if coefficients.At(i, j) != 0 && !g.IsDirectedFromTo(a, b) {
+				return nil, fmt.Errorf("coefficient for %s --> %s is not an edge of the graph", a.GetName(), b.GetName())
+			}
+		}
+		if errorVariances[i] < 0 {
+			return nil, fmt.Errorf("error variance of %s is negative", a.GetName())
+		}
+	}
+	m.coefficients.Copy(coefficients)
+	copy(m.intercepts, intercepts)
+	copy(m.errorVariances, errorVariances)
+	return m, nil
+}
+
+/*
+RandomLinearSEM
+
+Returns a linear SEM over the DAG g with zero intercepts, edge coefficients drawn
+uniformly from [-1.5, -0.5] and [0.5, 1.5], and error variances drawn uniformly from
+[1, 3], the usual parameters of simulation studies.
+*/
+func RandomLinearSEM(g *graph.Graph, rng *rand.Rand) (*LinearSEM, error) {
+	m, err := newLinearSEM(g)
+	if err != nil {
+		return nil, err
+	}
+	for j, node := range m.nodes {
+		for _, parent := range g.GetParents(node) {
+			coefficient := 0.5 + rng.Float64()
+			if rng.Intn(2) == 0 {
+				coefficient = -coefficient
+			}
+			m.coefficients.Set(m.index[parent], j, coefficient)
+		}
+		m.errorVariances[j] = 1 + 2*rng.Float64()
+	}
+	return m, nil
+}
+
+/*
+FitLinearSEM
+
+Estimates a linear SEM on the DAG g from data, one row per sample and one column per
+node in the order of the graph's node list: every node is regressed on its parents by
+ordinary least squares, giving the edge coefficients with their standard errors, the
+intercepts and the residual variances.
+*/
+func FitLinearSEM(g *graph.Graph, data *mat.Dense) (*LinearSEM, error) {
+	m, err := newLinearSEM(g)
+	if err != nil {
+		return nil, err
+	}
+	if _, c := data.Dims(); c != len(m.nodes) {
+		return nil, fmt.Errorf("data has %d columns but the graph has %d nodes", c, len(m.nodes))
+	}
+	for j, n := range m.nodes {
+		var parents []int
+		for _, p := range g.GetParents(n) {
+			parents = append(parents, m.index[p])
+		}
+		fit, err := Regress(data, j, parents)
+		if err != nil {
+			return nil, fmt.Errorf("fitting %s: %w", n.GetName(), err)
+		}
+		for k, i := range parents {
+			m.coefficients.Set(i, j, fit.Coefficients[k])
+			m.standardErrors.Set(i, j, fit.StandardErrors[k])
+		}
+		m.intercepts[j] = fit.Intercept
+		m.errorVariances[j] = fit.ResidualVariance
+	}
+	return m, nil
+}
+
+func (m *LinearSEM) GetGraph() *graph.Graph {
+	return m.graph
+}
+
+func (m *LinearSEM) GetNodes() []*graph.Node {
+	return m.nodes
+}
+
+/*
+GetCoefficient
+
+Returns the coefficient of the edge from --> to, or zero if there is no such edge.
+*/
+func (m *LinearSEM) GetCoefficient(from, to *graph.Node) float64 {
+	return m.coefficients.At(m.index[from], m.index[to])
+}
+
+/*
+GetStandardError
+
+Returns the standard error of the estimated coefficient of the edge from --> to.
+It is zero for models that were not fitted to data.
+*/
+func (m *LinearSEM) GetStandardError(from, to *graph.Node) float64 {
+	return m.standardErrors.At(m.index[from], m.index[to])
+}
+
+/*
+GetCoefficients
+
+Returns a copy of the coefficient matrix, entry [i][j] holding the weight of the edge
+from node i to node j.
+*/
+func (m *LinearSEM) GetCoefficients() *mat.Dense {
+	return mat.DenseCopyOf(m.coefficients)
+}
+
+func (m *LinearSEM) GetIntercept(node *graph.Node) float64 {
+	return m.intercepts[m.index[node]]
+}
+
+func (m *LinearSEM) GetErrorVariance(node *graph.Node) float64 {
+	return m.errorVariances[m.index[node]]
+}
+
+/*
+TotalEffects
+
+Returns the matrix of total effects, entry [i][j] being the change in node j per unit
+intervention on node i: the sum over all directed paths from i to j of the product of
+the edge coefficients along the path. The diagonal is one.
+*/
+func (m *LinearSEM) TotalEffects() *mat.Dense {
+	p := len(m.nodes)
+	effects := mat.NewDense(p, p, nil)
+	for i := range m.nodes {
+		effects.Set(i, i, 1)
+		// path products accumulate along the causal order
+		for _, n := range m.order {
+			j := m.index[n]
+			if j == i {
+				continue
+			}
+			sum := 0.0
+			for _, parent := range m.graph.GetParents(n) {
+				k := m.index[parent]
+				sum += effects.At(i, k) * m.coefficients.At(k, j)
+			}
+			effects.Set(i, j, sum)
+		}
+	}
+	return effects
+}
+
+/*
+TotalEffect
+
+Returns the total effect of from on to.
+*/
+func (m *LinearSEM) TotalEffect(from, to *graph.Node) float64 {
+	return m.TotalEffects().At(m.index[from], m.index[to])
+}
+
+/*
+Simulate
+
+Draws n samples from the model, generating the nodes in causal order with Gaussian
+errors of the model's error variances.
+*/
+func (m *LinearSEM) Simulate(n int, rng *rand.Rand) (*mat.Dense, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("number of samples must be positive")
+	}
+	data := mat.NewDense(n, len(m.nodes), nil)
+	for s := 0; s < n; s++ {
+		for _, node := range m.order {
+			j := m.index[node]
+			value := m.intercepts[j] + math.Sqrt(m.errorVariances[j])*rng.NormFloat64()
+			for _, parent := range m.graph.GetParents(node) {
+				k := m.index[parent]
+				value += m.coefficients.At(k, j) * data.At(s, k)
+			}
+			data.Set(s, j, value)
+		}
+	}
+	return data, nil
+}
+
+/*
+Predict
+
+Returns the value each node is expected to take given the observed values of its
+parents, for every row of data: the intercept plus the weighted parent values.
+*/
+func (m *LinearSEM) Predict(data *mat.Dense) (*mat.Dense, error) {
+	n, c := data.Dims()
+	if c != len(m.nodes) {
+		return nil, fmt.Errorf("data has %d columns but the model has %d nodes", c, len(m.nodes))
+	}
+	predicted := mat.NewDense(n, c, nil)
+	for j, node := range m.nodes {
+		parents := m.graph.GetParents(node)
+		for s := 0; s < n; s++ {
+			value := m.intercepts[j]
+			for _, parent := range parents {
+				k := m.index[parent]
+				value += m.coefficients.At(k, j) * data.At(s, k)
+			}
+			predicted.Set(s, j, value)
+		}
+	}
+	return predicted, nil
+}
@@ -0,0 +1,55 @@
+package estimate
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func newTestNodes(n int) []*graph.Node {
+	nodes := make([]*graph.Node, n)
+	for i := range nodes {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(fmt.Sprintf("X%d", i+1))
+	}
+	return nodes
+}
+
+func TestLinearSEMSimulateAndFit(t *testing.T) {
+	rng := rand.New(rand.NewSource(34))
+	nodes := newTestNodes(6)
+	dag, err := graph.RandomDag(nodes, 8, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sem, err := RandomLinearSEM(dag, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.Simulate(0, rng); err == nil {
+		t.Error("Simulate(0) must fail")
+	}
+	data, err := sem.Simulate(20000, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fit, err := FitLinearSEM(dag, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range dag.GetGraphEdges() {
+		from, to := e.GetNode1(), e.GetNode2()
+		want, got := sem.GetCoefficient(from, to), fit.GetCoefficient(from, to)
+		if math.Abs(got-want) > 4*fit.GetStandardError(from, to)+0.01 {
+			t.Errorf("coefficient of %s --> %s: got %.3f, want %.3f", from.GetName(), to.GetName(), got, want)
+		}
+	}
+	for _, n := range nodes {
+		want, got := sem.GetErrorVariance(n), fit.GetErrorVariance(n)
+		if math.Abs(got-want) > 0.1*want {
+			t.Errorf("error variance of %s: got %.3f, want %.3f", n.GetName(), got, want)
+		}
+	}
+}
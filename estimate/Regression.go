@@ -0,0 +1,97 @@
+package estimate
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+/*
+RegressionResult
+
+The ordinary least squares fit of one column of a data set on a list of other columns.
+Coefficients and StandardErrors follow the order of the regressors.
+*/
+type RegressionResult struct {
+	Target                 int
+	Regressors             []int
+	Intercept              float64
+	Coefficients           []float64
+	InterceptStandardError float64
+	StandardErrors         []float64
+	// unbiased estimate of the error variance, RSS / (n - p - 1)
+	ResidualVariance float64
+	Residuals        []float64
+}
+
+/*
+Regress
+
+Regresses column target of data on the regressor columns and an intercept by ordinary
+least squares. Rows of data are samples.
+Returns an error if there are not more samples than parameters or the regressors are
+collinear.
+*/
+func Regress(data *mat.Dense, target int, regressors []int) (*RegressionResult, error) {
+	n, c := data.Dims()
+	p := len(regressors)
+	if target < 0 || target >= c {
+		return nil, fmt.Errorf("target column %d out of range", target)
+	}
+	for _, r := range regressors {
+		if r < 0 || r >= c {
+			return nil, fmt.Errorf("regressor column %d out of range", r)
+		}
+		if r == target {
+			return nil, fmt.Errorf("column %d cannot be regressed on itself", target)
+		}
+	}
+	if n <= p+1 {
+		return nil, fmt.Errorf("regression on %d regressors needs more than %d samples, got %d", p, p+1, n)
+	}
+
+	x := mat.NewDense(n, p+1, nil)
+	y := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i++ {
+		x.Set(i, 0, 1)
+		for j, r := range regressors {
+			x.Set(i, j+1, data.At(i, r))
+		}
+		y.SetVec(i, data.At(i, target))
+	}
+	var xtx mat.Dense
+	xtx.Mul(x.T(), x)
+	var inverse mat.Dense
+	if err := inverse.Inverse(&xtx); err != nil {
+		return nil, fmt.Errorf("regressors of column %d are collinear", target)
+	}
+	var xty, beta mat.VecDense
+	xty.MulVec(x.T(), y)
+	beta.MulVec(&inverse, &xty)
+
+	var fitted mat.VecDense
+	fitted.MulVec(x, &beta)
+	residuals := make([]float64, n)
+	rss := 0.0
+	for i := range residuals {
+		residuals[i] = y.AtVec(i) - fitted.AtVec(i)
+		rss += residuals[i] * residuals[i]
+	}
+	variance := rss / float64(n-p-1)
+
+	result := &RegressionResult{
+		Target:                 target,
+		Regressors:             append([]int{}, regressors...),
+		Intercept:              beta.AtVec(0),
+		Coefficients:           make([]float64, p),
+		InterceptStandardError: math.Sqrt(variance * inverse.At(0, 0)),
+		StandardErrors:         make([]float64, p),
+		ResidualVariance:       variance,
+		Residuals:              residuals,
+	}
+	for j := 0; j < p; j++ {
+		result.Coefficients[j] = beta.AtVec(j + 1)
+		result.StandardErrors[j] = math.Sqrt(variance * inverse.At(j+1, j+1))
+	}
+	return result, nil
+}
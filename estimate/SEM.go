@@ -0,0 +1,480 @@
+package estimate
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/diff/fd"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+	"sort"
+)
+
+type SemParameterType int
+
+const (
+	// weight of a directed edge Node1 --> Node2
+	EdgeCoefficient SemParameterType = iota
+	// variance of the error term of Node1, the variance itself for exogenous latents
+	ErrorVariance
+	// covariance of the error terms of Node1 and Node2, one per bidirected edge
+	ErrorCovariance
+)
+
+/*
+SemParameter
+
+A free or fixed parameter of a structural equation model with its estimate.
+Fixed parameters are the loadings that set the scale of latent nodes and have no
+standard error.
+*/
+type SemParameter struct {
+	Type          SemParameterType
+	Node1         *graph.Node
+	Node2         *graph.Node
+	Value         float64
+	StandardError float64
+	Fixed         bool
+}
+
+/*
+ModificationIndex
+
+The expected drop in the chi-square statistic if a parameter that the model fixes to
+zero were freed, by the score test (Sörbom, 1989).
+*/
+type ModificationIndex struct {
+	Type  SemParameterType
+	Node1 *graph.Node
+	Node2 *graph.Node
+	Index float64
+}
+
+/*
+SemFit
+
+A linear structural equation model fitted by maximum likelihood, with the usual fit
+statistics: the likelihood-ratio chi-square against the saturated model, RMSEA, and the
+CFI and TLI comparisons with the independence model.
+*/
+type SemFit struct {
+	Parameters          []*SemParameter
+	SampleSize          int
+	ChiSquare           float64
+	DegreesOfFreedom    int
+	PValue              float64
+	Rmsea               float64
+	Cfi                 float64
+	Tli                 float64
+	ModificationIndices []ModificationIndex
+	// model-implied covariance matrix of the observed nodes
+	ImpliedCovariance *mat.Dense
+}
+
+type semModel struct {
+	nodes    []*graph.Node
+	index    map[*graph.Node]int
+	observed []int
+	params   []*SemParameter
+	// indices into params of the parameters being estimated
+	free []int
+	// sample covariance of the observed nodes, with divisor n
+	sample  *mat.SymDense
+	logDetS float64
+}
+
+/*
+FitSEM
+
+Fits a linear SEM with latent nodes and correlated errors by maximum likelihood.
+Directed edges of g are regressions, bidirected edges are error covariances, and every
+node whose type is LATENT is unobserved; the scale of a latent node is fixed by setting
+the loading on its first observed child to one.
+Data holds one row per sample and one column per observed node, in the order of the
+graph's node list. The discrepancy
+F = log|Σ(θ)| + tr(S Σ(θ)^-1) - log|S| - p
+is minimized with BFGS; standard errors and modification indices come from the
+expected information matrix.
+*/
+func FitSEM(g *graph.Graph, data *mat.Dense) (*SemFit, error) {
+	m, err := newSemModel(g, data)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := data.Dims()
+	p := len(m.observed)
+	moments := p * (p + 1) / 2
+	if len(m.free) > moments {
+		return nil, fmt.Errorf("model is not identified: %d free parameters but only %d moments", len(m.free), moments)
+	}
+
+	start := make([]float64, len(m.free))
+	for k, i := range m.free {
+		start[k] = m.params[i].Value
+	}
+	problem := optimize.Problem{
+		Func: m.discrepancy,
+		Grad: func(grad, x []float64) {
+			fd.Gradient(grad, m.discrepancy, x, &fd.Settings{Formula: fd.Central})
+		},
+	}
+	result, err := optimize.Minimize(problem, start, nil, &optimize.BFGS{})
+	if err != nil && (result == nil || math.IsInf(result.F, 1)) {
+		return nil, fmt.Errorf("maximum likelihood estimation failed: %w", err)
+	}
+	m.set(result.X)
+	fmin := result.F
+
+	fit := &SemFit{
+		Parameters:       m.params,
+		SampleSize:       n,
+		ChiSquare:        float64(n) * fmin,
+		DegreesOfFreedom: moments - len(m.free),
+	}
+	fit.PValue = 1
+	if fit.DegreesOfFreedom > 0 {
+		fit.PValue = distuv.ChiSquared{K: float64(fit.DegreesOfFreedom)}.Survival(fit.ChiSquare)
+	}
+
+	// independence model: the observed variances alone
+	baseline := -m.logDetS
+	for i := 0; i < p; i++ {
+		baseline += math.Log(m.sample.At(i, i))
+	}
+	baselineChiSquare := float64(n) * baseline
+	baselineDf := float64(p * (p - 1) / 2)
+	df := float64(fit.DegreesOfFreedom)
+	if df > 0 {
+		fit.Rmsea = math.Sqrt(math.Max(fit.ChiSquare-df, 0) / (df * float64(n)))
+	}
+	excess := math.Max(fit.ChiSquare-df, 0)
+	fit.Cfi = 1
+	if denominator := math.Max(baselineChiSquare-baselineDf, excess); denominator > 0 {
+		fit.Cfi = 1 - excess/denominator
+	}
+	if df > 0 && baselineDf > 0 {
+		ratio := baselineChiSquare / baselineDf
+		fit.Tli = (ratio - fit.ChiSquare/df) / (ratio - 1)
+	}
+	fit.ImpliedCovariance, _ = m.implied()
+
+	fit.ModificationIndices = m.inference(g, n)
+	return fit, nil
+}
+
+func newSemModel(g *graph.Graph, data *mat.Dense) (*semModel, error) {
+	if g.ExistsDirectedCycle() {
+		return nil, fmt.Errorf("graph contains a directed cycle")
+	}
+	for _, e := range g.GetGraphEdges() {
+		if !graph.IsDirectedEdge(e) && !graph.IsBidirectedEdge(e) {
+			return nil, fmt.Errorf("edge %s is neither directed nor bidirected", e.ToString())
+		}
+	}
+	m := &semModel{nodes: g.GetNodes(), index: map[*graph.Node]int{}}
+	for i, n := range m.nodes {
+		m.index[n] = i
+		if n.GetNodeType() != graph.LATENT {
+			m.observed = append(m.observed, i)
+		}
+	}
+	rows, cols := data.Dims()
+	if cols != len(m.observed) {
+		return nil, fmt.Errorf("data has %d columns but the graph has %d observed nodes", cols, len(m.observed))
+	}
+	if rows < 2 {
+		return nil, fmt.Errorf("at least two samples are needed")
+	}
+	m.sample = sampleCovariance(data)
+	var chol mat.Cholesky
+	if !chol.Factorize(m.sample) {
+		return nil, fmt.Errorf("sample covariance matrix is singular")
+	}
+	m.logDetS = chol.LogDet()
+
+	averageVariance := 0.0
+	for i := range m.observed {
+		averageVariance += m.sample.At(i, i) / float64(len(m.observed))
+	}
+	column := map[int]int{}
+	for k, i := range m.observed {
+		column[i] = k
+	}
+	for _, n := range m.nodes {
+		latent := n.GetNodeType() == graph.LATENT
+		children := g.GetChildren(n)
+		if latent && len(children) == 0 {
+			return nil, fmt.Errorf("latent node %s has no children", n.GetName())
+		}
+		// the scale is set by the first observed child, or the first child if all are latent
+		var marker *graph.Node
+		if latent {
+			marker = children[0]
+			for _, c := range children {
+				if c.GetNodeType() != graph.LATENT {
+					marker = c
+					break
+				}
+			}
+		}
+		for _, c := range children {
+			param := &SemParameter{Type: EdgeCoefficient, Node1: n, Node2: c}
+			if c == marker {
+				param.Value = 1
+				param.Fixed = true
+			}
+			m.params = append(m.params, param)
+		}
+	}
+	for i, n := range m.nodes {
+		start := averageVariance / 2
+		if k, ok := column[i]; ok {
+			start = m.sample.At(k, k) / 2
+		}
+		m.params = append(m.params, &SemParameter{Type: ErrorVariance, Node1: n, Node2: n, Value: start})
+	}
+	for _, e := range g.GetGraphEdges() {
+		if graph.IsBidirectedEdge(e) {
+			m.params = append(m.params, &SemParameter{Type: ErrorCovariance, Node1: e.GetNode1(), Node2: e.GetNode2()})
+		}
+	}
+	for i, param := range m.params {
+		if !param.Fixed {
+			m.free = append(m.free, i)
+		}
+	}
+	return m, nil
+}
+
+func sampleCovariance(data *mat.Dense) *mat.SymDense {
+	n, p := data.Dims()
+	means := make([]float64, p)
+	for j := 0; j < p; j++ {
+		for i := 0; i < n; i++ {
+			means[j] += data.At(i, j) / float64(n)
+		}
+	}
+	s := mat.NewSymDense(p, nil)
+	for a := 0; a < p; a++ {
+		for b := a; b < p; b++ {
+			sum := 0.0
+			for i := 0; i < n; i++ {
+				sum += (data.At(i, a) - means[a]) * (data.At(i, b) - means[b])
+			}
+			s.SetSym(a, b, sum/float64(n))
+		}
+	}
+	return s
+}
+
+// set writes the values of the free parameters.
+func (m *semModel) set(x []float64) {
+	for k, i := range m.free {
+		m.params[i].Value = x[k]
+	}
+}
+
+/*
+impliedFrom
+
+Returns the covariance matrix of the observed nodes implied by the parameters:
+with X = B'X + e and Cov(e) = Ω, Σ = (I - B')^-1 Ω (I - B')^-T restricted to the
+observed nodes.
+*/
+func (m *semModel) impliedFrom(params []*SemParameter) (*mat.SymDense, bool) {
+	size := len(m.nodes)
+	a := mat.NewDense(size, size, nil)
+	omega := mat.NewDense(size, size, nil)
+	for i := 0; i < size; i++ {
+		a.Set(i, i, 1)
+	}
+	for _, param := range params {
+		i, j := m.index[param.Node1], m.index[param.Node2]
+		switch param.Type {
+		case EdgeCoefficient:
+			a.Set(j, i, a.At(j, i)-param.Value)
+		case ErrorVariance:
+			omega.Set(i, i, param.Value)
+		case ErrorCovariance:
+			omega.Set(i, j, param.Value)
+			omega.Set(j, i, param.Value)
+		}
+	}
+	var inverse mat.Dense
+	if err := inverse.Inverse(a); err != nil {
+		return nil, false
+	}
+	var all, left mat.Dense
+	left.Mul(&inverse, omega)
+	all.Mul(&left, inverse.T())
+	sigma := mat.NewSymDense(len(m.observed), nil)
+	for k, i := range m.observed {
+		for l, j := range m.observed[k:] {
+			sigma.SetSym(k, k+l, all.At(i, j))
+		}
+	}
+	return sigma, true
+}
+
+func (m *semModel) implied() (*mat.Dense, bool) {
+	sigma, ok := m.impliedFrom(m.params)
+	if !ok {
+		return nil, false
+	}
+	return mat.DenseCopyOf(sigma), true
+}
+
+// discrepancyOf evaluates the ML fitting function for the given parameters.
+func (m *semModel) discrepancyOf(params []*SemParameter) float64 {
+	sigma, ok := m.impliedFrom(params)
+	if !ok {
+		return math.Inf(1)
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(sigma) {
+		return math.Inf(1)
+	}
+	var solved mat.Dense
+	if err := chol.SolveTo(&solved, m.sample); err != nil {
+		return math.Inf(1)
+	}
+	return chol.LogDet() + mat.Trace(&solved) - m.logDetS - float64(len(m.observed))
+}
+
+func (m *semModel) discrepancy(x []float64) float64 {
+	return m.discrepancyOf(m.with(x, nil, nil))
+}
+
+// with returns copies of the parameters with the free ones set to x, followed by the
+// extra parameters set to the trailing values of x.
+func (m *semModel) with(x []float64, extra []*SemParameter, extraValues []float64) []*SemParameter {
+	params := make([]*SemParameter, 0, len(m.params)+len(extra))
+	for _, param := range m.params {
+		c := *param
+		params = append(params, &c)
+	}
+	for k, i := range m.free {
+		params[i].Value = x[k]
+	}
+	for k, param := range extra {
+		c := *param
+		c.Value = extraValues[k]
+		params = append(params, &c)
+	}
+	return params
+}
+
+/*
+inference
+
+Sets the standard errors of the free parameters and returns the modification indices,
+both from the expected information of the fitting function,
+I_ab = tr(Σ^-1 ∂Σ/∂θa Σ^-1 ∂Σ/∂θb),
+which gives Cov(θ) = 2/n I^-1 and, for a parameter f fixed at zero, the score statistic
+MI = n/2 g_f² / (I_ff - I_fθ I_θθ^-1 I_θf) with g the gradient of F.
+Candidates are the directed edges that can be added without creating a cycle and the
+error covariances of non-adjacent pairs.
+*/
+func (m *semModel) inference(g *graph.Graph, n int) []ModificationIndex {
+	params := append([]*SemParameter{}, m.params...)
+	for i, a := range m.nodes {
+		for _, b := range m.nodes[i+1:] {
+			if g.IsAdjacentTo(a, b) {
+				continue
+			}
+			if !g.IsAncestorOf(b, a) {
+				params = append(params, &SemParameter{Type: EdgeCoefficient, Node1: a, Node2: b})
+			}
+			if !g.IsAncestorOf(a, b) {
+				params = append(params, &SemParameter{Type: EdgeCoefficient, Node1: b, Node2: a})
+			}
+			params = append(params, &SemParameter{Type: ErrorCovariance, Node1: a, Node2: b})
+		}
+	}
+	which := append([]int{}, m.free...)
+	for i := len(m.params); i < len(params); i++ {
+		which = append(which, i)
+	}
+	q := len(m.free)
+
+	sigma, ok := m.impliedFrom(params)
+	var inverseSigma mat.Dense
+	if ok && inverseSigma.Inverse(sigma) != nil {
+		ok = false
+	}
+	if !ok {
+		for _, i := range m.free {
+			m.params[i].StandardError = math.NaN()
+		}
+		return nil
+	}
+
+	// Σ^-1 ∂Σ/∂θ for every parameter, by central differences
+	const step = 1e-6
+	scaled := make([]*mat.Dense, len(which))
+	for k, i := range which {
+		value := params[i].Value
+		params[i].Value = value + step
+		upper, _ := m.impliedFrom(params)
+		params[i].Value = value - step
+		lower, _ := m.impliedFrom(params)
+		params[i].Value = value
+		var derivative mat.Dense
+		derivative.Sub(upper, lower)
+		derivative.Scale(1/(2*step), &derivative)
+		scaled[k] = &mat.Dense{}
+		scaled[k].Mul(&inverseSigma, &derivative)
+	}
+	information := mat.NewSymDense(len(which), nil)
+	for a := range which {
+		for b := a; b < len(which); b++ {
+			var product mat.Dense
+			product.Mul(scaled[a], scaled[b])
+			information.SetSym(a, b, mat.Trace(&product))
+		}
+	}
+
+	var inverse mat.Dense
+	if err := inverse.Inverse(information.SliceSym(0, q)); err != nil {
+		for _, i := range m.free {
+			m.params[i].StandardError = math.NaN()
+		}
+		return nil
+	}
+	for k, i := range m.free {
+		m.params[i].StandardError = math.Sqrt(2 / float64(n) * inverse.At(k, k))
+	}
+
+	// gradient of F: tr(Σ^-1 (Σ - S) Σ^-1 ∂Σ/∂θ)
+	var residual, weighted mat.Dense
+	residual.Sub(sigma, m.sample)
+	weighted.Mul(&inverseSigma, &residual)
+	var indices []ModificationIndex
+	for k := q; k < len(which); k++ {
+		var product mat.Dense
+		product.Mul(&weighted, scaled[k])
+		gradient := mat.Trace(&product)
+		cross := mat.NewVecDense(q, nil)
+		for l := 0; l < q; l++ {
+			cross.SetVec(l, information.At(l, k))
+		}
+		var solved mat.VecDense
+		solved.MulVec(&inverse, cross)
+		denominator := information.At(k, k) - mat.Dot(cross, &solved)
+		if denominator <= 1e-10 {
+			continue
+		}
+		candidate := params[which[k]]
+		indices = append(indices, ModificationIndex{
+			Type:  candidate.Type,
+			Node1: candidate.Node1,
+			Node2: candidate.Node2,
+			Index: float64(n) / 2 * gradient * gradient / denominator,
+		})
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return indices[i].Index > indices[j].Index
+	})
+	return indices
+}
@@ -0,0 +1,69 @@
+package estimate
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitSEMRecoversParameters(t *testing.T) {
+	// L --> X1, X2, X3 with X3 --> X4 and X2 <-> X4; the loading on X1 is fixed to one
+	nodes := newTestNodes(4)
+	x1, x2, x3, x4 := nodes[0], nodes[1], nodes[2], nodes[3]
+	l := &graph.Node{}
+	l.SetName("L")
+	l.SetNodeType(graph.LATENT)
+	g := graph.NewGraph(append([]*graph.Node{l}, nodes...))
+	g.AddDirectedEdge(l, x1)
+	g.AddDirectedEdge(l, x2)
+	g.AddDirectedEdge(l, x3)
+	g.AddDirectedEdge(x3, x4)
+	g.AddEdge(graph.BidirectedEdge(x2, x4))
+
+	rng := rand.New(rand.NewSource(36))
+	n := 20000
+	data := mat.NewDense(n, 4, nil)
+	for s := 0; s < n; s++ {
+		latent := 1.5 * rng.NormFloat64()
+		shared := rng.NormFloat64()
+		v1 := latent + rng.NormFloat64()
+		v2 := 0.8*latent + 0.6*shared + 0.8*rng.NormFloat64()
+		v3 := 1.2*latent + rng.NormFloat64()
+		v4 := -0.7*v3 + 0.5*shared + rng.NormFloat64()
+		data.SetRow(s, []float64{v1, v2, v3, v4})
+	}
+	fit, err := FitSEM(g, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[[2]*graph.Node]float64{
+		{l, x1}:  1,
+		{l, x2}:  0.8,
+		{l, x3}:  1.2,
+		{x3, x4}: -0.7,
+		{l, l}:   2.25,
+		{x1, x1}: 1,
+		{x2, x2}: 1,
+		{x3, x3}: 1,
+		{x4, x4}: 1.25,
+		{x2, x4}: 0.3,
+	}
+	for _, p := range fit.Parameters {
+		value, ok := want[[2]*graph.Node{p.Node1, p.Node2}]
+		if !ok {
+			value, ok = want[[2]*graph.Node{p.Node2, p.Node1}]
+		}
+		if !ok {
+			t.Errorf("unexpected parameter %s, %s", p.Node1.GetName(), p.Node2.GetName())
+			continue
+		}
+		if math.Abs(p.Value-value) > 0.1*math.Max(1, math.Abs(value)) {
+			t.Errorf("parameter %s, %s: got %.3f, want %.3f", p.Node1.GetName(), p.Node2.GetName(), p.Value, value)
+		}
+		if p.Fixed != (p.Node1 == l && p.Node2 == x1) {
+			t.Errorf("only the loading on X1 is fixed, got %s, %s fixed: %v", p.Node1.GetName(), p.Node2.GetName(), p.Fixed)
+		}
+	}
+}
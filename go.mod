@@ -1,10 +1,11 @@
 module GoCausal
 
-go 1.17
+go 1.18
 
 require gonum.org/v1/gonum v0.9.3
 
 require (
 	golang.org/x/exp v0.0.0-20220218215828-6cf2b201936e // indirect
+	golang.org/x/tools v0.1.8-0.20211029000441-d6a9af8af023 // indirect
 	gonum.org/v1/netlib v0.0.0-20210927171344-7274ea1d1842 // indirect
 )
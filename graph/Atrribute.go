@@ -21,6 +21,9 @@ func (attr *Attribute) GetAttribute(key string) interface{} {
 }
 
 func (attr *Attribute) AddAttribute(key string, value interface{}) {
+	if attr.attributes == nil {
+		attr.attributes = map[string]interface{}{}
+	}
 	attr.attributes[key] = value
 }
 
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+GraphComparison
+
+Counts of the agreement of an estimated graph with a true one. An adjacency is a pair
+of adjacent nodes; an arrowhead is an arrow endpoint at a node of an edge. A true
+positive is in both graphs, a false positive only in the estimate and a false negative
+only in the truth. The structural Hamming distance is the number of pairs of nodes that
+are adjacent in one graph only or joined by edges with different endpoints.
+*/
+type GraphComparison struct {
+	AdjacencyTruePositives    int
+	AdjacencyFalsePositives   int
+	AdjacencyFalseNegatives   int
+	ArrowheadTruePositives    int
+	ArrowheadFalsePositives   int
+	ArrowheadFalseNegatives   int
+	StructuralHammingDistance int
+}
+
+/*
+CompareGraphs
+
+Compares the estimated graph with the true one, matching their nodes by name. Returns
+an error if the graphs do not have the same node names.
+*/
+func CompareGraphs(truth, estimate *Graph) (*GraphComparison, error) {
+	nodes := truth.GetNodes()
+	if estimate.GetNumNodes() != len(nodes) {
+		return nil, fmt.Errorf("true graph has %d nodes but the estimate %d", len(nodes), estimate.GetNumNodes())
+	}
+	matched := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		if matched[i] = estimate.GetNode(n.GetName()); matched[i] == nil {
+			return nil, fmt.Errorf("node %s of the true graph is not in the estimate", n.GetName())
+		}
+	}
+	c := &GraphComparison{}
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			inTruth := truth.IsAdjacentTo(nodes[i], nodes[j])
+			inEstimate := estimate.IsAdjacentTo(matched[i], matched[j])
+			switch {
+			case inTruth && inEstimate:
+				c.AdjacencyTruePositives++
+			case inEstimate:
+				c.AdjacencyFalsePositives++
+			case inTruth:
+				c.AdjacencyFalseNegatives++
+			}
+			differ := inTruth != inEstimate
+			for _, ends := range [][2]int{{i, j}, {j, i}} {
+				trueArrow := truth.GetEndpoint(nodes[ends[0]], nodes[ends[1]])
+				estimatedArrow := estimate.GetEndpoint(matched[ends[0]], matched[ends[1]])
+				if trueArrow != estimatedArrow {
+					differ = true
+				}
+				switch {
+				case trueArrow == ARROW && estimatedArrow == ARROW:
+					c.ArrowheadTruePositives++
+				case estimatedArrow == ARROW:
+					c.ArrowheadFalsePositives++
+				case trueArrow == ARROW:
+					c.ArrowheadFalseNegatives++
+				}
+			}
+			if differ {
+				c.StructuralHammingDistance++
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c *GraphComparison) GetAdjacencyPrecision() float64 {
+	return ratio(c.AdjacencyTruePositives, c.AdjacencyTruePositives+c.AdjacencyFalsePositives)
+}
+
+func (c *GraphComparison) GetAdjacencyRecall() float64 {
+	return ratio(c.AdjacencyTruePositives, c.AdjacencyTruePositives+c.AdjacencyFalseNegatives)
+}
+
+func (c *GraphComparison) GetArrowheadPrecision() float64 {
+	return ratio(c.ArrowheadTruePositives, c.ArrowheadTruePositives+c.ArrowheadFalsePositives)
+}
+
+func (c *GraphComparison) GetArrowheadRecall() float64 {
+	return ratio(c.ArrowheadTruePositives, c.ArrowheadTruePositives+c.ArrowheadFalseNegatives)
+}
+
+// ratio returns a/b, or NaN if b is zero.
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return math.NaN()
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,173 @@
+package graph
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func newTestNodes(n int) []*Node {
+	nodes := make([]*Node, n)
+	for i := range nodes {
+		nodes[i] = &Node{}
+		nodes[i].SetName(fmt.Sprintf("X%d", i))
+	}
+	return nodes
+}
+
+func randomDag(rng *rand.Rand, n int, p float64) *Graph {
+	nodes := newTestNodes(n)
+	g := NewGraph(nodes)
+	order := rng.Perm(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < p {
+				g.AddDirectedEdge(nodes[order[i]], nodes[order[j]])
+			}
+		}
+	}
+	return g
+}
+
+// bruteDConnected enumerates every simple path between x and y in the skeleton
+// and reports whether one of them is active given z.
+func bruteDConnected(g *Graph, x, y *Node, z []*Node) bool {
+	inZ := map[*Node]bool{}
+	for _, n := range z {
+		inZ[n] = true
+	}
+	anZ := map[*Node]bool{}
+	for _, n := range g.GetNodes() {
+		for _, m := range z {
+			if bruteIsAncestor(g, n, m) {
+				anZ[n] = true
+			}
+		}
+	}
+	onPath := map[*Node]bool{x: true}
+	var search func(prev, cur *Node) bool
+	search = func(prev, cur *Node) bool {
+		for _, next := range g.GetAdjacentNodes(cur) {
+			if onPath[next] {
+				continue
+			}
+			if prev != nil {
+				collider := g.IsParentOf(prev, cur) && g.IsParentOf(next, cur)
+				if collider && !anZ[cur] || !collider && inZ[cur] {
+					continue
+				}
+			}
+			if next == y {
+				return true
+			}
+			onPath[next] = true
+			found := search(cur, next)
+			onPath[next] = false
+			if found {
+				return true
+			}
+		}
+		return false
+	}
+	return search(nil, x)
+}
+
+func bruteIsAncestor(g *Graph, a, b *Node) bool {
+	if a == b {
+		return true
+	}
+	for _, c := range g.GetChildren(a) {
+		if bruteIsAncestor(g, c, b) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIsDConnectedToMatchesPathEnumeration(t *testing.T) {
+	rng := rand.New(rand.NewSource(27))
+	for trial := 0; trial < 200; trial++ {
+		n := 2 + rng.Intn(6)
+		g := randomDag(rng, n, 0.2+0.5*rng.Float64())
+		nodes := g.GetNodes()
+		for _, x := range nodes {
+			for _, y := range nodes {
+				if x == y {
+					continue
+				}
+				var z []*Node
+				for _, n := range nodes {
+					if n != x && n != y && rng.Float64() < 0.35 {
+						z = append(z, n)
+					}
+				}
+				want := bruteDConnected(g, x, y, z)
+				if got := g.IsDConnectedTo(x, y, z); got != want {
+					t.Fatalf("IsDConnectedTo(%s, %s, %v) = %v, want %v in\n%s",
+						x.GetName(), y.GetName(), z, got, want, g.ToString())
+				}
+				if g.IsDSeparatedFrom(x, y, z) == want {
+					t.Fatalf("IsDSeparatedFrom(%s, %s) disagrees with IsDConnectedTo", x.GetName(), y.GetName())
+				}
+			}
+		}
+	}
+}
+
+func TestGetDConnectedNodesMatchesPathEnumeration(t *testing.T) {
+	rng := rand.New(rand.NewSource(270))
+	for trial := 0; trial < 200; trial++ {
+		n := 2 + rng.Intn(6)
+		g := randomDag(rng, n, 0.2+0.5*rng.Float64())
+		nodes := g.GetNodes()
+		x := nodes[rng.Intn(n)]
+		var z []*Node
+		for _, n := range nodes {
+			if n != x && rng.Float64() < 0.3 {
+				z = append(z, n)
+			}
+		}
+		reachable := map[*Node]bool{}
+		for _, n := range g.GetDConnectedNodes([]*Node{x}, z) {
+			reachable[n] = true
+		}
+		inZ := map[*Node]bool{}
+		for _, n := range z {
+			inZ[n] = true
+		}
+		for _, y := range nodes {
+			if y == x || inZ[y] {
+				if reachable[y] {
+					t.Fatalf("%s must not be reported as d-connected", y.GetName())
+				}
+				continue
+			}
+			if want := bruteDConnected(g, x, y, z); reachable[y] != want {
+				t.Fatalf("%s reachable from %s given %v = %v, want %v in\n%s",
+					y.GetName(), x.GetName(), z, reachable[y], want, g.ToString())
+			}
+		}
+	}
+}
+
+func TestIsDConnectedToCollider(t *testing.T) {
+	nodes := newTestNodes(4)
+	a, b, c, d := nodes[0], nodes[1], nodes[2], nodes[3]
+	g := NewGraph(nodes)
+	g.AddDirectedEdge(a, b)
+	g.AddDirectedEdge(c, b)
+	g.AddDirectedEdge(b, d)
+
+	if g.IsDConnectedTo(a, c, nil) {
+		t.Error("a and c are d-separated by the empty set")
+	}
+	if !g.IsDConnectedTo(a, c, []*Node{b}) {
+		t.Error("conditioning on the collider b must connect a and c")
+	}
+	if !g.IsDConnectedTo(a, c, []*Node{d}) {
+		t.Error("conditioning on a descendant of the collider must connect a and c")
+	}
+	if g.IsDConnectedTo(a, d, []*Node{b}) {
+		t.Error("b blocks the chain a -> b -> d")
+	}
+}
@@ -27,6 +27,8 @@ type Edge struct {
 	node2     *Node
 	endpoint1 Endpoint
 	endpoint2 Endpoint
+	// set by the graph for a weighted directed edge
+	weight float64
 }
 
 func (e *Edge) GetNode1() *Node {
@@ -45,6 +47,15 @@ func (e *Edge) GetEndpoint2() Endpoint {
 	return e.endpoint2
 }
 
+/*
+GetWeight
+
+Returns the weight of a directed edge as set by Graph.SetEdgeWeight, zero if none was set.
+*/
+func (e *Edge) GetWeight() float64 {
+	return e.weight
+}
+
 func (e *Edge) Exchange() {
 	node := *e.node2
 	*e.node2 = *e.node1
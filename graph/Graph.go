@@ -114,6 +114,9 @@ type Graph struct {
 	dottedUnderlineTriples []*Triple
 	pattern                bool
 	pag                    bool
+	timeLag                bool
+	// weights of directed edges, keyed by tail and head
+	weights map[[2]*Node]float64
 }
 
 func (g *Graph) adjustDPath(i, j int) {
@@ -151,16 +154,12 @@ func (g *Graph) reconstituteDPath(edges []*Edge) {
 	}
 }
 
-func (g *Graph) collectAncestors(node *Node, ancestors []*Node) {
-	if MapKeyInNodeSlice(ancestors, node) {
+func (g *Graph) collectAncestors(node *Node, ancestors *utils.OrderedSet[*Node]) {
+	if !ancestors.Add(node) {
 		return
 	}
-	ancestors = append(ancestors, node)
-	parents := g.GetParents(node)
-	if parents != nil {
-		for _, p := range parents {
-			g.collectAncestors(p, ancestors)
-		}
+	for _, p := range g.GetParents(node) {
+		g.collectAncestors(p, ancestors)
 	}
 }
 
@@ -287,13 +286,23 @@ Adds a node to the graph.
 Precondition: The proposed name of the node cannot already be used by any other node in the same graph.
 */
 func (g *Graph) AddNode(node *Node) bool {
-	if MapKeyInNodeSlice(g.nodes, node) {
+	if g.ContainsNode(node) {
 		return false
 	}
 	g.nodes = append(g.nodes, node)
 	g.nodeMap[node] = g.varNum
 	g.varNum++
 
+	graph := newSquareMatrix(g.varNum)
+	dPath := newSquareMatrix(g.varNum)
+	if g.varNum > 1 {
+		graph.Slice(0, g.varNum-1, 0, g.varNum-1).(*mat.Dense).Copy(g.graph)
+		dPath.Slice(0, g.varNum-1, 0, g.varNum-1).(*mat.Dense).Copy(g.dPath)
+	}
+	g.graph = graph
+	g.dPath = dPath
+	g.adjustDPath(g.varNum-1, g.varNum-1)
+
 	return true
 }
 
@@ -308,6 +317,7 @@ func (g *Graph) Clear() {
 	g.nodeMap = map[*Node]int{}
 	g.graph.Reset()
 	g.dPath.Reset()
+	g.weights = nil
 }
 
 /*
@@ -459,11 +469,11 @@ GetAncestors
 Returns a slice of ancestors for the given nodes.
 */
 func (g *Graph) GetAncestors(nodes []*Node) []*Node {
-	var ancestors []*Node
+	ancestors := utils.NewOrderedSet[*Node]()
 	for _, n := range nodes {
 		g.collectAncestors(n, ancestors)
 	}
-	return ancestors
+	return ancestors.Values()
 }
 
 /*
@@ -478,7 +488,7 @@ func (g *Graph) GetChildren(node *Node) []*Node {
 		e1 := Endpoint(g.graph.At(i, j))
 		e2 := Endpoint(g.graph.At(j, i))
 		if (e1 == TAIL && e2 == ARROW) || (e1 == TAIL_AND_ARROW && e2 == ARROW_AND_ARROW) {
-			n := g.nodes[i]
+			n := g.nodes[j]
 			children = append(children, n)
 		}
 	}
@@ -660,10 +670,7 @@ IsAncestorOf
 Return true iff node1 is an ancestor of node2.
 */
 func (g *Graph) IsAncestorOf(node1, node2 *Node) bool {
-	i := g.nodeMap[node1]
-	j := g.nodeMap[node2]
-	e := Endpoint(g.graph.At(j, i))
-	return e == ARROW
+	return IsAncestor(node1, []*Node{node2}, g)
 }
 
 /*
@@ -672,10 +679,7 @@ IsDescendantOf
 Returns true iff node1 is a descendant of node2.
 */
 func (g *Graph) IsDescendantOf(node1, node2 *Node) bool {
-	i := g.nodeMap[node1]
-	j := g.nodeMap[node2]
-	e := Endpoint(g.graph.At(i, j))
-	return e == ARROW
+	return IsAncestor(node2, []*Node{node1}, g)
 }
 
 /*
@@ -738,9 +742,41 @@ func (g *Graph) GetEdge(node1, node2 *Node) *Edge {
 		fmt.Println(err.Error())
 		return nil
 	}
+	edge.weight = g.weights[[2]*Node{edge.node1, edge.node2}]
 	return edge
 }
 
+/*
+SetEdgeWeight
+
+Attaches a weight, such as a linear coefficient, to the directed edge from node1 to
+node2. The weight is carried by the edges the graph returns and is dropped when the edge
+is removed.
+*/
+func (g *Graph) SetEdgeWeight(node1, node2 *Node, weight float64) error {
+	if !g.IsDirectedFromTo(node1, node2) {
+		return fmt.Errorf("there is no directed edge from %s to %s", node1.GetName(), node2.GetName())
+	}
+	if g.weights == nil {
+		g.weights = map[[2]*Node]float64{}
+	}
+	g.weights[[2]*Node{node1, node2}] = weight
+	return nil
+}
+
+/*
+GetEdgeWeight
+
+Returns the weight of the directed edge from node1 to node2, zero if there is no such
+edge or no weight was set.
+*/
+func (g *Graph) GetEdgeWeight(node1, node2 *Node) float64 {
+	if !g.IsDirectedFromTo(node1, node2) {
+		return 0
+	}
+	return g.weights[[2]*Node{node1, node2}]
+}
+
 /*
 GetDirectedEdge
 
@@ -759,6 +795,7 @@ func (g *Graph) GetDirectedEdge(node1, node2 *Node) *Edge {
 		fmt.Println(err.Error())
 		return nil
 	}
+	edge.weight = g.weights[[2]*Node{edge.node1, edge.node2}]
 	return edge
 }
 
@@ -842,6 +879,31 @@ func (g *Graph) GetEndpoint(node1, node2 *Node) Endpoint {
 	}
 }
 
+/*
+SetEndpoint
+
+Replaces the endpoint at node2 of the edge connecting node1 and node2, keeping the one at
+node1, e.g. to turn a o-o b into a o-> b. Returns false if the nodes are not joined by a
+single edge or the resulting edge is not supported.
+*/
+func (g *Graph) SetEndpoint(node1, node2 *Node, endpoint Endpoint) bool {
+	i := g.nodeMap[node1]
+	j := g.nodeMap[node2]
+	at1 := Endpoint(g.graph.At(i, j))
+	at2 := Endpoint(g.graph.At(j, i))
+	if at1 == NULL || at1 == TAIL_AND_ARROW || at1 == ARROW_AND_ARROW || at2 == TAIL_AND_ARROW || at2 == ARROW_AND_ARROW {
+		return false
+	}
+	g.RemoveConnectingEdge(node1, node2)
+	if g.AddEdge(&Edge{node1: node1, node2: node2, endpoint1: at1, endpoint2: endpoint}) ||
+		g.AddEdge(&Edge{node1: node2, node2: node1, endpoint1: endpoint, endpoint2: at1}) {
+		return true
+	}
+	g.graph.Set(i, j, float64(at1))
+	g.graph.Set(j, i, float64(at2))
+	return false
+}
+
 /*
 IsDefNonCollider
 
@@ -942,6 +1004,15 @@ func (g *Graph) SetPag(pag bool) {
 	g.pag = pag
 }
 
+/*
+IsTimeLagModel
+
+Returns true if the graph is the repeating structure of a TimeLagGraph.
+*/
+func (g *Graph) IsTimeLagModel() bool {
+	return g.timeLag
+}
+
 /*
 IsDirectedFromTo
 
@@ -1014,50 +1085,56 @@ func (g *Graph) GetNodesOutOf(node *Node, endpoint Endpoint) []*Node {
 	return nodes
 }
 
-func (g *Graph) RemoveEdge(edge *Edge) {
-	node1 := edge.GetNode1()
-	node2 := edge.GetNode2()
+/*
+RemoveEdge
 
-	i := g.nodeMap[node1]
-	j := g.nodeMap[node2]
+Removes the given edge. When the two nodes are joined by a pair of edges, e.g. a --> b
+together with a <-> b, only the matching one is removed.
+*/
+func (g *Graph) RemoveEdge(edge *Edge) {
+	i := g.nodeMap[edge.GetNode1()]
+	j := g.nodeMap[edge.GetNode2()]
 
-	outOf := Endpoint(g.graph.At(j, i))
-	inTo := Endpoint(g.graph.At(i, j))
+	// endpoints at node1 and node2 of whatever connects them
+	at1 := Endpoint(g.graph.At(i, j))
+	at2 := Endpoint(g.graph.At(j, i))
 
 	end1 := edge.GetEndpoint1()
 	end2 := edge.GetEndpoint2()
-	if outOf == TAIL_AND_ARROW && inTo == TAIL_AND_ARROW {
-		if end1 == ARROW {
-			g.graph.Set(j, i, -1)
-			g.graph.Set(i, j, -1)
-		} else if end1 == TAIL {
-			g.graph.Set(i, j, 1)
-			g.graph.Set(j, i, 1)
-		}
-	} else if outOf == ARROW_AND_ARROW && inTo == TAIL_AND_ARROW {
-		if end1 == ARROW {
-			g.graph.Set(j, i, -1)
-			g.graph.Set(i, j, -1)
-		} else if end1 == TAIL {
-			g.graph.Set(i, j, 1)
-			g.graph.Set(j, i, 1)
-		}
-	} else if outOf == TAIL_AND_ARROW && inTo == ARROW_AND_ARROW {
-		if end1 == ARROW {
-			g.graph.Set(j, i, -1)
-			g.graph.Set(i, j, 1)
-		} else if end1 == TAIL {
-			g.graph.Set(i, j, 1)
-			g.graph.Set(j, i, 1)
-		}
-	} else {
-		if end1 == inTo && end2 == outOf {
-			g.graph.Set(j, i, 0)
-			g.graph.Set(i, j, 0)
-		}
+	if end1 == TAIL && end2 == ARROW {
+		delete(g.weights, [2]*Node{edge.GetNode1(), edge.GetNode2()})
+	}
+	if at1 == end1 && at2 == end2 {
+		g.graph.Set(i, j, 0)
+		g.graph.Set(j, i, 0)
+		return
+	}
+	if at1 == TAIL_AND_ARROW && at2 == TAIL_AND_ARROW && end1 != end2 {
+		// the pair is a tail-tail and an arrow-arrow edge, neither of which is this one
+		return
+	}
+	other1, ok1 := remainingEndpoint(at1, end1)
+	other2, ok2 := remainingEndpoint(at2, end2)
+	if ok1 && ok2 {
+		g.graph.Set(i, j, float64(other1))
+		g.graph.Set(j, i, float64(other2))
 	}
 }
 
+// remainingEndpoint returns what is left at a node carrying two edges once the edge
+// ending in the given endpoint there is removed.
+func remainingEndpoint(pair, end Endpoint) (Endpoint, bool) {
+	switch {
+	case pair == TAIL_AND_ARROW && end == TAIL:
+		return ARROW, true
+	case pair == TAIL_AND_ARROW && end == ARROW:
+		return TAIL, true
+	case pair == ARROW_AND_ARROW && end == ARROW:
+		return ARROW, true
+	}
+	return NULL, false
+}
+
 /*
 RemoveConnectingEdge
 
@@ -1068,6 +1145,8 @@ func (g *Graph) RemoveConnectingEdge(node1, node2 *Node) {
 	j := g.nodeMap[node2]
 	g.graph.Set(j, i, 0)
 	g.graph.Set(i, j, 0)
+	delete(g.weights, [2]*Node{node1, node2})
+	delete(g.weights, [2]*Node{node2, node1})
 }
 
 /*
@@ -1082,6 +1161,8 @@ func (g *Graph) RemoveConnectingEdges(node1, node2 *Node) {
 	j := g.nodeMap[node2]
 	g.graph.Set(j, i, 0)
 	g.graph.Set(i, j, 0)
+	delete(g.weights, [2]*Node{node1, node2})
+	delete(g.weights, [2]*Node{node2, node1})
 }
 
 /*
@@ -1102,13 +1183,22 @@ RemoveNode
 Removes a node from the graph.
 */
 func (g *Graph) RemoveNode(node *Node) {
-	i := g.nodeMap[node]
-	err := utils.RemoveRowCol(i, i, g.graph)
-	if err != nil {
-		panic(err.Error())
+	if !g.ContainsNode(node) {
+		return
 	}
+	var nodes []*Node
+	for _, n := range g.nodes {
+		if n != node {
+			nodes = append(nodes, n)
+		}
+	}
+	subgraph := g.Subgraph(nodes)
+	g.nodes = subgraph.nodes
+	g.varNum = subgraph.varNum
+	g.graph = subgraph.graph
+	g.dPath = subgraph.dPath
+	g.weights = subgraph.weights
 	g.updateNodeMap()
-	g.varNum--
 }
 
 /*
@@ -1123,6 +1213,32 @@ func (g *Graph) RemoveNodes(nodes []*Node) {
 	}
 }
 
+/*
+Copy
+
+Returns a copy of the graph that can be modified independently of it.
+The nodes themselves are shared between the two graphs.
+*/
+func (g *Graph) Copy() *Graph {
+	nodes := make([]*Node, len(g.nodes))
+	copy(nodes, g.nodes)
+	c := NewGraph(nodes)
+	if g.varNum > 0 {
+		c.graph = mat.DenseCopyOf(g.graph)
+		c.dPath = mat.DenseCopyOf(g.dPath)
+	}
+	c.ambiguousTriples = append([]*Triple{}, g.ambiguousTriples...)
+	c.underlineTriples = append([]*Triple{}, g.underlineTriples...)
+	c.dottedUnderlineTriples = append([]*Triple{}, g.dottedUnderlineTriples...)
+	c.pattern = g.pattern
+	c.pag = g.pag
+	c.timeLag = g.timeLag
+	for k, w := range g.weights {
+		c.SetEdgeWeight(k[0], k[1], w)
+	}
+	return c
+}
+
 /*
 Subgraph
 
@@ -1130,15 +1246,21 @@ Constructs and returns a subgraph consisting of a given subset of the
 nodes of this graph together with the edges between them.
 */
 func (g *Graph) Subgraph(nodes []*Node) *Graph {
-	subgraph := NewGraph(nodes)
-	graph := mat.DenseCopyOf(g.graph)
-	for i := 0; i < g.varNum; i++ {
-		if !MapKeyInNodeSlice(nodes, g.nodes[i]) {
-			_ = utils.RemoveRowCol(i, i, graph)
+	subgraph := NewGraph(append([]*Node{}, nodes...))
+	for i, a := range nodes {
+		for j, b := range nodes {
+			subgraph.graph.Set(i, j, g.graph.At(g.nodeMap[a], g.nodeMap[b]))
 		}
 	}
-	subgraph.graph = graph
 	subgraph.reconstituteDPath(subgraph.GetGraphEdges())
+	subgraph.pattern = g.pattern
+	subgraph.pag = g.pag
+	subgraph.timeLag = g.timeLag
+	for k, w := range g.weights {
+		if subgraph.ContainsNode(k[0]) && subgraph.ContainsNode(k[1]) {
+			subgraph.SetEdgeWeight(k[0], k[1], w)
+		}
+	}
 	return subgraph
 }
 
@@ -1210,18 +1332,15 @@ func (g *Graph) GetDottedUnderlines() []*Triple {
 }
 
 func (g *Graph) IsAmbiguousTriple(triple *Triple) bool {
-	// FIXME
-	return false
+	return containsTriple(g.ambiguousTriples, triple)
 }
 
 func (g *Graph) IsUnderlineTriple(triple *Triple) bool {
-	// FIXME
-	return false
+	return containsTriple(g.underlineTriples, triple)
 }
 
 func (g *Graph) IsDottedUnderlineTriple(triple *Triple) bool {
-	// FIXME
-	return false
+	return containsTriple(g.dottedUnderlineTriples, triple)
 }
 
 func (g *Graph) AddAmbiguousTriple(triple *Triple) {
@@ -1236,13 +1355,20 @@ func (g *Graph) AddDottedUnderlineTriple(triple *Triple) {
 	g.dottedUnderlineTriples = append(g.dottedUnderlineTriples, triple)
 }
 
+func newSquareMatrix(n int) *mat.Dense {
+	if n == 0 {
+		return &mat.Dense{}
+	}
+	return mat.NewDense(n, n, nil)
+}
+
 func NewGraph(nodes []*Node) *Graph {
 	n := len(nodes)
 	graph := Graph{
 		nodes:   nodes,
 		varNum:  n,
-		graph:   mat.NewDense(n, n, nil),
-		dPath:   mat.NewDense(n, n, nil),
+		graph:   newSquareMatrix(n),
+		dPath:   newSquareMatrix(n),
 		nodeMap: map[*Node]int{},
 		pattern: false,
 		pag:     false,
@@ -0,0 +1,240 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// edge marks of the text format, with the endpoints at their left and right nodes
+var edgeMarks = map[string][2]Endpoint{
+	"-->": {TAIL, ARROW},
+	"<--": {ARROW, TAIL},
+	"---": {TAIL, TAIL},
+	"<->": {ARROW, ARROW},
+	"o->": {CIRCLE, ARROW},
+	"<-o": {ARROW, CIRCLE},
+	"o-o": {CIRCLE, CIRCLE},
+	"--o": {TAIL, CIRCLE},
+	"o--": {CIRCLE, TAIL},
+}
+
+// names of the endpoints in the JSON format
+var endpointNames = map[Endpoint]string{
+	TAIL:   "TAIL",
+	ARROW:  "ARROW",
+	CIRCLE: "CIRCLE",
+}
+
+/*
+WriteTetrad
+
+Writes the graph in the text format of ToString, which Tetrad reads: a "Graph Nodes:"
+line followed by the names separated by ";", and a "Graph Edges:" line followed by one
+numbered edge per line, e.g. "1. X --> Y".
+*/
+func WriteTetrad(g *Graph, w io.Writer) error {
+	_, err := io.WriteString(w, g.ToString())
+	return err
+}
+
+/*
+ReadTetrad
+
+Reads a graph written by WriteTetrad or Tetrad. The edge numbers are ignored and every
+node an edge names must be listed among the nodes. Lines after a blank line that ends
+the edges, such as Tetrad's attribute sections, are ignored.
+*/
+func ReadTetrad(r io.Reader) (*Graph, error) {
+	scanner := bufio.NewScanner(r)
+	var g *Graph
+	section := ""
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "Graph Nodes:":
+			section = "nodes"
+			continue
+		case text == "Graph Edges:":
+			if section != "nodes" {
+				return nil, fmt.Errorf("line %d: edges come before the nodes", line)
+			}
+			if g == nil {
+				g = NewGraph(nil)
+			}
+			section = "edges"
+			continue
+		case text == "":
+			if section == "edges" {
+				section = "done"
+			}
+			continue
+		}
+		switch section {
+		case "nodes":
+			if g != nil {
+				return nil, fmt.Errorf("line %d: nodes are listed twice", line)
+			}
+			var nodes []*Node
+			for _, name := range strings.Split(text, ";") {
+				nodes = append(nodes, &Node{name: strings.TrimSpace(name), nodeType: MEASURED})
+			}
+			var err error
+			if g, err = newNamedGraph(nodes); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		case "edges":
+			fields := strings.Fields(text)
+			if len(fields) == 4 && strings.HasSuffix(fields[0], ".") {
+				fields = fields[1:]
+			}
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("line %d: cannot read edge %q", line, text)
+			}
+			marks, ok := edgeMarks[fields[1]]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown edge mark %q", line, fields[1])
+			}
+			if err := addNamedEdge(g, fields[0], fields[2], marks[0], marks[1]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		case "":
+			return nil, fmt.Errorf("line %d: expected \"Graph Nodes:\"", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, fmt.Errorf("no \"Graph Nodes:\" section")
+	}
+	return g, nil
+}
+
+/*
+WriteDot
+
+Writes the graph in the DOT language of Graphviz. Every edge is drawn with its two
+endpoints: none for a tail, an arrowhead for an arrow and an open dot for a circle.
+*/
+func WriteDot(g *Graph, w io.Writer) error {
+	shapes := map[Endpoint]string{TAIL: "none", ARROW: "normal", CIRCLE: "odot"}
+	var b strings.Builder
+	b.WriteString("digraph g {\n")
+	for _, n := range g.GetNodes() {
+		fmt.Fprintf(&b, "  %q;\n", n.GetName())
+	}
+	for _, e := range g.GetGraphEdges() {
+		fmt.Fprintf(&b, "  %q -> %q [dir=both, arrowtail=%s, arrowhead=%s];\n",
+			e.GetNode1().GetName(), e.GetNode2().GetName(), shapes[e.GetEndpoint1()], shapes[e.GetEndpoint2()])
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type jsonEdge struct {
+	Node1     string `json:"node1"`
+	Node2     string `json:"node2"`
+	Endpoint1 string `json:"endpoint1"`
+	Endpoint2 string `json:"endpoint2"`
+}
+
+type jsonGraph struct {
+	Nodes []string   `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+/*
+WriteJson
+
+Writes the graph as a JSON object with the names of its nodes under "nodes" and its
+edges under "edges", each with "node1", "node2" and the endpoints at them, "endpoint1"
+and "endpoint2", one of "TAIL", "ARROW" and "CIRCLE".
+*/
+func WriteJson(g *Graph, w io.Writer) error {
+	out := jsonGraph{Nodes: append([]string{}, g.GetNodeNames()...), Edges: []jsonEdge{}}
+	for _, e := range g.GetGraphEdges() {
+		out.Edges = append(out.Edges, jsonEdge{
+			Node1:     e.GetNode1().GetName(),
+			Node2:     e.GetNode2().GetName(),
+			Endpoint1: endpointNames[e.GetEndpoint1()],
+			Endpoint2: endpointNames[e.GetEndpoint2()],
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+/*
+ReadJson
+
+Reads a graph written by WriteJson.
+*/
+func ReadJson(r io.Reader) (*Graph, error) {
+	var in jsonGraph
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, err
+	}
+	nodes := make([]*Node, len(in.Nodes))
+	for i, name := range in.Nodes {
+		nodes[i] = &Node{name: name, nodeType: MEASURED}
+	}
+	g, err := newNamedGraph(nodes)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := map[string]Endpoint{}
+	for endpoint, name := range endpointNames {
+		endpoints[name] = endpoint
+	}
+	for k, e := range in.Edges {
+		endpoint1, ok1 := endpoints[e.Endpoint1]
+		endpoint2, ok2 := endpoints[e.Endpoint2]
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("edge %d: unknown endpoint %q or %q", k, e.Endpoint1, e.Endpoint2)
+		}
+		if err := addNamedEdge(g, e.Node1, e.Node2, endpoint1, endpoint2); err != nil {
+			return nil, fmt.Errorf("edge %d: %v", k, err)
+		}
+	}
+	return g, nil
+}
+
+// newNamedGraph returns the empty graph over the nodes, checking that their names are
+// nonempty and distinct.
+func newNamedGraph(nodes []*Node) (*Graph, error) {
+	names := map[string]bool{}
+	for _, n := range nodes {
+		if n.name == "" {
+			return nil, fmt.Errorf("empty node name")
+		}
+		if names[n.name] {
+			return nil, fmt.Errorf("node %s is listed twice", n.name)
+		}
+		names[n.name] = true
+	}
+	return NewGraph(nodes), nil
+}
+
+// addNamedEdge adds the edge between the nodes of the given names with the given
+// endpoints at them.
+func addNamedEdge(g *Graph, name1, name2 string, endpoint1, endpoint2 Endpoint) error {
+	node1, node2 := g.GetNode(name1), g.GetNode(name2)
+	if node1 == nil || node2 == nil {
+		return fmt.Errorf("edge %s - %s names a node that is not listed", name1, name2)
+	}
+	if node1 == node2 {
+		return fmt.Errorf("edge joins %s to itself", name1)
+	}
+	if !g.AddEdge(&Edge{node1: node1, node2: node2, endpoint1: endpoint1, endpoint2: endpoint2}) &&
+		!g.AddEdge(&Edge{node1: node2, node2: node1, endpoint1: endpoint2, endpoint2: endpoint1}) {
+		return fmt.Errorf("cannot add edge between %s and %s", name1, name2)
+	}
+	return nil
+}
@@ -2,11 +2,6 @@ package graph
 
 import "GoCausal/utils"
 
-type NodePoint struct {
-	node *Node
-	edge *Edge
-}
-
 func MapKeyInNodeSlice(haystack []*Node, needle *Node) bool {
 	set := make(map[*Node]struct{})
 	for _, e := range haystack {
@@ -16,67 +11,73 @@ func MapKeyInNodeSlice(haystack []*Node, needle *Node) bool {
 	return ok
 }
 
-func (n *NodePoint) GetDistalNode() *Node {
-	return n.edge.GetDistalNode(n.node)
-}
-
 func ExistsDirectedPathFromToBreadthFirst(nodeFrom, nodeTo *Node, g *Graph) bool {
-	v := []*Node{nodeFrom}
-	q := utils.LinkedQueue{}
-	q.Append(nodeFrom)
+	visited := utils.NewSet(nodeFrom)
+	q := utils.NewQueue(nodeFrom)
 	for q.Size() > 0 {
-		t := q.Pop().(*Node)
-		for _, u := range g.GetAdjacentNodes(t) {
-			if g.IsParentOf(t, u) && g.IsParentOf(u, t) {
-				return true
-			}
-			edge := g.GetEdge(t, u)
-			c := TraverseDirected(t, edge)
-			if c == nil || MapKeyInNodeSlice(v, c) {
-				continue
-			}
+		t := q.Pop()
+		for _, c := range g.GetChildren(t) {
 			if c == nodeTo {
 				return true
 			}
-			v = append(v, c)
-			q.Append(c)
+			if visited.Add(c) {
+				q.Append(c)
+			}
 		}
 	}
 	return false
 }
 
+/*
+IsDag
+
+Returns true iff every edge of g is directed and g has no directed cycle.
+*/
+func IsDag(g *Graph) bool {
+	for _, e := range g.GetGraphEdges() {
+		if !IsDirectedEdge(e) {
+			return false
+		}
+	}
+	return !g.ExistsDirectedCycle()
+}
+
 /*
 IsDConnectedTo
 
 Returns true if node1 is d-connected to node2 on the set of nodes z.
 */
 func IsDConnectedTo(node1, node2 *Node, z []*Node, g *Graph) bool {
-	// TODO
 	if node1 == node2 {
 		return true
 	}
-	q := utils.LinkedQueue{}
-	for _, e := range g.GetNodeEdges(node1) {
-		if e.GetDistalNode(node1) == node2 {
+	for _, n := range GetDConnectedNodes([]*Node{node1}, z, g) {
+		if n == node2 {
 			return true
 		}
-		q.Append(NodePoint{node: node1, edge: e})
 	}
+	return false
+}
+
+/*
+IsAncestor
+
+Determines if a given node is an ancestor of any node in a set of nodes z.
+*/
+func IsAncestor(node *Node, z []*Node, g *Graph) bool {
+	visited := utils.NewSet(z...)
+	if visited.Contains(node) {
+		return true
+	}
+	q := utils.NewQueue(z...)
 	for q.Size() > 0 {
-		nodePoint := q.Pop().(NodePoint)
-		a := nodePoint.node
-		b := nodePoint.GetDistalNode()
-		for _, e := range g.GetNodeEdges(b) {
-			c := e.GetDistalNode(b)
-			if c == a {
-				continue
+		t := q.Pop()
+		for _, p := range g.GetParents(t) {
+			if p == node {
+				return true
 			}
-			if Reachable(nodePoint.edge, e, a, z, g) {
-				if c == node2 {
-					return true
-				} else {
-					q.Append(NodePoint{node: b, edge: e})
-				}
+			if visited.Add(p) {
+				q.Append(p)
 			}
 		}
 	}
@@ -84,44 +85,75 @@ func IsDConnectedTo(node1, node2 *Node, z []*Node, g *Graph) bool {
 }
 
 /*
-Reachable
+GetCComponents
 
-Determines if two edges do or do not form a block for d-separation,
-conditional on a set of nodes z starting from a node a
+Returns the c-components (districts) of g: the connected components of the graph
+restricted to its bidirected edges. Nodes without bidirected edges form singleton
+components. Components and their nodes follow the order of the graph's node list.
 */
-func Reachable(edge1, edge2 *Edge, a *Node, z []*Node, g *Graph) bool {
-	b := edge1.GetDistalNode(a)
-	collider := edge1.GetProximalEndpoint(b) == ARROW && edge2.GetProximalEndpoint(b) == ARROW
-	if !collider && !MapKeyInNodeSlice(z, b) {
-		return true
+func GetCComponents(g *Graph) [][]*Node {
+	var components [][]*Node
+	visited := utils.NewSet[*Node]()
+	for _, n := range g.GetNodes() {
+		if !visited.Add(n) {
+			continue
+		}
+		members := utils.NewSet(n)
+		q := utils.NewQueue(n)
+		for q.Size() > 0 {
+			u := q.Pop()
+			for _, e := range g.GetNodeEdges(u) {
+				v := e.GetDistalNode(u)
+				if IsBidirectedEdge(e) && visited.Add(v) {
+					members.Add(v)
+					q.Append(v)
+				}
+			}
+		}
+		var component []*Node
+		for _, m := range g.GetNodes() {
+			if members.Contains(m) {
+				component = append(component, m)
+			}
+		}
+		components = append(components, component)
 	}
-	ancestor := IsAncestor(b, z, g)
-	return collider && ancestor
+	return components
 }
 
 /*
-IsAncestor
+GetCausalOrdering
 
-Determines if a given node is an ancestor of any node in a set of nodes z.
+Returns the nodes of g in a topological order of its directed edges: every node comes
+after all of its parents. Ties are broken by the order of the graph's node list, and
+edges other than directed ones are ignored.
+Returns nil if the directed edges contain a cycle.
 */
-func IsAncestor(node *Node, z []*Node, g *Graph) bool {
-	if MapKeyInNodeSlice(z, node) {
-		return true
-	}
-	q := utils.LinkedQueue{}
-	for _, n := range z {
-		q.Append(n)
-	}
-	for q.Size() > 0 {
-		t := q.Pop().(*Node)
-		if t == node {
-			return true
-		}
-		for _, c := range g.GetParents(t) {
-			if !q.Contains(c) {
-				q.Append(c)
+func GetCausalOrdering(g *Graph) []*Node {
+	placed := utils.NewSet[*Node]()
+	var order []*Node
+	for len(order) < g.GetNumNodes() {
+		progress := false
+		for _, n := range g.GetNodes() {
+			if placed.Contains(n) {
+				continue
+			}
+			ready := true
+			for _, p := range g.GetParents(n) {
+				if !placed.Contains(p) {
+					ready = false
+					break
+				}
 			}
+			if ready {
+				placed.Add(n)
+				order = append(order, n)
+				progress = true
+			}
+		}
+		if !progress {
+			return nil
 		}
 	}
-	return false
+	return order
 }
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGetChildren(t *testing.T) {
+	nodes := newTestNodes(3)
+	g := NewGraph(nodes)
+	g.AddDirectedEdge(nodes[0], nodes[1])
+	g.AddDirectedEdge(nodes[0], nodes[2])
+	children := g.GetChildren(nodes[0])
+	if len(children) != 2 || children[0] != nodes[1] || children[1] != nodes[2] {
+		names := make([]string, len(children))
+		for i, c := range children {
+			names[i] = c.GetName()
+		}
+		t.Fatalf("children of X0: got %v, want [X1 X2]", names)
+	}
+
+	// every child must list the node among its parents
+	rng := rand.New(rand.NewSource(26))
+	for trial := 0; trial < 20; trial++ {
+		g := randomDag(rng, 8, 0.3)
+		for _, n := range g.GetNodes() {
+			for _, c := range g.GetChildren(n) {
+				if c == n || !g.IsParentOf(n, c) {
+					t.Fatalf("trial %d: %s is not a child of %s", trial, c.GetName(), n.GetName())
+				}
+			}
+			count := 0
+			for _, m := range g.GetNodes() {
+				if g.IsParentOf(n, m) {
+					count++
+				}
+			}
+			if count != len(g.GetChildren(n)) {
+				t.Fatalf("trial %d: %s has %d children, GetChildren returned %d", trial, n.GetName(), count, len(g.GetChildren(n)))
+			}
+		}
+	}
+}
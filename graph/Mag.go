@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"GoCausal/utils"
+	"fmt"
+)
+
+/*
+IsAncestralGraph
+
+Returns true iff g is an ancestral graph: it has no circle endpoints, no directed cycles,
+no almost directed cycles (a <-> b with a an ancestor of b), and no arrowhead points
+into an endpoint of an undirected edge.
+*/
+func IsAncestralGraph(g *Graph) bool {
+	if g.ExistsDirectedCycle() {
+		return false
+	}
+	for _, e := range g.GetGraphEdges() {
+		node1 := e.GetNode1()
+		node2 := e.GetNode2()
+		if e.GetEndpoint1() == CIRCLE || e.GetEndpoint2() == CIRCLE {
+			return false
+		}
+		if IsBidirectedEdge(e) {
+			if g.IsAncestorOf(node1, node2) || g.IsAncestorOf(node2, node1) {
+				return false
+			}
+		} else if IsUndirectedEdge(e) {
+			if hasArrowheadInto(node1, g) || hasArrowheadInto(node2, g) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func hasArrowheadInto(node *Node, g *Graph) bool {
+	for _, e := range g.GetNodeEdges(node) {
+		if e.GetProximalEndpoint(node) == ARROW {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+IsMaximal
+
+Returns true iff g is a maximal ancestral graph, i.e. an ancestral graph in which no
+inducing path connects a pair of non-adjacent nodes.
+*/
+func IsMaximal(g *Graph) bool {
+	if !IsAncestralGraph(g) {
+		return false
+	}
+	nodes := g.GetNodes()
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			if !g.IsAdjacentTo(a, b) && existsInducingPath(a, b, g) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+existsInducingPath
+
+Searches for a path between a and b on which every intermediate node is a collider and
+an ancestor of a or b. In an ancestral graph such a path has arrowheads at both sides
+of every intermediate node, so the search only walks through arrowheads.
+*/
+func existsInducingPath(a, b *Node, g *Graph) bool {
+	ancestors := utils.NewSet(g.GetAncestors([]*Node{a, b})...)
+	visited := utils.NewSet(a)
+	q := utils.Queue[*Node]{}
+	for _, e := range g.GetNodeEdges(a) {
+		v := e.GetDistalNode(a)
+		if v != b && e.GetProximalEndpoint(v) == ARROW && ancestors.Contains(v) && visited.Add(v) {
+			q.Append(v)
+		}
+	}
+	for q.Size() > 0 {
+		v := q.Pop()
+		for _, e := range g.GetNodeEdges(v) {
+			if e.GetProximalEndpoint(v) != ARROW {
+				continue
+			}
+			w := e.GetDistalNode(v)
+			if w == b {
+				return true
+			}
+			if e.GetProximalEndpoint(w) == ARROW && ancestors.Contains(w) && visited.Add(w) {
+				q.Append(w)
+			}
+		}
+	}
+	return false
+}
+
+/*
+DagToMag
+
+Projects a DAG onto its observed nodes, returning the maximal ancestral graph that
+marginalizes the latent nodes and conditions on the selection nodes
+(Richardson & Spirtes, 2002).
+Nodes passed in latents as well as every node whose type is LATENT are marginalized.
+Two observed nodes are adjacent in the MAG iff no set of observed nodes d-separates them
+given the selection nodes; the edge a --> b is used when a is an ancestor of b or of a
+selection node and b is not, a -- b when both are, and a <-> b when neither is.
+*/
+func DagToMag(dag *Graph, latents, selection []*Node) (*Graph, error) {
+	if !IsDag(dag) {
+		return nil, fmt.Errorf("graph must be a DAG")
+	}
+	hidden := utils.NewSet[*Node]()
+	for _, n := range latents {
+		if !dag.ContainsNode(n) {
+			return nil, fmt.Errorf("latent node %s is not in the graph", n.GetName())
+		}
+		hidden.Add(n)
+	}
+	for _, n := range selection {
+		if !dag.ContainsNode(n) {
+			return nil, fmt.Errorf("selection node %s is not in the graph", n.GetName())
+		}
+		if hidden.Contains(n) {
+			return nil, fmt.Errorf("node %s cannot be both latent and selected", n.GetName())
+		}
+	}
+	for _, n := range dag.GetNodes() {
+		if n.GetNodeType() == LATENT {
+			hidden.Add(n)
+		}
+	}
+	selected := utils.NewSet(selection...)
+	var observed []*Node
+	for _, n := range dag.GetNodes() {
+		if !hidden.Contains(n) && !selected.Contains(n) {
+			observed = append(observed, n)
+		}
+	}
+
+	// ancestors of each observed node together with the selection nodes
+	ancestors := map[*Node]*utils.Set[*Node]{}
+	for _, n := range observed {
+		ancestors[n] = utils.NewSet(dag.GetAncestors(append([]*Node{n}, selection...))...)
+	}
+
+	mag := NewGraph(observed)
+	for i, a := range observed {
+		for _, b := range observed[i+1:] {
+			z := append([]*Node{}, selection...)
+			for _, n := range observed {
+				if n != a && n != b && (ancestors[a].Contains(n) || ancestors[b].Contains(n)) {
+					z = append(z, n)
+				}
+			}
+			if dag.IsDSeparatedFrom(a, b, z) {
+				continue
+			}
+			aToB := ancestors[b].Contains(a)
+			bToA := ancestors[a].Contains(b)
+			if aToB && bToA {
+				mag.AddEdge(UndirectedEdge(a, b))
+			} else if aToB {
+				mag.AddDirectedEdge(a, b)
+			} else if bToA {
+				mag.AddDirectedEdge(b, a)
+			} else {
+				mag.AddEdge(BidirectedEdge(a, b))
+			}
+		}
+	}
+	return mag, nil
+}
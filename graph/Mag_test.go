@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDagToMagKnownGraphs(t *testing.T) {
+	nodes := newTestNodes(5)
+	a, b, c, l, s := nodes[0], nodes[1], nodes[2], nodes[3], nodes[4]
+	dag := NewGraph(nodes)
+	dag.AddDirectedEdge(l, a)
+	dag.AddDirectedEdge(l, b)
+	dag.AddDirectedEdge(b, c)
+	dag.AddDirectedEdge(a, s)
+	dag.AddDirectedEdge(c, s)
+
+	mag, err := DagToMag(dag, []*Node{l}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsBidirectedEdge(mag.GetEdge(a, b)) {
+		t.Errorf("a latent common cause gives a <-> b, got %v", mag.GetEdge(a, b))
+	}
+	if !mag.IsDirectedFromTo(b, c) || !mag.IsDirectedFromTo(a, s) || mag.IsAdjacentTo(a, c) {
+		t.Errorf("unexpected MAG\n%s", mag.ToString())
+	}
+
+	mag, err = DagToMag(dag, []*Node{l}, []*Node{s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mag.ContainsNode(s) {
+		t.Error("selection nodes are not in the MAG")
+	}
+	for _, pair := range [][2]*Node{{a, c}, {b, c}} {
+		if !IsUndirectedEdge(mag.GetEdge(pair[0], pair[1])) {
+			t.Errorf("ancestors of the selection node are joined by tails, got %v", mag.GetEdge(pair[0], pair[1]))
+		}
+	}
+
+	if _, err := DagToMag(dag, []*Node{s}, []*Node{s}); err == nil {
+		t.Error("a node cannot be both latent and selected")
+	}
+}
+
+func TestDagToMagPreservesSeparations(t *testing.T) {
+	rng := rand.New(rand.NewSource(28))
+	for trial := 0; trial < 100; trial++ {
+		n := 4 + rng.Intn(5)
+		dag := randomDag(rng, n, 0.2+0.4*rng.Float64())
+		nodes := dag.GetNodes()
+		perm := rng.Perm(n)
+		latents := []*Node{nodes[perm[0]]}
+		var selection []*Node
+		if rng.Intn(2) == 0 {
+			selection = []*Node{nodes[perm[1]]}
+		}
+		mag, err := DagToMag(dag, latents, selection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsMaximal(mag) {
+			t.Fatalf("MAG is not maximal\n%s", mag.ToString())
+		}
+		observed := mag.GetNodes()
+		for _, x := range observed {
+			for _, y := range observed {
+				if x == y {
+					continue
+				}
+				var z []*Node
+				for _, n := range observed {
+					if n != x && n != y && rng.Float64() < 0.4 {
+						z = append(z, n)
+					}
+				}
+				want := dag.IsDSeparatedFrom(x, y, append(append([]*Node{}, z...), selection...))
+				if got := mag.IsMSeparatedFrom(x, y, z); got != want {
+					t.Fatalf("m-separation of %s and %s given %v is %v, d-separation in the DAG %v\nDAG\n%s\nMAG\n%s",
+						x.GetName(), y.GetName(), z, got, want, dag.ToString(), mag.ToString())
+				}
+			}
+		}
+	}
+}
+
+func TestIsMaximal(t *testing.T) {
+	nodes := newTestNodes(4)
+	a, b, c, d := nodes[0], nodes[1], nodes[2], nodes[3]
+	g := NewGraph(nodes)
+	g.AddEdge(BidirectedEdge(a, b))
+	g.AddEdge(BidirectedEdge(b, c))
+	g.AddEdge(BidirectedEdge(c, d))
+	g.AddDirectedEdge(b, d)
+	g.AddDirectedEdge(c, a)
+	if !IsAncestralGraph(g) {
+		t.Fatal("graph is ancestral")
+	}
+	if IsMaximal(g) {
+		t.Error("a <-> b <-> c <-> d is an inducing path between the non-adjacent a and d")
+	}
+	g.AddEdge(BidirectedEdge(a, d))
+	if !IsMaximal(g) {
+		t.Error("graph is maximal once a and d are adjacent")
+	}
+
+	g = NewGraph(newTestNodes(2))
+	x, y := g.GetNodes()[0], g.GetNodes()[1]
+	g.AddDirectedEdge(x, y)
+	g.AddEdge(BidirectedEdge(x, y))
+	if IsAncestralGraph(g) {
+		t.Error("x --> y with x <-> y is an almost directed cycle")
+	}
+}
@@ -1,5 +1,7 @@
 package graph
 
+import "fmt"
+
 type NodeType int32
 type NodeVariableType int32
 
@@ -19,6 +21,13 @@ const (
 	INTERVENTION_VALUE  NodeVariableType = 3
 )
 
+// attribute keys under which SetDomain stores the categories of a node and
+// SetInterventionTarget the variable an indicator refers to
+const (
+	domainAttribute             = "domain"
+	interventionTargetAttribute = "interventionTarget"
+)
+
 type INode interface {
 	GetName() string
 	SetName(string)
@@ -88,6 +97,60 @@ func (node *Node) SetCenter(x, y int) {
 	node.centerY = y
 }
 
+/*
+SetDomain
+
+Marks the node as a categorical variable whose values are the given categories, coded
+0 .. len(categories)-1 in data sets.
+*/
+func (node *Node) SetDomain(categories []string) {
+	node.varType = DOMAIN
+	node.AddAttribute(domainAttribute, append([]string{}, categories...))
+}
+
+/*
+GetDomain
+
+Returns the categories of a categorical node, or nil if no domain was set.
+*/
+func (node *Node) GetDomain() []string {
+	if node.varType != DOMAIN {
+		return nil
+	}
+	categories, _ := node.GetAttribute(domainAttribute).([]string)
+	return categories
+}
+
+/*
+SetInterventionTarget
+
+Marks the node as an indicator of interventions on target, of variable type
+INTERVENTION_STATUS or INTERVENTION_VALUE. In data sets, the column of a status node is
+nonzero on the samples where target was intervened on; the column of a value node holds
+the value target was set to on those samples and NaN elsewhere.
+*/
+func (node *Node) SetInterventionTarget(target *Node, varType NodeVariableType) error {
+	if varType != INTERVENTION_STATUS && varType != INTERVENTION_VALUE {
+		return fmt.Errorf("variable type %d is not an intervention indicator", varType)
+	}
+	node.varType = varType
+	node.AddAttribute(interventionTargetAttribute, target)
+	return nil
+}
+
+/*
+GetInterventionTarget
+
+Returns the variable an intervention indicator refers to, or nil if the node is not one.
+*/
+func (node *Node) GetInterventionTarget() *Node {
+	if node.varType != INTERVENTION_STATUS && node.varType != INTERVENTION_VALUE {
+		return nil
+	}
+	target, _ := node.GetAttribute(interventionTargetAttribute).(*Node)
+	return target
+}
+
 func (node *Node) Equals(n *Node) bool {
 	return node.name == n.name
 }
@@ -0,0 +1,285 @@
+package graph
+
+import (
+	"GoCausal/utils"
+	"fmt"
+)
+
+/*
+orient
+
+Replaces whatever edge connects node1 and node2 by the directed edge node1 --> node2.
+*/
+func (g *Graph) orient(node1, node2 *Node) {
+	g.RemoveConnectingEdges(node1, node2)
+	g.AddDirectedEdge(node1, node2)
+}
+
+/*
+DagToCpdag
+
+Returns the completed partially directed acyclic graph (pattern) representing the
+Markov equivalence class of the DAG: the skeleton, the unshielded colliders, and every
+edge compelled by Meek's orientation rules R1-R3. The result is flagged as a pattern.
+*/
+func DagToCpdag(dag *Graph) (*Graph, error) {
+	if !IsDag(dag) {
+		return nil, fmt.Errorf("graph must be a DAG")
+	}
+	nodes := dag.GetNodes()
+	cpdag := NewGraph(append([]*Node{}, nodes...))
+	for _, e := range dag.GetGraphEdges() {
+		cpdag.AddEdge(UndirectedEdge(e.GetNode1(), e.GetNode2()))
+	}
+	for _, c := range nodes {
+		parents := dag.GetParents(c)
+		for i, a := range parents {
+			for _, b := range parents[i+1:] {
+				if !dag.IsAdjacentTo(a, b) {
+					cpdag.orient(a, c)
+					cpdag.orient(b, c)
+				}
+			}
+		}
+	}
+	ApplyMeekRules(cpdag)
+	cpdag.SetPattern(true)
+	return cpdag, nil
+}
+
+/*
+DagToInterventionalCpdag
+
+Returns the interventional essential graph of the DAG (Hauser & Bühlmann, 2012): the
+graph representing the DAGs that cannot be told apart from it by data from the given
+family of intervention targets, one slice of intervened nodes per experimental setting.
+On top of the edges compelled observationally, every edge with exactly one endpoint in
+some target is oriented as in the DAG, then Meek's rules R1-R4 propagate the
+orientations. The family is assumed conservative, i.e. every node is left alone in some
+setting, as it is whenever observational data is included (an empty target).
+*/
+func DagToInterventionalCpdag(dag *Graph, targets [][]*Node) (*Graph, error) {
+	cpdag, err := DagToCpdag(dag)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		intervened := utils.NewSet(target...)
+		for _, e := range dag.GetGraphEdges() {
+			a, b := e.GetNode1(), e.GetNode2()
+			if intervened.Contains(a) != intervened.Contains(b) && cpdag.IsUndirectedFromTo(a, b) {
+				if dag.IsDirectedFromTo(a, b) {
+					cpdag.orient(a, b)
+				} else {
+					cpdag.orient(b, a)
+				}
+			}
+		}
+	}
+	ApplyMeekRules(cpdag)
+	return cpdag, nil
+}
+
+/*
+ApplyMeekRules
+
+Orients undirected edges of a partially directed graph with Meek's rules R1-R4 until
+no rule applies:
+R1 a --> b -- c with a, c non-adjacent gives b --> c;
+R2 a --> b --> c with a -- c gives a --> c;
+R3 a -- c --> b, a -- d --> b, a -- b with c, d non-adjacent gives a --> b;
+R4 c --> d --> b, a -- b with a adjacent to c and d and c, b non-adjacent gives a --> b.
+R4 never fires on a CPDAG; it is needed once background knowledge has oriented edges.
+Returns true if any edge was oriented.
+*/
+func ApplyMeekRules(g *Graph) bool {
+	changed := false
+	for {
+		oriented := false
+		for _, e := range g.GetGraphEdges() {
+			if !IsUndirectedEdge(e) {
+				continue
+			}
+			a, b := e.GetNode1(), e.GetNode2()
+			if meekOrientable(g, a, b) {
+				g.orient(a, b)
+				oriented = true
+			} else if meekOrientable(g, b, a) {
+				g.orient(b, a)
+				oriented = true
+			}
+		}
+		if !oriented {
+			return changed
+		}
+		changed = true
+	}
+}
+
+// meekOrientable reports whether one of R1-R4 orients the undirected edge a -- b as a --> b.
+func meekOrientable(g *Graph, a, b *Node) bool {
+	for _, c := range g.GetParents(a) {
+		// R1
+		if !g.IsAdjacentTo(c, b) {
+			return true
+		}
+	}
+	for _, c := range g.GetChildren(a) {
+		// R2
+		if g.IsDirectedFromTo(c, b) {
+			return true
+		}
+	}
+	var undirected []*Node
+	for _, c := range g.GetAdjacentNodes(a) {
+		if g.IsUndirectedFromTo(a, c) && g.IsDirectedFromTo(c, b) {
+			undirected = append(undirected, c)
+		}
+	}
+	for i, c := range undirected {
+		for _, d := range undirected[i+1:] {
+			// R3
+			if !g.IsAdjacentTo(c, d) {
+				return true
+			}
+		}
+	}
+	for _, d := range g.GetParents(b) {
+		if !g.IsAdjacentTo(a, d) {
+			continue
+		}
+		for _, c := range g.GetParents(d) {
+			// R4
+			if c != a && g.IsAdjacentTo(a, c) && !g.IsAdjacentTo(c, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+PdagToDag
+
+Returns a DAG consistent with the partially directed graph: it keeps every directed edge,
+orients the undirected ones, and introduces no new unshielded collider (Dor & Tarsi, 1992).
+Returns an error if no consistent extension exists.
+*/
+func PdagToDag(pdag *Graph) (*Graph, error) {
+	dag := pdag.Copy()
+	dag.SetPattern(false)
+	work := pdag.Copy()
+	remaining := utils.NewOrderedSet(work.GetNodes()...)
+	for remaining.Size() > 0 {
+		var sink *Node
+		for _, x := range remaining.Values() {
+			if isExtensionSink(work, x, remaining) {
+				sink = x
+				break
+			}
+		}
+		if sink == nil {
+			return nil, fmt.Errorf("graph admits no consistent DAG extension")
+		}
+		for _, y := range work.GetAdjacentNodes(sink) {
+			if remaining.Contains(y) && work.IsUndirectedFromTo(y, sink) {
+				dag.orient(y, sink)
+			}
+		}
+		remaining.Remove(sink)
+	}
+	return dag, nil
+}
+
+// isExtensionSink reports whether x has no children among the remaining nodes and each
+// of its undirected neighbours is adjacent to all of its other neighbours.
+func isExtensionSink(g *Graph, x *Node, remaining *utils.OrderedSet[*Node]) bool {
+	var adjacent []*Node
+	for _, y := range g.GetAdjacentNodes(x) {
+		if !remaining.Contains(y) {
+			continue
+		}
+		if g.IsDirectedFromTo(x, y) {
+			return false
+		}
+		if !g.IsDirectedFromTo(y, x) && !g.IsUndirectedFromTo(x, y) {
+			return false
+		}
+		adjacent = append(adjacent, y)
+	}
+	for _, y := range adjacent {
+		if !g.IsUndirectedFromTo(x, y) {
+			continue
+		}
+		for _, w := range adjacent {
+			if w != y && !g.IsAdjacentTo(y, w) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+PagToMag
+
+Returns a MAG in the Markov equivalence class represented by the PAG
+(Zhang, 2008, Theorem 2): every a o-> b becomes a --> b and the subgraph of a o-o b
+edges is oriented into a DAG without unshielded colliders, using a maximum cardinality
+search ordering of its nodes.
+PAGs with a --o b edges, which only arise under selection bias, are not supported.
+*/
+func PagToMag(pag *Graph) (*Graph, error) {
+	mag := pag.Copy()
+	mag.SetPag(false)
+	circle := map[*Node]*utils.OrderedSet[*Node]{}
+	for _, e := range pag.GetGraphEdges() {
+		a, b := e.GetNode1(), e.GetNode2()
+		end1, end2 := e.GetEndpoint1(), e.GetEndpoint2()
+		if end1 == CIRCLE && end2 == CIRCLE {
+			if circle[a] == nil {
+				circle[a] = utils.NewOrderedSet[*Node]()
+			}
+			if circle[b] == nil {
+				circle[b] = utils.NewOrderedSet[*Node]()
+			}
+			circle[a].Add(b)
+			circle[b].Add(a)
+		} else if end1 == CIRCLE && end2 == ARROW {
+			mag.orient(a, b)
+		} else if end2 == CIRCLE && end1 == ARROW {
+			mag.orient(b, a)
+		} else if end1 == CIRCLE || end2 == CIRCLE {
+			return nil, fmt.Errorf("edge %s is not supported: selection bias", e.ToString())
+		}
+	}
+
+	// maximum cardinality search over the circle component
+	numbered := utils.NewOrderedSet[*Node]()
+	for numbered.Size() < len(circle) {
+		var next *Node
+		best := -1
+		for _, n := range pag.GetNodes() {
+			if circle[n] == nil || numbered.Contains(n) {
+				continue
+			}
+			weight := 0
+			for _, m := range circle[n].Values() {
+				if numbered.Contains(m) {
+					weight++
+				}
+			}
+			if weight > best {
+				best = weight
+				next = n
+			}
+		}
+		for _, m := range circle[next].Values() {
+			if numbered.Contains(m) {
+				mag.orient(m, next)
+			}
+		}
+		numbered.Add(next)
+	}
+	return mag, nil
+}
@@ -0,0 +1,36 @@
+package graph
+
+import "testing"
+
+func TestDagToInterventionalCpdagChain(t *testing.T) {
+	nodes := newTestNodes(3)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	dag := NewGraph(nodes)
+	dag.AddDirectedEdge(a, b)
+	dag.AddDirectedEdge(b, c)
+
+	cases := []struct {
+		name   string
+		target []*Node
+		ab, bc bool
+	}{
+		{"observational", nil, false, false},
+		// a --> b is compelled and R1 then orients b --> c
+		{"do(a)", []*Node{a}, true, true},
+		{"do(b)", []*Node{b}, true, true},
+		// only b --> c touches the target
+		{"do(c)", []*Node{c}, false, true},
+	}
+	for _, k := range cases {
+		cpdag, err := DagToInterventionalCpdag(dag, [][]*Node{nil, k.target})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cpdag.IsDirectedFromTo(a, b) != k.ab || cpdag.IsUndirectedFromTo(a, b) == k.ab {
+			t.Errorf("%s: edge a -- b is %v", k.name, cpdag.GetEdge(a, b))
+		}
+		if cpdag.IsDirectedFromTo(b, c) != k.bc || cpdag.IsUndirectedFromTo(b, c) == k.bc {
+			t.Errorf("%s: edge b -- c is %v", k.name, cpdag.GetEdge(b, c))
+		}
+	}
+}
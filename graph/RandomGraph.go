@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+/*
+RandomDag
+
+Returns a DAG over the nodes with the given number of edges, drawn uniformly among the
+pairs of nodes and directed along a random causal order. Returns an error if there are
+more edges than pairs of nodes.
+*/
+func RandomDag(nodes []*Node, numEdges int, rng *rand.Rand) (*Graph, error) {
+	n := len(nodes)
+	if numEdges < 0 || numEdges > n*(n-1)/2 {
+		return nil, fmt.Errorf("a DAG over %d nodes has between 0 and %d edges, got %d", n, n*(n-1)/2, numEdges)
+	}
+	g := NewGraph(nodes)
+	order := rng.Perm(n)
+	var pairs [][2]int
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			pairs = append(pairs, [2]int{order[i], order[j]})
+		}
+	}
+	rng.Shuffle(len(pairs), func(a, b int) { pairs[a], pairs[b] = pairs[b], pairs[a] })
+	for _, pair := range pairs[:numEdges] {
+		g.AddDirectedEdge(nodes[pair[0]], nodes[pair[1]])
+	}
+	return g, nil
+}
@@ -0,0 +1,468 @@
+package graph
+
+import (
+	"GoCausal/utils"
+	"sort"
+	"strings"
+)
+
+/*
+separatorProblem
+
+Collects the node sets shared by the separator routines for a pair x, y:
+the nodes that must be in the separator (include), the nodes that may be in it
+(restrict, every node when nil) and the ancestral set An({x, y} ∪ include) every
+minimal separator is contained in (van der Zander, Liśkiewicz & Textor, 2019).
+*/
+type separatorProblem struct {
+	g         *Graph
+	x         *Node
+	y         *Node
+	include   *utils.Set[*Node]
+	allowed   *utils.Set[*Node]
+	ancestors *utils.Set[*Node]
+}
+
+func newSeparatorProblem(g *Graph, x, y *Node, include, restrict []*Node) *separatorProblem {
+	p := &separatorProblem{
+		g:       g,
+		x:       x,
+		y:       y,
+		include: utils.NewSet(include...),
+	}
+	if restrict == nil {
+		restrict = g.GetNodes()
+	}
+	p.allowed = utils.NewSet(restrict...).Union(p.include)
+	p.allowed.Remove(x)
+	p.allowed.Remove(y)
+	p.ancestors = utils.NewSet(g.GetAncestors(append([]*Node{x, y}, include...))...)
+	return p
+}
+
+// valid reports whether the constraints can be satisfied at all.
+func (p *separatorProblem) valid() bool {
+	return !p.include.Contains(p.x) && !p.include.Contains(p.y) && p.x != p.y
+}
+
+// candidates returns the allowed ancestral nodes that are not forced into the separator.
+func (p *separatorProblem) candidates() []*Node {
+	var nodes []*Node
+	for _, n := range p.g.GetNodes() {
+		if p.allowed.Contains(n) && p.ancestors.Contains(n) && !p.include.Contains(n) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// sorted returns the given nodes in the order of the graph's node list.
+func (p *separatorProblem) sorted(nodes *utils.Set[*Node]) []*Node {
+	result := make([]*Node, 0, nodes.Size())
+	for _, n := range p.g.GetNodes() {
+		if nodes.Contains(n) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+/*
+moralGraph
+
+Returns the adjacency of the moral (augmented) graph of the subgraph induced by the
+ancestral set: two nodes are adjacent iff they are joined by an edge or by a collider
+path, which for DAGs amounts to marrying parents of a common child and for mixed
+graphs also joins nodes of the same district.
+Nodes in the include set are conditioned on and therefore left out.
+*/
+func (p *separatorProblem) moralGraph() map[*Node]*utils.OrderedSet[*Node] {
+	inGraph := func(n *Node) bool {
+		return p.ancestors.Contains(n) && !p.include.Contains(n)
+	}
+	adjacency := map[*Node]*utils.OrderedSet[*Node]{}
+	for _, n := range p.g.GetNodes() {
+		if inGraph(n) {
+			adjacency[n] = utils.NewOrderedSet[*Node]()
+		}
+	}
+	for _, a := range p.g.GetNodes() {
+		if !p.ancestors.Contains(a) {
+			continue
+		}
+		// walk collider paths starting at a; every node met along the way is
+		// adjacent to a in the moral graph
+		visited := utils.NewSet(a)
+		q := utils.NewQueue(a)
+		for q.Size() > 0 {
+			v := q.Pop()
+			for _, e := range p.g.GetNodeEdges(v) {
+				w := e.GetDistalNode(v)
+				if !p.ancestors.Contains(w) {
+					continue
+				}
+				if v != a && e.GetProximalEndpoint(v) != ARROW {
+					continue
+				}
+				if inGraph(a) && inGraph(w) && w != a {
+					adjacency[a].Add(w)
+					adjacency[w].Add(a)
+				}
+				if e.GetProximalEndpoint(w) == ARROW && visited.Add(w) {
+					q.Append(w)
+				}
+			}
+		}
+	}
+	return adjacency
+}
+
+/*
+FindMinimalSeparator
+
+Returns a minimal set z with include ⊆ z ⊆ restrict that d-separates x and y in g
+(m-separates them when g is an ADMG or a MAG); minimal means no proper subset of z
+containing include separates x and y. A nil restrict allows every node of g.
+The second return value is false when no such separator exists.
+
+The search starts from the ancestral set An({x, y} ∪ include) ∩ restrict, which
+separates x and y whenever any admissible set does, and drops every node whose
+removal keeps x and y separated (Tian, Paz & Pearl, 1998).
+*/
+func FindMinimalSeparator(g *Graph, x, y *Node, include, restrict []*Node) ([]*Node, bool) {
+	p := newSeparatorProblem(g, x, y, include, restrict)
+	if !p.valid() {
+		return nil, false
+	}
+	z := utils.NewOrderedSet(p.sorted(p.include)...)
+	z.AddAll(p.candidates()...)
+	if !g.IsDSeparatedFrom(x, y, z.Values()) {
+		return nil, false
+	}
+	for _, n := range p.candidates() {
+		z.Remove(n)
+		if !g.IsDSeparatedFrom(x, y, z.Values()) {
+			z.Add(n)
+		}
+	}
+	return p.sorted(utils.NewSet(z.Values()...)), true
+}
+
+/*
+FindMinimumSeparator
+
+Returns a separator of minimum cardinality among the sets z with include ⊆ z ⊆ restrict
+that d-separate x and y. The second return value is false when no such separator exists.
+The separator is found as a minimum vertex cut between x and y in the moral graph of
+An({x, y} ∪ include), where nodes outside restrict cannot be cut.
+*/
+func FindMinimumSeparator(g *Graph, x, y *Node, include, restrict []*Node) ([]*Node, bool) {
+	p := newSeparatorProblem(g, x, y, include, restrict)
+	if !p.valid() {
+		return nil, false
+	}
+	adjacency := p.moralGraph()
+	cut, ok := minimumVertexCut(adjacency, x, y, func(n *Node) bool {
+		return p.allowed.Contains(n)
+	})
+	if !ok {
+		return nil, false
+	}
+	z := p.include.Copy()
+	z.AddAll(cut...)
+	return p.sorted(z), true
+}
+
+/*
+FindNearestSeparator
+
+Returns the minimal set z with include ⊆ z ⊆ restrict that d-separates x and y and lies
+closest to x: every node of z is reachable from x without passing through another node
+of z in the moral graph of An({x, y} ∪ include). Conditioning on it keeps as much of
+x's neighbourhood unobserved as possible, which is what instrumental variable searches
+need (van der Zander, Textor & Liśkiewicz, 2015).
+The second return value is false when no such separator exists.
+*/
+func FindNearestSeparator(g *Graph, x, y *Node, include, restrict []*Node) ([]*Node, bool) {
+	p := newSeparatorProblem(g, x, y, include, restrict)
+	if !p.valid() {
+		return nil, false
+	}
+	adjacency := p.reducedGraph()
+	if adjacency[x].Contains(y) {
+		return nil, false
+	}
+	removed := utils.NewSet(x)
+	removed.AddAll(adjacency[x].Values()...)
+	cy := component(adjacency, y, removed)
+	z := p.include.Copy()
+	for _, n := range cy.Values() {
+		for _, m := range adjacency[n].Values() {
+			if adjacency[x].Contains(m) {
+				z.Add(m)
+			}
+		}
+	}
+	return p.sorted(z), true
+}
+
+/*
+minimumVertexCut
+
+Computes a minimum set of cuttable vertices separating source from sink with the
+Edmonds-Karp max-flow algorithm on the vertex-split graph.
+Returns false if source and sink cannot be separated by cuttable vertices.
+*/
+func minimumVertexCut(adjacency map[*Node]*utils.OrderedSet[*Node], source, sink *Node,
+	cuttable func(*Node) bool) ([]*Node, bool) {
+	var nodes []*Node
+	index := map[*Node]int{}
+	for n := range adjacency {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].LessThan(nodes[j]) })
+	for i, n := range nodes {
+		index[n] = i
+	}
+	// vertex v is split into v_in = 2v and v_out = 2v+1
+	infinite := len(nodes) + 1
+	size := 2 * len(nodes)
+	capacity := make([][]int, size)
+	neighbours := make([][]int, size)
+	for i := range capacity {
+		capacity[i] = make([]int, size)
+	}
+	link := func(u, v, c int) {
+		capacity[u][v] = c
+		neighbours[u] = append(neighbours[u], v)
+		neighbours[v] = append(neighbours[v], u)
+	}
+	for i, n := range nodes {
+		if cuttable(n) {
+			link(2*i, 2*i+1, 1)
+		} else {
+			link(2*i, 2*i+1, infinite)
+		}
+		for _, m := range adjacency[n].Values() {
+			link(2*i+1, 2*index[m], infinite)
+		}
+	}
+	s := 2*index[source] + 1
+	t := 2 * index[sink]
+
+	// breadth-first search in the residual graph, returning the search tree
+	residual := func() []int {
+		parent := make([]int, size)
+		for i := range parent {
+			parent[i] = -1
+		}
+		parent[s] = s
+		q := utils.NewQueue(s)
+		for q.Size() > 0 {
+			u := q.Pop()
+			for _, v := range neighbours[u] {
+				if capacity[u][v] > 0 && parent[v] == -1 {
+					parent[v] = u
+					q.Append(v)
+				}
+			}
+		}
+		return parent
+	}
+
+	flow := 0
+	for {
+		parent := residual()
+		if parent[t] == -1 {
+			break
+		}
+		bottleneck := infinite
+		for v := t; v != s; v = parent[v] {
+			if c := capacity[parent[v]][v]; c < bottleneck {
+				bottleneck = c
+			}
+		}
+		for v := t; v != s; v = parent[v] {
+			capacity[parent[v]][v] -= bottleneck
+			capacity[v][parent[v]] += bottleneck
+		}
+		flow += bottleneck
+		if flow >= infinite {
+			return nil, false
+		}
+	}
+
+	// vertices whose in-copy is reachable in the residual graph but whose out-copy is not
+	parent := residual()
+	var cut []*Node
+	for i, n := range nodes {
+		if parent[2*i] != -1 && parent[2*i+1] == -1 {
+			cut = append(cut, n)
+		}
+	}
+	return cut, true
+}
+
+/*
+ListMinimalSeparators
+
+Enumerates every minimal set z with include ⊆ z ⊆ restrict that d-separates x and y.
+Nodes outside restrict are eliminated from the moral graph of An({x, y} ∪ include)
+by joining their neighbours, after which the minimal x-y separators of the reduced
+graph are listed with the close-separator generation of Kloks & Kratsch (1998):
+starting from the separator closest to x, each separator S and each v ∈ S yield the
+neighbourhood of y's component after removing the closed neighbourhood of
+C_x(S) ∪ {v}.
+*/
+func ListMinimalSeparators(g *Graph, x, y *Node, include, restrict []*Node) [][]*Node {
+	p := newSeparatorProblem(g, x, y, include, restrict)
+	if !p.valid() {
+		return nil
+	}
+	adjacency := p.reducedGraph()
+	if adjacency[x].Contains(y) {
+		return nil
+	}
+
+	var separators [][]*Node
+	seen := map[string]bool{}
+	q := utils.Queue[string]{}
+	sets := map[string]*utils.Set[*Node]{}
+	record := func(s *utils.Set[*Node]) {
+		key := p.key(s)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		sets[key] = s
+		q.Append(key)
+		z := s.Union(p.include)
+		separators = append(separators, p.sorted(z))
+	}
+
+	closedNeighbourhood := func(nodes *utils.Set[*Node]) *utils.Set[*Node] {
+		closed := nodes.Copy()
+		for _, n := range nodes.Values() {
+			closed.AddAll(adjacency[n].Values()...)
+		}
+		return closed
+	}
+	neighbourhood := func(component *utils.Set[*Node]) *utils.Set[*Node] {
+		return closedNeighbourhood(component).Difference(component)
+	}
+
+	removed := closedNeighbourhood(utils.NewSet(x))
+	record(neighbourhood(component(adjacency, y, removed)))
+	for q.Size() > 0 {
+		s := sets[q.Pop()]
+		cx := component(adjacency, x, s)
+		for _, v := range p.sorted(s) {
+			a := cx.Copy()
+			a.Add(v)
+			removed := closedNeighbourhood(a)
+			if removed.Contains(y) {
+				continue
+			}
+			record(neighbourhood(component(adjacency, y, removed)))
+		}
+	}
+	return separators
+}
+
+/*
+reducedGraph
+
+Returns the moral graph restricted to x, y and the candidate nodes, where two nodes are
+adjacent iff the moral graph joins them through nodes that may not be cut.
+*/
+func (p *separatorProblem) reducedGraph() map[*Node]*utils.OrderedSet[*Node] {
+	moral := p.moralGraph()
+	keep := utils.NewSet(p.candidates()...)
+	keep.Add(p.x)
+	keep.Add(p.y)
+	adjacency := map[*Node]*utils.OrderedSet[*Node]{}
+	for _, n := range keep.Values() {
+		adjacency[n] = utils.NewOrderedSet[*Node]()
+	}
+	for _, a := range keep.Values() {
+		visited := utils.NewSet(a)
+		q := utils.NewQueue(a)
+		for q.Size() > 0 {
+			v := q.Pop()
+			for _, w := range moral[v].Values() {
+				if !visited.Add(w) {
+					continue
+				}
+				if keep.Contains(w) {
+					adjacency[a].Add(w)
+				} else {
+					q.Append(w)
+				}
+			}
+		}
+	}
+	return adjacency
+}
+
+func (p *separatorProblem) key(nodes *utils.Set[*Node]) string {
+	var names []string
+	for _, n := range p.sorted(nodes) {
+		names = append(names, n.GetName())
+	}
+	return strings.Join(names, "\x00")
+}
+
+// component returns the connected component of start after deleting the removed nodes.
+func component(adjacency map[*Node]*utils.OrderedSet[*Node], start *Node,
+	removed *utils.Set[*Node]) *utils.Set[*Node] {
+	visited := utils.NewSet(start)
+	q := utils.NewQueue(start)
+	for q.Size() > 0 {
+		v := q.Pop()
+		for _, w := range adjacency[v].Values() {
+			if !removed.Contains(w) && visited.Add(w) {
+				q.Append(w)
+			}
+		}
+	}
+	return visited
+}
+
+/*
+IsMinimalSeparator
+
+Returns true iff z d-separates x and y, include ⊆ z ⊆ restrict, and no proper subset of z
+that still contains include separates x and y. A nil restrict allows every node of g.
+*/
+func IsMinimalSeparator(g *Graph, x, y *Node, z, include, restrict []*Node) bool {
+	p := newSeparatorProblem(g, x, y, include, restrict)
+	if !p.valid() {
+		return false
+	}
+	set := utils.NewOrderedSet(z...)
+	if set.Contains(x) || set.Contains(y) || !p.include.IsSubsetOf(utils.NewSet(z...)) {
+		return false
+	}
+	for _, n := range z {
+		if !p.allowed.Contains(n) || !p.ancestors.Contains(n) {
+			return false
+		}
+	}
+	if !g.IsDSeparatedFrom(x, y, z) {
+		return false
+	}
+	// within the ancestral set separation is monotone, so it suffices to try
+	// removing one node at a time
+	for _, n := range z {
+		if p.include.Contains(n) {
+			continue
+		}
+		set.Remove(n)
+		separated := g.IsDSeparatedFrom(x, y, set.Values())
+		set.Add(n)
+		if separated {
+			return false
+		}
+	}
+	return true
+}
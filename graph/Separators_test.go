@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// bruteMinimalSeparators returns, keyed by their sorted names, every set z with
+// include ⊆ z ⊆ restrict that d-separates x and y while no proper subset containing
+// include does.
+func bruteMinimalSeparators(g *Graph, x, y *Node, include, restrict []*Node) map[string][]*Node {
+	required := map[*Node]bool{}
+	for _, n := range include {
+		required[n] = true
+	}
+	var free []*Node
+	for _, n := range restrict {
+		if n != x && n != y && !required[n] {
+			free = append(free, n)
+		}
+	}
+	separating := map[int]bool{}
+	sets := map[int][]*Node{}
+	for mask := 0; mask < 1<<len(free); mask++ {
+		z := append([]*Node{}, include...)
+		for i, n := range free {
+			if mask&(1<<i) != 0 {
+				z = append(z, n)
+			}
+		}
+		sets[mask] = z
+		separating[mask] = g.IsDSeparatedFrom(x, y, z)
+	}
+	minimal := map[string][]*Node{}
+	for mask, z := range sets {
+		if !separating[mask] {
+			continue
+		}
+		isMinimal := true
+		for sub := (mask - 1) & mask; sub != mask; sub = (sub - 1) & mask {
+			if separating[sub] {
+				isMinimal = false
+				break
+			}
+			if sub == 0 {
+				break
+			}
+		}
+		if isMinimal {
+			minimal[separatorKey(z)] = z
+		}
+	}
+	return minimal
+}
+
+func separatorKey(z []*Node) string {
+	names := make([]string, len(z))
+	for i, n := range z {
+		names[i] = n.GetName()
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func TestSeparatorsMatchBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(29))
+	for trial := 0; trial < 200; trial++ {
+		n := 3 + rng.Intn(5)
+		g := randomDag(rng, n, 0.2+0.5*rng.Float64())
+		nodes := g.GetNodes()
+		perm := rng.Perm(n)
+		x, y := nodes[perm[0]], nodes[perm[1]]
+		var include []*Node
+		restrict := []*Node{}
+		for _, k := range perm[2:] {
+			switch r := rng.Float64(); {
+			case r < 0.15:
+				include = append(include, nodes[k])
+				restrict = append(restrict, nodes[k])
+			case r < 0.85:
+				restrict = append(restrict, nodes[k])
+			}
+		}
+		want := bruteMinimalSeparators(g, x, y, include, restrict)
+
+		minimal, ok := FindMinimalSeparator(g, x, y, include, restrict)
+		if ok != (len(want) > 0) {
+			t.Fatalf("FindMinimalSeparator found %v, brute force %d separators in\n%s", ok, len(want), g.ToString())
+		}
+		if ok {
+			if _, found := want[separatorKey(minimal)]; !found {
+				t.Fatalf("FindMinimalSeparator returned %s, not a minimal separator in\n%s", separatorKey(minimal), g.ToString())
+			}
+			if !IsMinimalSeparator(g, x, y, minimal, include, restrict) {
+				t.Fatalf("IsMinimalSeparator rejects %s", separatorKey(minimal))
+			}
+		}
+
+		minimum, ok := FindMinimumSeparator(g, x, y, include, restrict)
+		if ok != (len(want) > 0) {
+			t.Fatalf("FindMinimumSeparator found %v, brute force %d separators", ok, len(want))
+		}
+		if ok {
+			if !g.IsDSeparatedFrom(x, y, minimum) {
+				t.Fatalf("FindMinimumSeparator returned %s, which does not separate", separatorKey(minimum))
+			}
+			for _, z := range want {
+				if len(z) < len(minimum) {
+					t.Fatalf("FindMinimumSeparator returned %s but %s is smaller", separatorKey(minimum), separatorKey(z))
+				}
+			}
+		}
+
+		listed := ListMinimalSeparators(g, x, y, include, restrict)
+		if len(listed) != len(want) {
+			t.Fatalf("ListMinimalSeparators listed %d separators, brute force %d in\n%s", len(listed), len(want), g.ToString())
+		}
+		for _, z := range listed {
+			if _, found := want[separatorKey(z)]; !found {
+				t.Fatalf("ListMinimalSeparators listed %s, not a minimal separator", separatorKey(z))
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package graph
+
+/*
+Do
+
+Returns the post-intervention graph G with the nodes overlined: a copy of g in which
+every edge with an arrowhead at one of the intervened nodes is removed, i.e. the
+directed edges from their parents and the bidirected edges standing for latent
+confounders.
+*/
+func Do(g *Graph, nodes []*Node) *Graph {
+	return Mutilate(g, nodes, nil)
+}
+
+/*
+RemoveOutgoing
+
+Returns the graph G with the nodes underlined: a copy of g in which every directed edge
+out of one of the nodes is removed.
+*/
+func RemoveOutgoing(g *Graph, nodes []*Node) *Graph {
+	return Mutilate(g, nil, nodes)
+}
+
+/*
+Mutilate
+
+Returns the graph with the incoming nodes overlined and the outgoing nodes underlined,
+as used by the rules of the do-calculus: the edges into the incoming nodes and the
+directed edges out of the outgoing nodes are removed from a copy of g.
+*/
+func Mutilate(g *Graph, incoming, outgoing []*Node) *Graph {
+	m := g.Copy()
+	for _, n := range incoming {
+		for _, p := range g.GetParents(n) {
+			m.RemoveEdge(DirectedEdge(p, n))
+		}
+		for _, e := range g.GetNodeEdges(n) {
+			if IsBidirectedEdge(e) {
+				m.RemoveEdge(e)
+			}
+		}
+	}
+	for _, n := range outgoing {
+		for _, c := range g.GetChildren(n) {
+			m.RemoveEdge(DirectedEdge(n, c))
+		}
+	}
+	return m
+}
@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func edgeStrings(g *Graph) []string {
+	var edges []string
+	for _, e := range g.GetGraphEdges() {
+		edges = append(edges, e.ToString())
+	}
+	sort.Strings(edges)
+	return edges
+}
+
+func TestMutilate(t *testing.T) {
+	// X0 --> X1 --> X2 --> X3, X1 --> X3 with X1 <-> X3 and X0 <-> X2
+	nodes := newTestNodes(4)
+	g := NewGraph(nodes)
+	g.AddDirectedEdge(nodes[0], nodes[1])
+	g.AddDirectedEdge(nodes[1], nodes[2])
+	g.AddDirectedEdge(nodes[2], nodes[3])
+	g.AddDirectedEdge(nodes[1], nodes[3])
+	g.AddEdge(BidirectedEdge(nodes[1], nodes[3]))
+	g.AddEdge(BidirectedEdge(nodes[0], nodes[2]))
+	all := edgeStrings(g)
+
+	cases := []struct {
+		name string
+		got  *Graph
+		want []string
+	}{
+		{"do(X1)", Do(g, []*Node{nodes[1]}), []string{"X0 <-> X2", "X1 --> X2", "X1 --> X3", "X2 --> X3"}},
+		{"do(X2, X3)", Do(g, []*Node{nodes[2], nodes[3]}), []string{"X0 --> X1"}},
+		{"remove outgoing X1", RemoveOutgoing(g, []*Node{nodes[1]}), []string{"X0 --> X1", "X0 <-> X2", "X1 <-> X3", "X2 --> X3"}},
+		{"remove outgoing X3", RemoveOutgoing(g, []*Node{nodes[3]}), all},
+		{"mutilate X3 and X0", Mutilate(g, []*Node{nodes[3]}, []*Node{nodes[0]}), []string{"X0 <-> X2", "X1 --> X2"}},
+	}
+	for _, c := range cases {
+		if got := edgeStrings(c.got); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+	if got := edgeStrings(g); !reflect.DeepEqual(got, all) {
+		t.Errorf("the original graph changed: got %v, want %v", got, all)
+	}
+}
@@ -0,0 +1,212 @@
+package graph
+
+import "fmt"
+
+/*
+TimeLagGraph
+
+A graph over the variables of a time series unrolled over lags 0 .. maxLag, lag 0 being
+the present. The node of a variable at lag l > 0 is named "name:l". Edges added through
+AddLaggedEdge are repeated at every shift in time that keeps both ends within the
+window, so the graph describes a stationary process.
+The node list holds the variables at lag 0, then at lag 1 and so on, the column order of
+LaggedData.
+*/
+type TimeLagGraph struct {
+	*Graph
+	maxLag    int
+	variables []*Node
+	// lagged[l][i] is variable i at lag l
+	lagged [][]*Node
+	// variable index and lag of every node
+	position map[*Node][2]int
+}
+
+/*
+NewTimeLagGraph
+
+Returns an empty time-lag graph whose lag 0 nodes are the given variables, creating
+their copies at lags 1 .. maxLag.
+*/
+func NewTimeLagGraph(variables []*Node, maxLag int) (*TimeLagGraph, error) {
+	if maxLag < 0 {
+		return nil, fmt.Errorf("maximum lag must not be negative")
+	}
+	t := &TimeLagGraph{
+		maxLag:    maxLag,
+		variables: append([]*Node{}, variables...),
+		position:  map[*Node][2]int{},
+	}
+	var nodes []*Node
+	for lag := 0; lag <= maxLag; lag++ {
+		var row []*Node
+		for i, v := range variables {
+			n := v
+			if lag > 0 {
+				n = &Node{name: fmt.Sprintf("%s:%d", v.GetName(), lag), nodeType: v.nodeType}
+			}
+			t.position[n] = [2]int{i, lag}
+			row = append(row, n)
+		}
+		t.lagged = append(t.lagged, row)
+		nodes = append(nodes, row...)
+	}
+	t.Graph = NewGraph(nodes)
+	t.Graph.timeLag = true
+	return t, nil
+}
+
+func (t *TimeLagGraph) GetMaxLag() int {
+	return t.maxLag
+}
+
+/*
+GetLag0Nodes
+
+Returns the nodes of the variables at lag 0.
+*/
+func (t *TimeLagGraph) GetLag0Nodes() []*Node {
+	return t.variables
+}
+
+/*
+GetNodeLag
+
+Returns the lag of the node, -1 if it is not in the graph.
+*/
+func (t *TimeLagGraph) GetNodeLag(node *Node) int {
+	p, ok := t.position[node]
+	if !ok {
+		return -1
+	}
+	return p[1]
+}
+
+/*
+GetLaggedNode
+
+Returns the node of the variable of the given node at the given lag, nil if the lag is
+outside 0 .. maxLag.
+*/
+func (t *TimeLagGraph) GetLaggedNode(node *Node, lag int) *Node {
+	p, ok := t.position[node]
+	if !ok || lag < 0 || lag > t.maxLag {
+		return nil
+	}
+	return t.lagged[lag][p[0]]
+}
+
+/*
+AddLaggedEdge
+
+Adds the directed edge from -> to together with its copies shifted in time. Edges point
+forward in time, so from must be at a lag at least that of to; a contemporaneous edge
+joins two different variables at the same lag.
+*/
+func (t *TimeLagGraph) AddLaggedEdge(from, to *Node) error {
+	shifts, err := t.shifts(from, to)
+	if err != nil {
+		return err
+	}
+	for _, s := range shifts {
+		a, b := t.lagged[s[0]][t.position[from][0]], t.lagged[s[1]][t.position[to][0]]
+		if !t.IsDirectedFromTo(a, b) {
+			t.RemoveConnectingEdges(a, b)
+			t.AddDirectedEdge(a, b)
+		}
+	}
+	return nil
+}
+
+/*
+AddLaggedUndirectedEdge
+
+Adds the undirected edge node1 --- node2 between two variables at the same lag, together
+with its copies at every other lag, for contemporaneous links whose direction is unknown.
+*/
+func (t *TimeLagGraph) AddLaggedUndirectedEdge(node1, node2 *Node) error {
+	if t.GetNodeLag(node1) != t.GetNodeLag(node2) {
+		return fmt.Errorf("undirected edge from %s to %s is not contemporaneous", node1.GetName(), node2.GetName())
+	}
+	shifts, err := t.shifts(node1, node2)
+	if err != nil {
+		return err
+	}
+	for _, s := range shifts {
+		a, b := t.lagged[s[0]][t.position[node1][0]], t.lagged[s[1]][t.position[node2][0]]
+		if !t.IsUndirectedFromTo(a, b) {
+			t.RemoveConnectingEdges(a, b)
+			t.AddEdge(UndirectedEdge(a, b))
+		}
+	}
+	return nil
+}
+
+/*
+RemoveLaggedEdge
+
+Removes the edge between from and to, directed or not, together with its copies shifted
+in time.
+*/
+func (t *TimeLagGraph) RemoveLaggedEdge(from, to *Node) error {
+	shifts, err := t.shifts(from, to)
+	if err != nil {
+		return err
+	}
+	for _, s := range shifts {
+		a, b := t.lagged[s[0]][t.position[from][0]], t.lagged[s[1]][t.position[to][0]]
+		t.RemoveConnectingEdges(a, b)
+	}
+	return nil
+}
+
+// shifts returns the lags of both ends of every copy of the edge from -> to.
+func (t *TimeLagGraph) shifts(from, to *Node) ([][2]int, error) {
+	p, ok1 := t.position[from]
+	q, ok2 := t.position[to]
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("nodes must belong to the graph")
+	}
+	if p[1] < q[1] {
+		return nil, fmt.Errorf("edge from %s to %s points back in time", from.GetName(), to.GetName())
+	}
+	if from == to {
+		return nil, fmt.Errorf("edge from %s to itself", from.GetName())
+	}
+	span := p[1] - q[1]
+	var shifts [][2]int
+	for lag := 0; lag+span <= t.maxLag; lag++ {
+		shifts = append(shifts, [2]int{lag + span, lag})
+	}
+	return shifts, nil
+}
+
+/*
+IsRepeating
+
+Returns true if every edge of the graph is either directed forward in time or undirected
+between two nodes at the same lag, and present at every shift that keeps both of its ends
+within the window, as AddLaggedEdge and AddLaggedUndirectedEdge guarantee.
+Edges added directly to the underlying graph may break this.
+*/
+func (t *TimeLagGraph) IsRepeating() bool {
+	for _, e := range t.GetGraphEdges() {
+		from, to := e.GetNode1(), e.GetNode2()
+		directed := e.GetEndpoint1() == TAIL && e.GetEndpoint2() == ARROW
+		undirected := e.GetEndpoint1() == TAIL && e.GetEndpoint2() == TAIL
+		if !directed && !(undirected && t.GetNodeLag(from) == t.GetNodeLag(to)) {
+			return false
+		}
+		shifts, err := t.shifts(from, to)
+		if err != nil {
+			return false
+		}
+		for _, s := range shifts {
+			a, b := t.lagged[s[0]][t.position[from][0]], t.lagged[s[1]][t.position[to][0]]
+			if (directed && !t.IsDirectedFromTo(a, b)) || (undirected && !t.IsUndirectedFromTo(a, b)) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTimeLagGraphRepeatsEdges(t *testing.T) {
+	variables := newTestNodes(2)
+	if _, err := NewTimeLagGraph(variables, -1); err == nil {
+		t.Error("a negative maximum lag must fail")
+	}
+	g, err := NewTimeLagGraph(variables, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x0, x1 := variables[0], variables[1]
+	x0lag1, x1lag1 := g.GetLaggedNode(x0, 1), g.GetLaggedNode(x1, 1)
+	x0lag2, x1lag2 := g.GetLaggedNode(x0, 2), g.GetLaggedNode(x1, 2)
+	names := ""
+	for _, n := range g.GetNodes() {
+		names += n.GetName() + ";"
+	}
+	if names != "X0;X1;X0:1;X1:1;X0:2;X1:2;" {
+		t.Fatalf("nodes: got %s", names)
+	}
+	if g.GetNodeLag(x1lag2) != 2 || g.GetNodeLag(&Node{}) != -1 || g.GetLaggedNode(x1lag2, 3) != nil || g.GetLaggedNode(x1lag2, 0) != x1 {
+		t.Error("lags of the nodes are wrong")
+	}
+	if !g.IsTimeLagModel() || !g.Subgraph([]*Node{x0, x0lag1}).IsTimeLagModel() || !g.Copy().IsTimeLagModel() {
+		t.Error("copies and subgraphs of a time-lag graph are time-lag models")
+	}
+
+	// a lag 1 link is repeated between lags 2 and 1, a contemporaneous one at every lag
+	if err := g.AddLaggedEdge(x0lag1, x1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddLaggedEdge(x0lag1, x0lag1); err == nil {
+		t.Error("a self loop must fail")
+	}
+	if err := g.AddLaggedEdge(x1, x0lag1); err == nil {
+		t.Error("an edge back in time must fail")
+	}
+	if err := g.AddLaggedUndirectedEdge(x0, x1lag1); err == nil {
+		t.Error("an undirected edge across lags must fail")
+	}
+	if err := g.AddLaggedUndirectedEdge(x0lag2, x1lag2); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"X0 --- X1", "X0:1 --- X1:1", "X0:1 --> X1", "X0:2 --- X1:2", "X0:2 --> X1:1"}
+	if got := edgeStrings(g.Graph); !reflect.DeepEqual(got, want) || !g.IsRepeating() {
+		t.Fatalf("got edges %v, want %v", got, want)
+	}
+	// directing the contemporaneous link replaces every copy
+	if err := g.AddLaggedEdge(x1, x0); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"X0:1 --> X1", "X0:2 --> X1:1", "X1 --> X0", "X1:1 --> X0:1", "X1:2 --> X0:2"}
+	if got := edgeStrings(g.Graph); !reflect.DeepEqual(got, want) || !g.IsRepeating() {
+		t.Fatalf("got edges %v, want %v", got, want)
+	}
+	if err := g.RemoveLaggedEdge(x0lag2, x1lag1); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"X1 --> X0", "X1:1 --> X0:1", "X1:2 --> X0:2"}
+	if got := edgeStrings(g.Graph); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got edges %v, want %v", got, want)
+	}
+
+	// edges added to the underlying graph can break the repeating structure
+	g.AddDirectedEdge(x0lag1, x1)
+	if g.IsRepeating() {
+		t.Error("X0:1 --> X1 without X0:2 --> X1:1 does not repeat")
+	}
+	g.RemoveConnectingEdges(x0lag1, x1)
+	g.AddDirectedEdge(x1, x0lag2)
+	if g.IsRepeating() {
+		t.Error("an edge pointing back in time does not repeat")
+	}
+}
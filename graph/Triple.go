@@ -5,3 +5,37 @@ type Triple struct {
 	y *Node
 	z *Node
 }
+
+/*
+NewTriple
+
+Returns the triple <x, y, z> with y in the middle, the same triple as <z, y, x>.
+*/
+func NewTriple(x, y, z *Node) *Triple {
+	return &Triple{x: x, y: y, z: z}
+}
+
+func (t *Triple) GetX() *Node {
+	return t.x
+}
+
+func (t *Triple) GetY() *Node {
+	return t.y
+}
+
+func (t *Triple) GetZ() *Node {
+	return t.z
+}
+
+func (t *Triple) Equals(other *Triple) bool {
+	return t.y == other.y && (t.x == other.x && t.z == other.z || t.x == other.z && t.z == other.x)
+}
+
+func containsTriple(triples []*Triple, triple *Triple) bool {
+	for _, t := range triples {
+		if t.Equals(triple) {
+			return true
+		}
+	}
+	return false
+}
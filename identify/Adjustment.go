@@ -0,0 +1,361 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"GoCausal/utils"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoAdjustmentSet = errors.New("no valid adjustment set exists")
+	ErrNotAmenable     = errors.New("graph is not amenable: some possibly causal path does not start with a visible edge")
+)
+
+/*
+adjustment
+
+Bundles what the generalized adjustment criterion (Perković, Textor, Kalisch & Maathuis,
+2018) needs for a pair x, y: the causal nodes, the forbidden nodes and the proper
+back-door graph in which adjustment sets are exactly the separators avoiding them.
+
+The graph is read according to its flags: a pattern is a CPDAG whose undirected edges
+may point either way, a PAG uses circle endpoints and Zhang's edge visibility, and any
+other graph is read causally as a DAG or an ADMG with explicit bidirected confounding.
+*/
+type adjustment struct {
+	g *graph.Graph
+	x *graph.Node
+	y *graph.Node
+	// possibly causal nodes: nodes other than x on a proper possibly causal path from x to y
+	causal *utils.Set[*graph.Node]
+	// x together with the possible descendants of causal nodes
+	forbidden *utils.Set[*graph.Node]
+	// children of x through which a proper possibly causal path leaves x
+	first    []*graph.Node
+	amenable bool
+	// a DAG/MAG of the equivalence class with the first edges of proper causal paths removed
+	backdoor *graph.Graph
+}
+
+func newAdjustment(g *graph.Graph, x, y *graph.Node) (*adjustment, error) {
+	if !g.ContainsNode(x) || !g.ContainsNode(y) {
+		return nil, fmt.Errorf("treatment and outcome must be nodes of the graph")
+	}
+	if x == y {
+		return nil, fmt.Errorf("treatment and outcome must differ")
+	}
+	a := &adjustment{g: g, x: x, y: y, amenable: true}
+
+	avoidX := utils.NewSet(x)
+	reachesY := possibleAncestors(g, []*graph.Node{y}, avoidX)
+	var starts []*graph.Node
+	// edges through which the paths leave x; read from the edge list, since GetEdge
+	// cannot tell x --> d apart from a bidirected edge joining the same pair
+	var firstEdges []*graph.Edge
+	for _, e := range g.GetNodeEdges(x) {
+		d := e.GetDistalNode(x)
+		if possiblyDirected(g, e, x) && reachesY.Contains(d) {
+			starts = append(starts, d)
+			firstEdges = append(firstEdges, e)
+		}
+	}
+	a.causal = possibleDescendants(g, starts, avoidX).Intersection(reachesY)
+	a.forbidden = possibleDescendants(g, a.causal.Values(), nil)
+	a.forbidden.Add(x)
+
+	for k, d := range starts {
+		a.first = append(a.first, d)
+		e := firstEdges[k]
+		if e.GetProximalEndpoint(x) != graph.TAIL || e.GetProximalEndpoint(d) != graph.ARROW || !isVisible(g, x, d) {
+			a.amenable = false
+		}
+	}
+	if !a.amenable {
+		return a, nil
+	}
+
+	var err error
+	if g.IsPattern() {
+		a.backdoor, err = graph.PdagToDag(g)
+	} else if g.IsPag() {
+		a.backdoor, err = graph.PagToMag(g)
+	} else {
+		a.backdoor = g.Copy()
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range a.first {
+		a.backdoor.RemoveEdge(graph.DirectedEdge(x, d))
+	}
+	return a, nil
+}
+
+/*
+possiblyDirected
+
+Returns true if the edge could be oriented out of u in some member of the class the
+graph represents, i.e. it has no arrowhead at u and no tail at the other end.
+Undirected edges of a CPDAG can be oriented either way.
+*/
+func possiblyDirected(g *graph.Graph, e *graph.Edge, u *graph.Node) bool {
+	near := e.GetProximalEndpoint(u)
+	far := e.GetDistalEndpoint(u)
+	if near == graph.ARROW {
+		return false
+	}
+	if g.IsPattern() && near == graph.TAIL && far == graph.TAIL {
+		return true
+	}
+	return far == graph.ARROW || far == graph.CIRCLE
+}
+
+// possibleDescendants returns the nodes reachable from sources along possibly directed
+// edges without passing through the avoided nodes, sources included.
+func possibleDescendants(g *graph.Graph, sources []*graph.Node, avoid *utils.Set[*graph.Node]) *utils.Set[*graph.Node] {
+	return possibleReach(g, sources, avoid, true)
+}
+
+// possibleAncestors returns the nodes from which a possibly directed path reaches one of
+// the targets without passing through the avoided nodes, targets included.
+func possibleAncestors(g *graph.Graph, targets []*graph.Node, avoid *utils.Set[*graph.Node]) *utils.Set[*graph.Node] {
+	return possibleReach(g, targets, avoid, false)
+}
+
+func possibleReach(g *graph.Graph, start []*graph.Node, avoid *utils.Set[*graph.Node], forward bool) *utils.Set[*graph.Node] {
+	visited := utils.NewSet(start...)
+	q := utils.NewQueue(start...)
+	for q.Size() > 0 {
+		u := q.Pop()
+		for _, e := range g.GetNodeEdges(u) {
+			v := e.GetDistalNode(u)
+			if avoid != nil && avoid.Contains(v) {
+				continue
+			}
+			if forward && !possiblyDirected(g, e, u) || !forward && !possiblyDirected(g, e, v) {
+				continue
+			}
+			if visited.Add(v) {
+				q.Append(v)
+			}
+		}
+	}
+	return visited
+}
+
+/*
+isVisible
+
+Returns true if the directed edge x --> d carries no hidden confounding.
+Edges of DAGs, CPDAGs and ADMGs are always visible; in a PAG the edge is visible iff some
+node v not adjacent to d has an edge into x, or is joined to x by a collider path into x
+whose inner nodes are all parents of d (Zhang, 2008).
+*/
+func isVisible(g *graph.Graph, x, d *graph.Node) bool {
+	if !g.IsPag() {
+		return true
+	}
+	visited := utils.NewSet(x)
+	q := utils.NewQueue(x)
+	for q.Size() > 0 {
+		q1 := q.Pop()
+		for _, e := range g.GetNodeEdges(q1) {
+			if e.GetProximalEndpoint(q1) != graph.ARROW {
+				continue
+			}
+			v := e.GetDistalNode(q1)
+			if v != d && !g.IsAdjacentTo(v, d) {
+				return true
+			}
+			if graph.IsBidirectedEdge(e) && g.IsDirectedFromTo(v, d) && visited.Add(v) {
+				q.Append(v)
+			}
+		}
+	}
+	return false
+}
+
+// valid checks the generalized adjustment criterion for z.
+func (a *adjustment) valid(z []*graph.Node) bool {
+	if !a.amenable {
+		return false
+	}
+	for _, n := range z {
+		if n == a.y || a.forbidden.Contains(n) {
+			return false
+		}
+	}
+	return a.backdoor.IsDSeparatedFrom(a.x, a.y, z)
+}
+
+// candidates returns the nodes allowed in an adjustment set, optionally excluding the
+// possible descendants of x as the back-door criterion requires.
+func (a *adjustment) candidates(backdoor bool) []*graph.Node {
+	excluded := a.forbidden
+	if backdoor {
+		excluded = excluded.Union(possibleDescendants(a.g, []*graph.Node{a.x}, nil))
+	}
+	var nodes []*graph.Node
+	for _, n := range a.g.GetNodes() {
+		if n != a.y && !excluded.Contains(n) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+func (a *adjustment) errNoSet() error {
+	if !a.amenable {
+		return ErrNotAmenable
+	}
+	return ErrNoAdjustmentSet
+}
+
+/*
+IsValidAdjustmentSet
+
+Returns true if adjusting for z identifies the total effect of x on y by the
+generalized adjustment criterion: the graph is amenable relative to (x, y), z contains
+no forbidden node, and z blocks every proper non-causal path from x to y.
+Works for DAGs, ADMGs, CPDAGs (graphs flagged as patterns) and PAGs.
+*/
+func IsValidAdjustmentSet(g *graph.Graph, x, y *graph.Node, z []*graph.Node) bool {
+	a, err := newAdjustment(g, x, y)
+	if err != nil {
+		return false
+	}
+	return a.valid(z)
+}
+
+/*
+IsValidBackdoorSet
+
+Returns true if z satisfies the back-door criterion relative to (x, y): z contains no
+(possible) descendant of x and blocks every path between x and y that starts with an
+edge into x. On CPDAGs and PAGs this is the generalized back-door criterion of
+Maathuis & Colombo (2015).
+*/
+func IsValidBackdoorSet(g *graph.Graph, x, y *graph.Node, z []*graph.Node) bool {
+	a, err := newAdjustment(g, x, y)
+	if err != nil {
+		return false
+	}
+	descendants := possibleDescendants(g, []*graph.Node{x}, nil)
+	for _, n := range z {
+		if descendants.Contains(n) {
+			return false
+		}
+	}
+	return a.valid(z)
+}
+
+/*
+FindBackdoorSets
+
+Enumerates every minimal back-door set for the effect of x on y.
+Returns ErrNotAmenable or ErrNoAdjustmentSet when no valid set exists.
+*/
+func FindBackdoorSets(g *graph.Graph, x, y *graph.Node) ([][]*graph.Node, error) {
+	a, err := newAdjustment(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !a.amenable {
+		return nil, a.errNoSet()
+	}
+	sets := graph.ListMinimalSeparators(a.backdoor, x, y, nil, a.candidates(true))
+	if len(sets) == 0 {
+		return nil, a.errNoSet()
+	}
+	return sets, nil
+}
+
+/*
+FindMinimalBackdoorSet
+
+Returns one minimal back-door set for the effect of x on y.
+Returns ErrNotAmenable or ErrNoAdjustmentSet when no valid set exists.
+*/
+func FindMinimalBackdoorSet(g *graph.Graph, x, y *graph.Node) ([]*graph.Node, error) {
+	a, err := newAdjustment(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !a.amenable {
+		return nil, a.errNoSet()
+	}
+	z, ok := graph.FindMinimalSeparator(a.backdoor, x, y, nil, a.candidates(true))
+	if !ok {
+		return nil, a.errNoSet()
+	}
+	return z, nil
+}
+
+/*
+FindMinimumAdjustmentSet
+
+Returns a valid adjustment set of minimum cardinality for the effect of x on y.
+Returns ErrNotAmenable or ErrNoAdjustmentSet when no valid set exists.
+*/
+func FindMinimumAdjustmentSet(g *graph.Graph, x, y *graph.Node) ([]*graph.Node, error) {
+	a, err := newAdjustment(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !a.amenable {
+		return nil, a.errNoSet()
+	}
+	z, ok := graph.FindMinimumSeparator(a.backdoor, x, y, nil, a.candidates(false))
+	if !ok {
+		return nil, a.errNoSet()
+	}
+	return z, nil
+}
+
+/*
+FindOptimalAdjustmentSet
+
+Returns the optimal adjustment set O(x, y) = pa(cn(x, y)) \ forb(x, y) of Henckel,
+Perković & Maathuis (2022), which yields the smallest asymptotic variance among all valid
+adjustment sets in linear models. Defined for DAGs and CPDAGs only.
+If y is not a possible descendant of x the canonical set PossAn({x, y}) \ forb(x, y)
+is returned instead.
+Returns ErrNotAmenable or ErrNoAdjustmentSet when no valid set exists.
+*/
+func FindOptimalAdjustmentSet(g *graph.Graph, x, y *graph.Node) ([]*graph.Node, error) {
+	if g.IsPag() {
+		return nil, fmt.Errorf("optimal adjustment set is only defined for DAGs and CPDAGs")
+	}
+	for _, e := range g.GetGraphEdges() {
+		if graph.IsBidirectedEdge(e) {
+			return nil, fmt.Errorf("optimal adjustment set is only defined for DAGs and CPDAGs")
+		}
+	}
+	a, err := newAdjustment(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if !a.amenable {
+		return nil, a.errNoSet()
+	}
+	// without a causal path the effect is zero and O is undefined; fall back to the
+	// canonical set PossAn({x, y}) \ forb, which is valid whenever any set is
+	parents := possibleAncestors(g, []*graph.Node{x, y}, nil)
+	if a.causal.Size() > 0 {
+		parents = utils.NewSet[*graph.Node]()
+		for _, c := range a.causal.Values() {
+			parents.AddAll(g.GetParents(c)...)
+		}
+	}
+	var o []*graph.Node
+	for _, n := range g.GetNodes() {
+		if parents.Contains(n) && n != y && !a.forbidden.Contains(n) {
+			o = append(o, n)
+		}
+	}
+	if !a.valid(o) {
+		return nil, a.errNoSet()
+	}
+	return o, nil
+}
@@ -0,0 +1,230 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func newNodes(names ...string) []*graph.Node {
+	nodes := make([]*graph.Node, len(names))
+	for i, name := range names {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(name)
+	}
+	return nodes
+}
+
+func randomDag(rng *rand.Rand, n int, p float64) *graph.Graph {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("X%d", i)
+	}
+	nodes := newNodes(names...)
+	g := graph.NewGraph(nodes)
+	order := rng.Perm(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < p {
+				g.AddDirectedEdge(nodes[order[i]], nodes[order[j]])
+			}
+		}
+	}
+	return g
+}
+
+// subsetsOf returns every subset of nodes.
+func subsetsOf(nodes []*graph.Node) [][]*graph.Node {
+	var subsets [][]*graph.Node
+	for mask := 0; mask < 1<<len(nodes); mask++ {
+		var z []*graph.Node
+		for i, n := range nodes {
+			if mask&(1<<i) != 0 {
+				z = append(z, n)
+			}
+		}
+		subsets = append(subsets, z)
+	}
+	return subsets
+}
+
+func names(nodes []*graph.Node) string {
+	return nodeList(nodes)
+}
+
+// bruteAdjustment checks the adjustment criterion of Shpitser, VanderWeele & Robins
+// (2010) on a DAG from its definition.
+func bruteAdjustment(g *graph.Graph, x, y *graph.Node, z []*graph.Node) bool {
+	var causal []*graph.Node
+	for _, n := range g.GetNodes() {
+		if n != x && g.IsAncestorOf(x, n) && g.IsAncestorOf(n, y) {
+			causal = append(causal, n)
+		}
+	}
+	for _, n := range z {
+		if n == x || n == y {
+			return false
+		}
+		for _, c := range causal {
+			if g.IsAncestorOf(c, n) {
+				return false
+			}
+		}
+	}
+	backdoor := graph.NewGraph(g.GetNodes())
+	for _, e := range g.GetGraphEdges() {
+		from, to := e.GetNode1(), e.GetNode2()
+		isFirst := false
+		for _, c := range causal {
+			if from == x && to == c {
+				isFirst = true
+			}
+		}
+		if !isFirst {
+			backdoor.AddDirectedEdge(from, to)
+		}
+	}
+	return backdoor.IsDSeparatedFrom(x, y, z)
+}
+
+func TestAdjustmentKnownGraphs(t *testing.T) {
+	nodes := newNodes("A", "B", "M", "X", "Y")
+	a, b, m, x, y := nodes[0], nodes[1], nodes[2], nodes[3], nodes[4]
+	g := graph.NewGraph(nodes)
+	// M-bias: x <- a -> m <- b -> y
+	g.AddDirectedEdge(a, x)
+	g.AddDirectedEdge(a, m)
+	g.AddDirectedEdge(b, m)
+	g.AddDirectedEdge(b, y)
+	g.AddDirectedEdge(x, y)
+	cases := []struct {
+		z    []*graph.Node
+		want bool
+	}{
+		{nil, true},
+		{[]*graph.Node{m}, false},
+		{[]*graph.Node{m, a}, true},
+		{[]*graph.Node{m, b}, true},
+		{[]*graph.Node{y}, false},
+	}
+	for _, c := range cases {
+		if got := IsValidAdjustmentSet(g, x, y, c.z); got != c.want {
+			t.Errorf("IsValidAdjustmentSet({%s}) = %v, want %v", names(c.z), got, c.want)
+		}
+	}
+	sets, err := FindBackdoorSets(g, x, y)
+	if err != nil || len(sets) != 1 || len(sets[0]) != 0 {
+		t.Errorf("the only minimal back-door set under M-bias is empty, got %v, %v", sets, err)
+	}
+
+	// a mediator may not be adjusted for
+	g = graph.NewGraph(nodes)
+	g.AddDirectedEdge(x, m)
+	g.AddDirectedEdge(m, y)
+	g.AddDirectedEdge(a, x)
+	g.AddDirectedEdge(a, y)
+	g.AddDirectedEdge(b, y)
+	if IsValidAdjustmentSet(g, x, y, []*graph.Node{a, m}) {
+		t.Error("the mediator m is forbidden")
+	}
+	optimal, err := FindOptimalAdjustmentSet(g, x, y)
+	if err != nil || names(optimal) != "A, B" {
+		t.Errorf("optimal set is the parents of y but x, got {%s}, %v", names(optimal), err)
+	}
+	minimum, err := FindMinimumAdjustmentSet(g, x, y)
+	if err != nil || names(minimum) != "A" {
+		t.Errorf("minimum set is {A}, got {%s}, %v", names(minimum), err)
+	}
+
+	// x --> y together with x <-> y leaves nothing to adjust for
+	g = graph.NewGraph(nodes)
+	g.AddDirectedEdge(a, x)
+	g.AddDirectedEdge(x, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+	if IsValidAdjustmentSet(g, x, y, []*graph.Node{a}) {
+		t.Error("x <-> y cannot be blocked")
+	}
+	if _, err := FindMinimalBackdoorSet(g, x, y); !errors.Is(err, ErrNoAdjustmentSet) {
+		t.Errorf("want ErrNoAdjustmentSet, got %v", err)
+	}
+
+	// an undirected edge out of x is not amenable
+	g = graph.NewGraph(nodes)
+	g.AddEdge(graph.UndirectedEdge(x, y))
+	g.SetPattern(true)
+	if _, err := FindMinimalBackdoorSet(g, x, y); !errors.Is(err, ErrNotAmenable) {
+		t.Errorf("want ErrNotAmenable, got %v", err)
+	}
+}
+
+func TestAdjustmentMatchesDefinition(t *testing.T) {
+	rng := rand.New(rand.NewSource(30))
+	for trial := 0; trial < 150; trial++ {
+		n := 3 + rng.Intn(4)
+		g := randomDag(rng, n, 0.3+0.4*rng.Float64())
+		nodes := g.GetNodes()
+		perm := rng.Perm(n)
+		x, y := nodes[perm[0]], nodes[perm[1]]
+		var others []*graph.Node
+		for _, k := range perm[2:] {
+			others = append(others, nodes[k])
+		}
+		anyBackdoor, anyAdjustment := false, false
+		backdoorSets := map[int]bool{}
+		for mask, z := range subsetsOf(others) {
+			want := bruteAdjustment(g, x, y, z)
+			if got := IsValidAdjustmentSet(g, x, y, z); got != want {
+				t.Fatalf("IsValidAdjustmentSet(%s, %s, {%s}) = %v, want %v in\n%s",
+					x.GetName(), y.GetName(), names(z), got, want, g.ToString())
+			}
+			noDescendant := true
+			for _, v := range z {
+				if g.IsAncestorOf(x, v) {
+					noDescendant = false
+				}
+			}
+			anyAdjustment = anyAdjustment || want
+			anyBackdoor = anyBackdoor || want && noDescendant
+			backdoorSets[mask] = want && noDescendant
+			if got := IsValidBackdoorSet(g, x, y, z); got != (want && noDescendant) {
+				t.Fatalf("IsValidBackdoorSet(%s, %s, {%s}) = %v in\n%s", x.GetName(), y.GetName(), names(z), got, g.ToString())
+			}
+		}
+		sets, err := FindBackdoorSets(g, x, y)
+		if (err == nil) != anyBackdoor {
+			t.Fatalf("FindBackdoorSets returned %v but some back-door set exists: %v", err, anyBackdoor)
+		}
+		minimal := 0
+		for mask, valid := range backdoorSets {
+			isMinimal := valid
+			for sub := range backdoorSets {
+				if sub != mask && sub&mask == sub && backdoorSets[sub] {
+					isMinimal = false
+				}
+			}
+			if isMinimal {
+				minimal++
+			}
+		}
+		if len(sets) != minimal {
+			t.Fatalf("FindBackdoorSets found %d sets, want %d in\n%s", len(sets), minimal, g.ToString())
+		}
+		for _, z := range sets {
+			if !IsValidBackdoorSet(g, x, y, z) {
+				t.Fatalf("FindBackdoorSets returned the invalid set {%s}", names(z))
+			}
+			for i := range z {
+				smaller := append(append([]*graph.Node{}, z[:i]...), z[i+1:]...)
+				if IsValidBackdoorSet(g, x, y, smaller) {
+					t.Fatalf("back-door set {%s} is not minimal", names(z))
+				}
+			}
+		}
+		optimal, err := FindOptimalAdjustmentSet(g, x, y)
+		if (err == nil) != anyAdjustment || err == nil && !bruteAdjustment(g, x, y, optimal) {
+			t.Fatalf("optimal set {%s} is not valid: %v", names(optimal), err)
+		}
+	}
+}
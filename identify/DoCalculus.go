@@ -0,0 +1,53 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"GoCausal/utils"
+)
+
+/*
+InsertionDeletionOfObservations
+
+Rule 1 of the do-calculus (Pearl, 1995):
+P(y | do(x), z, w) = P(y | do(x), w) if y and z are d-separated by x ∪ w in the graph
+with the edges into x removed.
+*/
+func InsertionDeletionOfObservations(g *graph.Graph, y, x, z, w []*graph.Node) bool {
+	return graph.Do(g, x).AreDSeparated(y, z, union(x, w))
+}
+
+/*
+ActionObservationExchange
+
+Rule 2 of the do-calculus:
+P(y | do(x), do(z), w) = P(y | do(x), z, w) if y and z are d-separated by x ∪ w in the
+graph with the edges into x and the edges out of z removed.
+*/
+func ActionObservationExchange(g *graph.Graph, y, x, z, w []*graph.Node) bool {
+	return graph.Mutilate(g, x, z).AreDSeparated(y, z, union(x, w))
+}
+
+/*
+InsertionDeletionOfActions
+
+Rule 3 of the do-calculus:
+P(y | do(x), do(z), w) = P(y | do(x), w) if y and z are d-separated by x ∪ w in the
+graph with the edges into x and into z(w) removed, where z(w) are the nodes of z that
+are not ancestors of w once the edges into x are removed.
+*/
+func InsertionDeletionOfActions(g *graph.Graph, y, x, z, w []*graph.Node) bool {
+	ancestors := utils.NewSet(graph.Do(g, x).GetAncestors(w)...)
+	incoming := append([]*graph.Node{}, x...)
+	for _, n := range z {
+		if !ancestors.Contains(n) {
+			incoming = append(incoming, n)
+		}
+	}
+	return graph.Do(g, incoming).AreDSeparated(y, z, union(x, w))
+}
+
+func union(a, b []*graph.Node) []*graph.Node {
+	set := utils.NewOrderedSet(a...)
+	set.AddAll(b...)
+	return set.Values()
+}
@@ -0,0 +1,47 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"testing"
+)
+
+func TestDoCalculusFrontDoor(t *testing.T) {
+	// front-door with an instrument: Z --> X --> M --> Y with X <-> Y
+	nodes := newNodes("Z", "X", "M", "Y")
+	z, x, m, y := nodes[0], nodes[1], nodes[2], nodes[3]
+	g := graph.NewGraph(nodes)
+	g.AddDirectedEdge(z, x)
+	g.AddDirectedEdge(x, m)
+	g.AddDirectedEdge(m, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+
+	type rule func(g *graph.Graph, y, x, z, w []*graph.Node) bool
+	set := func(nodes ...*graph.Node) []*graph.Node { return nodes }
+	cases := []struct {
+		name       string
+		rule       rule
+		y, x, z, w []*graph.Node
+		want       bool
+	}{
+		// the steps of the front-door derivation
+		{"P(m | do(x)) = P(m | x)", ActionObservationExchange, set(m), nil, set(x), nil, true},
+		{"P(y | do(m), x) = P(y | m, x)", ActionObservationExchange, set(y), nil, set(m), set(x), true},
+		{"P(x | do(m)) = P(x)", InsertionDeletionOfActions, set(x), nil, set(m), nil, true},
+		{"P(y | do(x), do(m)) = P(y | do(m))", InsertionDeletionOfActions, set(y), set(m), set(x), nil, true},
+		{"P(y | do(x), z) = P(y | do(x))", InsertionDeletionOfObservations, set(y), set(x), set(z), nil, true},
+		// the steps it has to avoid
+		{"P(y | do(x), m) = P(y | do(x))", InsertionDeletionOfObservations, set(y), set(x), set(m), nil, false},
+		{"P(y | do(m), x) = P(y | do(m))", InsertionDeletionOfObservations, set(y), set(m), set(x), nil, false},
+		{"P(y | do(m)) = P(y | m)", ActionObservationExchange, set(y), nil, set(m), nil, false},
+		{"P(y | do(x)) = P(y | x)", ActionObservationExchange, set(y), nil, set(x), nil, false},
+		{"P(y | do(m)) = P(y)", InsertionDeletionOfActions, set(y), nil, set(m), nil, false},
+		// Z is an ancestor of the conditioned X, so do(z) keeps the edge Z --> X and
+		// conditioning on the collider X connects Z and Y
+		{"P(y | do(z), x) = P(y | x)", InsertionDeletionOfActions, set(y), nil, set(z), set(x), false},
+	}
+	for _, c := range cases {
+		if got := c.rule(g, c.y, c.x, c.z, c.w); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
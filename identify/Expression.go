@@ -0,0 +1,136 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"strings"
+)
+
+/*
+Expression
+
+A symbolic probability expression over observed variables, as produced by the
+identification algorithms. It can be printed as plain text or as LaTeX.
+*/
+type Expression interface {
+	ToString() string
+	ToLatex() string
+}
+
+/*
+Probability
+
+The observational probability P(Variables | Given); Given may be empty.
+*/
+type Probability struct {
+	Variables []*graph.Node
+	Given     []*graph.Node
+}
+
+func (p *Probability) ToString() string {
+	return p.format(" | ")
+}
+
+func (p *Probability) ToLatex() string {
+	return p.format(` \mid `)
+}
+
+func (p *Probability) format(bar string) string {
+	s := "P(" + nodeList(p.Variables)
+	if len(p.Given) > 0 {
+		s += bar + nodeList(p.Given)
+	}
+	return s + ")"
+}
+
+/*
+Sum
+
+The marginalization of Term over the variables in Over.
+*/
+type Sum struct {
+	Over []*graph.Node
+	Term Expression
+}
+
+func (s *Sum) ToString() string {
+	return "sum_{" + nodeList(s.Over) + "} " + s.Term.ToString()
+}
+
+func (s *Sum) ToLatex() string {
+	return `\sum_{` + nodeList(s.Over) + "} " + s.Term.ToLatex()
+}
+
+/*
+Product
+
+The product of its factors.
+*/
+type Product struct {
+	Factors []Expression
+}
+
+func (p *Product) ToString() string {
+	var factors []string
+	for _, f := range p.Factors {
+		if _, ok := f.(*Sum); ok {
+			factors = append(factors, "["+f.ToString()+"]")
+		} else {
+			factors = append(factors, f.ToString())
+		}
+	}
+	return strings.Join(factors, " ")
+}
+
+func (p *Product) ToLatex() string {
+	var factors []string
+	for _, f := range p.Factors {
+		if _, ok := f.(*Sum); ok {
+			factors = append(factors, `\left(`+f.ToLatex()+`\right)`)
+		} else {
+			factors = append(factors, f.ToLatex())
+		}
+	}
+	return strings.Join(factors, " ")
+}
+
+/*
+Fraction
+
+The quotient Numerator / Denominator.
+*/
+type Fraction struct {
+	Numerator   Expression
+	Denominator Expression
+}
+
+func (f *Fraction) ToString() string {
+	return "[" + f.Numerator.ToString() + "] / [" + f.Denominator.ToString() + "]"
+}
+
+func (f *Fraction) ToLatex() string {
+	return `\frac{` + f.Numerator.ToLatex() + "}{" + f.Denominator.ToLatex() + "}"
+}
+
+func nodeList(nodes []*graph.Node) string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.GetName()
+	}
+	return strings.Join(names, ", ")
+}
+
+// sumOver marginalizes e over the given variables, leaving it unchanged when there are none.
+func sumOver(over []*graph.Node, e Expression) Expression {
+	if len(over) == 0 {
+		return e
+	}
+	return &Sum{Over: over, Term: e}
+}
+
+// product multiplies the factors, returning a lone factor unchanged.
+func product(factors []Expression) Expression {
+	if len(factors) == 1 {
+		return factors[0]
+	}
+	return &Product{Factors: factors}
+}
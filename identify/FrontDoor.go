@@ -0,0 +1,214 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"GoCausal/utils"
+	"errors"
+	"fmt"
+)
+
+var ErrNoFrontdoorSet = errors.New("no valid front-door set exists")
+
+/*
+frontdoor
+
+Holds the pieces of the front-door criterion (Pearl, 1995) for a pair x, y that do not
+depend on the candidate set: the nodes that have no back-door path from x, which are
+the only nodes a front-door set may contain (Jeong, Tian & Bareinboim, 2022).
+*/
+type frontdoor struct {
+	g *graph.Graph
+	x *graph.Node
+	y *graph.Node
+	// nodes other than x and y that are d-separated from x once the edges out of x are cut
+	candidates *utils.Set[*graph.Node]
+}
+
+func newFrontdoor(g *graph.Graph, x, y *graph.Node) (*frontdoor, error) {
+	if !g.ContainsNode(x) || !g.ContainsNode(y) {
+		return nil, fmt.Errorf("treatment and outcome must be nodes of the graph")
+	}
+	if x == y {
+		return nil, fmt.Errorf("treatment and outcome must differ")
+	}
+	if g.IsPattern() || g.IsPag() {
+		return nil, fmt.Errorf("front-door criterion is only defined for DAGs and ADMGs")
+	}
+	f := &frontdoor{g: g, x: x, y: y, candidates: utils.NewSet[*graph.Node]()}
+	connected := utils.NewSet(graph.RemoveOutgoing(g, []*graph.Node{x}).GetDConnectedNodes([]*graph.Node{x}, nil)...)
+	for _, n := range g.GetNodes() {
+		if n != x && n != y && !connected.Contains(n) {
+			f.candidates.Add(n)
+		}
+	}
+	return f, nil
+}
+
+// intercepts reports whether every directed path from x to y passes through z.
+func (f *frontdoor) intercepts(z *utils.Set[*graph.Node]) bool {
+	visited := utils.NewSet(f.x)
+	q := utils.NewQueue(f.x)
+	for q.Size() > 0 {
+		for _, c := range f.g.GetChildren(q.Pop()) {
+			if c == f.y {
+				return false
+			}
+			if !z.Contains(c) && visited.Add(c) {
+				q.Append(c)
+			}
+		}
+	}
+	return true
+}
+
+// blocked returns the nodes of z from which every back-door path to y is blocked by x.
+func (f *frontdoor) blocked(z *utils.Set[*graph.Node]) *utils.Set[*graph.Node] {
+	cut := graph.RemoveOutgoing(f.g, z.Values())
+	given := []*graph.Node{f.x}
+	kept := utils.NewSet[*graph.Node]()
+	for _, n := range z.Values() {
+		if cut.IsDSeparatedFrom(n, f.y, given) {
+			kept.Add(n)
+		}
+	}
+	return kept
+}
+
+/*
+maximal
+
+Returns the largest subset of allowed that satisfies the second and third front-door
+conditions. A node with an open back-door path to y stays connected when edges are
+added back, so it belongs to no valid subset and can be discarded until none is left;
+every front-door set inside allowed is therefore a subset of the result.
+*/
+func (f *frontdoor) maximal(allowed *utils.Set[*graph.Node]) *utils.Set[*graph.Node] {
+	z := allowed.Intersection(f.candidates)
+	for {
+		kept := f.blocked(z)
+		if kept.Size() == z.Size() {
+			return z
+		}
+		z = kept
+	}
+}
+
+func (f *frontdoor) valid(z *utils.Set[*graph.Node]) bool {
+	return z.IsSubsetOf(f.candidates) && f.intercepts(z) && f.blocked(z).Size() == z.Size()
+}
+
+// find returns a front-door set z with include ⊆ z ⊆ allowed, if one exists.
+func (f *frontdoor) find(include, allowed *utils.Set[*graph.Node]) (*utils.Set[*graph.Node], bool) {
+	z := f.maximal(allowed)
+	if !include.IsSubsetOf(z) || !f.intercepts(z) {
+		return nil, false
+	}
+	return z, true
+}
+
+func (f *frontdoor) sorted(z *utils.Set[*graph.Node]) []*graph.Node {
+	nodes := []*graph.Node{}
+	for _, n := range f.g.GetNodes() {
+		if z.Contains(n) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+/*
+IsValidFrontdoorSet
+
+Returns true if z satisfies the front-door criterion relative to (x, y): z intercepts
+every directed path from x to y, there is no open back-door path from x to z, and x
+blocks every back-door path from z to y. The effect of x on y is then identified by
+the front-door formula even when x and y share latent confounders.
+Works for DAGs and ADMGs.
+*/
+func IsValidFrontdoorSet(g *graph.Graph, x, y *graph.Node, z []*graph.Node) bool {
+	f, err := newFrontdoor(g, x, y)
+	if err != nil {
+		return false
+	}
+	return f.valid(utils.NewSet(z...))
+}
+
+/*
+FindFrontdoorSet
+
+Returns a front-door set for the effect of x on y from which no node can be dropped.
+Returns ErrNoFrontdoorSet when no valid set exists.
+*/
+func FindFrontdoorSet(g *graph.Graph, x, y *graph.Node) ([]*graph.Node, error) {
+	f, err := newFrontdoor(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	z, ok := f.find(utils.NewSet[*graph.Node](), f.candidates)
+	if !ok {
+		return nil, ErrNoFrontdoorSet
+	}
+	// validity is not monotone: dropping one node can make another droppable, so the
+	// pass is repeated until it removes nothing
+	for changed := true; changed; {
+		changed = false
+		for _, n := range f.sorted(z) {
+			reduced := z.Copy()
+			reduced.Remove(n)
+			if f.valid(reduced) {
+				z = reduced
+				changed = true
+			}
+		}
+	}
+	return f.sorted(z), nil
+}
+
+/*
+ListFrontdoorSets
+
+Enumerates every front-door set for the effect of x on y, branching on whether a node
+is in the set and pruning branches that contain no valid set, so consecutive sets are
+found in polynomial time (Jeong, Tian & Bareinboim, 2022).
+Returns ErrNoFrontdoorSet when no valid set exists.
+*/
+func ListFrontdoorSets(g *graph.Graph, x, y *graph.Node) ([][]*graph.Node, error) {
+	f, err := newFrontdoor(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	var sets [][]*graph.Node
+	f.list(utils.NewSet[*graph.Node](), f.candidates, &sets)
+	if len(sets) == 0 {
+		return nil, ErrNoFrontdoorSet
+	}
+	return sets, nil
+}
+
+func (f *frontdoor) list(include, allowed *utils.Set[*graph.Node], sets *[][]*graph.Node) {
+	z, ok := f.find(include, allowed)
+	if !ok {
+		return
+	}
+	// no valid set contains a node outside the maximal one
+	allowed = z
+	var v *graph.Node
+	for _, n := range f.sorted(allowed) {
+		if !include.Contains(n) {
+			v = n
+			break
+		}
+	}
+	if v == nil {
+		if f.valid(include) {
+			*sets = append(*sets, f.sorted(include))
+		}
+		return
+	}
+	withV := include.Copy()
+	withV.Add(v)
+	f.list(withV, allowed, sets)
+	withoutV := allowed.Copy()
+	withoutV.Remove(v)
+	f.list(include, withoutV, sets)
+}
@@ -0,0 +1,113 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// randomAdmg returns a random DAG to which bidirected edges are added between
+// non-adjacent pairs with probability q.
+func randomAdmg(rng *rand.Rand, n int, p, q float64) *graph.Graph {
+	g := randomDag(rng, n, p)
+	nodes := g.GetNodes()
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			if !g.IsAdjacentTo(a, b) && rng.Float64() < q {
+				g.AddEdge(graph.BidirectedEdge(a, b))
+			}
+		}
+	}
+	return g
+}
+
+func TestFrontdoorKnownGraphs(t *testing.T) {
+	nodes := newNodes("X", "M1", "M2", "Y")
+	x, m1, m2, y := nodes[0], nodes[1], nodes[2], nodes[3]
+	g := graph.NewGraph(nodes)
+	g.AddDirectedEdge(x, m1)
+	g.AddDirectedEdge(m1, m2)
+	g.AddDirectedEdge(m2, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+	for _, z := range [][]*graph.Node{{m1}, {m2}, {m1, m2}} {
+		if !IsValidFrontdoorSet(g, x, y, z) {
+			t.Errorf("{%s} is a front-door set", names(z))
+		}
+	}
+	if IsValidFrontdoorSet(g, x, y, nil) {
+		t.Error("the empty set does not intercept x --> m1 --> m2 --> y")
+	}
+	sets, err := ListFrontdoorSets(g, x, y)
+	if err != nil || len(sets) != 3 {
+		t.Errorf("want 3 front-door sets, got %d, %v", len(sets), err)
+	}
+	z, err := FindFrontdoorSet(g, x, y)
+	if err != nil || len(z) != 1 {
+		t.Errorf("want a single mediator, got {%s}, %v", names(z), err)
+	}
+
+	g.AddEdge(graph.BidirectedEdge(x, m1))
+	g.AddEdge(graph.BidirectedEdge(m2, y))
+	if _, err := FindFrontdoorSet(g, x, y); !errors.Is(err, ErrNoFrontdoorSet) {
+		t.Errorf("both mediators are confounded, want ErrNoFrontdoorSet, got %v", err)
+	}
+}
+
+func TestFrontdoorSetsMatchBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	for trial := 0; trial < 200; trial++ {
+		n := 3 + rng.Intn(4)
+		g := randomAdmg(rng, n, 0.3+0.4*rng.Float64(), 0.3)
+		nodes := g.GetNodes()
+		perm := rng.Perm(n)
+		x, y := nodes[perm[0]], nodes[perm[1]]
+		var others []*graph.Node
+		for _, k := range perm[2:] {
+			others = append(others, nodes[k])
+		}
+		want := map[string]bool{}
+		for _, z := range subsetsOf(others) {
+			if IsValidFrontdoorSet(g, x, y, z) {
+				want[setKey(z)] = true
+			}
+		}
+		sets, err := ListFrontdoorSets(g, x, y)
+		if (err == nil) != (len(want) > 0) || len(sets) != len(want) {
+			t.Fatalf("ListFrontdoorSets found %d sets, want %d, in\n%s", len(sets), len(want), g.ToString())
+		}
+		for _, z := range sets {
+			if !want[setKey(z)] {
+				t.Fatalf("ListFrontdoorSets listed the invalid set {%s}", names(z))
+			}
+		}
+		z, err := FindFrontdoorSet(g, x, y)
+		if err != nil {
+			if len(want) > 0 {
+				t.Fatalf("FindFrontdoorSet: %v, but %d sets exist", err, len(want))
+			}
+			continue
+		}
+		if !want[setKey(z)] {
+			t.Fatalf("FindFrontdoorSet returned the invalid set {%s}", names(z))
+		}
+		for i := range z {
+			smaller := append(append([]*graph.Node{}, z[:i]...), z[i+1:]...)
+			if IsValidFrontdoorSet(g, x, y, smaller) {
+				t.Fatalf("%s can be dropped from the front-door set {%s} in\n%s", z[i].GetName(), names(z), g.ToString())
+			}
+		}
+	}
+}
+
+// setKey identifies a set of nodes by its sorted names.
+func setKey(z []*graph.Node) string {
+	labels := make([]string, len(z))
+	for i, n := range z {
+		labels[i] = n.GetName()
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
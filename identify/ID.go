@@ -0,0 +1,213 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"GoCausal/utils"
+	"errors"
+	"fmt"
+)
+
+var ErrNotIdentifiable = errors.New("causal effect is not identifiable")
+
+/*
+HedgeError
+
+Reports that P(y | do(x)) is not identifiable, witnessed by a hedge: two c-forests F and
+F' ⊂ F that share their root set within the ancestors of y, where F contains nodes of x
+and F' does not (Shpitser & Pearl, 2006).
+errors.Is(err, ErrNotIdentifiable) holds for it.
+*/
+type HedgeError struct {
+	F      []*graph.Node
+	FPrime []*graph.Node
+}
+
+func (e *HedgeError) Error() string {
+	return fmt.Sprintf("%s: hedge formed by {%s} and {%s}", ErrNotIdentifiable, nodeList(e.F), nodeList(e.FPrime))
+}
+
+func (e *HedgeError) Unwrap() error {
+	return ErrNotIdentifiable
+}
+
+/*
+distribution
+
+The distribution the ID algorithm currently works with, over the variables vars.
+A nil expr stands for the observational marginal P(vars), whose conditionals can be
+written down directly; otherwise expr is a product of factors built by the algorithm
+and conditionals are ratios of its marginals.
+*/
+type distribution struct {
+	expr Expression
+	vars *utils.Set[*graph.Node]
+}
+
+type identification struct {
+	// position of every node in a causal ordering of the input graph
+	rank map[*graph.Node]int
+}
+
+func (id *identification) sorted(nodes *utils.Set[*graph.Node]) []*graph.Node {
+	result := make([]*graph.Node, nodes.Size())
+	i := 0
+	for _, n := range nodes.Values() {
+		result[i] = n
+		i++
+	}
+	for i := 1; i < len(result); i++ {
+		for j := i; j > 0 && id.rank[result[j]] < id.rank[result[j-1]]; j-- {
+			result[j], result[j-1] = result[j-1], result[j]
+		}
+	}
+	return result
+}
+
+func (id *identification) marginal(d distribution, keep *utils.Set[*graph.Node]) distribution {
+	if keep.Size() == d.vars.Size() {
+		return d
+	}
+	if d.expr == nil {
+		return distribution{vars: keep}
+	}
+	return distribution{expr: sumOver(id.sorted(d.vars.Difference(keep)), d.expr), vars: keep}
+}
+
+func (id *identification) expression(d distribution) Expression {
+	if d.expr == nil {
+		return &Probability{Variables: id.sorted(d.vars)}
+	}
+	return d.expr
+}
+
+// conditional returns the expression for P(v | given) under d.
+func (id *identification) conditional(d distribution, v *graph.Node, given *utils.Set[*graph.Node]) Expression {
+	if d.expr == nil {
+		return &Probability{Variables: []*graph.Node{v}, Given: id.sorted(given)}
+	}
+	joint := given.Copy()
+	joint.Add(v)
+	numerator := id.expression(id.marginal(d, joint))
+	if given.IsEmpty() {
+		return numerator
+	}
+	return &Fraction{Numerator: numerator, Denominator: id.expression(id.marginal(d, given))}
+}
+
+// predecessors returns the nodes of g that precede v in the causal ordering.
+func (id *identification) predecessors(g *graph.Graph, v *graph.Node) *utils.Set[*graph.Node] {
+	before := utils.NewSet[*graph.Node]()
+	for _, n := range g.GetNodes() {
+		if id.rank[n] < id.rank[v] {
+			before.Add(n)
+		}
+	}
+	return before
+}
+
+// factorize returns the product over v in s of P(v | predecessors of v in g) under d.
+func (id *identification) factorize(d distribution, g *graph.Graph, s *utils.Set[*graph.Node]) Expression {
+	var factors []Expression
+	for _, v := range id.sorted(s) {
+		factors = append(factors, id.conditional(d, v, id.predecessors(g, v)))
+	}
+	return product(factors)
+}
+
+// identify runs the ID algorithm on the query P_x(y) with distribution d over graph g.
+func (id *identification) identify(y, x *utils.Set[*graph.Node], d distribution, g *graph.Graph) (Expression, error) {
+	v := utils.NewSet(g.GetNodes()...)
+
+	// line 1: nothing to intervene on
+	if x.IsEmpty() {
+		return id.expression(id.marginal(d, y)), nil
+	}
+
+	// line 2: non-ancestors of y are irrelevant
+	ancestors := utils.NewSet(g.GetAncestors(id.sorted(y))...)
+	if ancestors.Size() < v.Size() {
+		return id.identify(y, x.Intersection(ancestors), id.marginal(d, ancestors), g.Subgraph(id.sorted(ancestors)))
+	}
+
+	// line 3: intervening on nodes that cannot affect y changes nothing
+	intervened := graph.Do(g, id.sorted(x))
+	w := v.Difference(x).Difference(utils.NewSet(intervened.GetAncestors(id.sorted(y))...))
+	if !w.IsEmpty() {
+		return id.identify(y, x.Union(w), d, g)
+	}
+
+	// line 4: factorize over the c-components of G \ x
+	rest := v.Difference(x)
+	components := graph.GetCComponents(g.Subgraph(id.sorted(rest)))
+	if len(components) > 1 {
+		var factors []Expression
+		for _, c := range components {
+			s := utils.NewSet(c...)
+			e, err := id.identify(s, v.Difference(s), d, g)
+			if err != nil {
+				return nil, err
+			}
+			factors = append(factors, e)
+		}
+		return sumOver(id.sorted(rest.Difference(y)), product(factors)), nil
+	}
+
+	s := utils.NewSet(components[0]...)
+	districts := graph.GetCComponents(g)
+
+	// line 5: g is a single c-component containing s, a hedge
+	if len(districts) == 1 {
+		return nil, &HedgeError{F: id.sorted(v), FPrime: id.sorted(s)}
+	}
+
+	for _, c := range districts {
+		district := utils.NewSet(c...)
+		// line 6: s is a c-component of g
+		if district.Size() == s.Size() && s.IsSubsetOf(district) {
+			return sumOver(id.sorted(s.Difference(y)), id.factorize(d, g, s)), nil
+		}
+		// line 7: s lies inside a larger c-component of g
+		if s.IsSubsetOf(district) {
+			next := distribution{expr: id.factorize(d, g, district), vars: district}
+			return id.identify(y, x.Intersection(district), next, g.Subgraph(id.sorted(district)))
+		}
+	}
+	return nil, fmt.Errorf("c-component of G \\ X is not contained in a c-component of G")
+}
+
+/*
+IdentifyEffect
+
+Runs the ID algorithm of Shpitser & Pearl (2006) on the interventional distribution
+P(y | do(x)) in the semi-Markovian model g, where latent confounding is encoded by
+bidirected (ARROW-ARROW) edges. Returns an expression for the effect in terms of the
+observational distribution of the nodes of g, or a *HedgeError when the effect is not
+identifiable.
+*/
+func IdentifyEffect(g *graph.Graph, y, x []*graph.Node) (Expression, error) {
+	if g.IsPattern() || g.IsPag() {
+		return nil, fmt.Errorf("the ID algorithm is only defined for DAGs and ADMGs")
+	}
+	if len(y) == 0 {
+		return nil, fmt.Errorf("outcome set must not be empty")
+	}
+	order := graph.GetCausalOrdering(g)
+	if order == nil {
+		return nil, fmt.Errorf("graph contains a directed cycle")
+	}
+	outcomes := utils.NewSet(y...)
+	treatments := utils.NewSet(x...)
+	for _, n := range append(append([]*graph.Node{}, y...), x...) {
+		if !g.ContainsNode(n) {
+			return nil, fmt.Errorf("node %s is not in the graph", n.GetName())
+		}
+	}
+	if !outcomes.Intersection(treatments).IsEmpty() {
+		return nil, fmt.Errorf("treatment and outcome sets must be disjoint")
+	}
+	id := &identification{rank: map[*graph.Node]int{}}
+	for i, n := range order {
+		id.rank[n] = i
+	}
+	return id.identify(outcomes, treatments, distribution{vars: utils.NewSet(g.GetNodes()...)}, g)
+}
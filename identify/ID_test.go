@@ -0,0 +1,57 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"errors"
+	"testing"
+)
+
+func TestIdentifyEffectKnownGraphs(t *testing.T) {
+	// front-door: X --> M --> Y with X <-> Y
+	nodes := newNodes("X", "M", "Y")
+	x, m, y := nodes[0], nodes[1], nodes[2]
+	g := graph.NewGraph(nodes)
+	g.AddDirectedEdge(x, m)
+	g.AddDirectedEdge(m, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+	e, err := IdentifyEffect(g, []*graph.Node{y}, []*graph.Node{x})
+	if err != nil {
+		t.Fatalf("front-door effect is identifiable: %v", err)
+	}
+	if want := "sum_{M} P(M | X) [sum_{X} P(X) P(Y | X, M)]"; e.ToString() != want {
+		t.Errorf("front-door: got %s, want %s", e.ToString(), want)
+	}
+
+	// napkin: W --> Z --> X --> Y with W <-> X and W <-> Y
+	nodes = newNodes("W", "Z", "X", "Y")
+	w, z, x, y := nodes[0], nodes[1], nodes[2], nodes[3]
+	g = graph.NewGraph(nodes)
+	g.AddDirectedEdge(w, z)
+	g.AddDirectedEdge(z, x)
+	g.AddDirectedEdge(x, y)
+	g.AddEdge(graph.BidirectedEdge(w, x))
+	g.AddEdge(graph.BidirectedEdge(w, y))
+	e, err = IdentifyEffect(g, []*graph.Node{y}, []*graph.Node{x})
+	if err != nil {
+		t.Fatalf("napkin effect is identifiable: %v", err)
+	}
+	want := "[sum_{W} P(W) P(X | W, Z) P(Y | W, Z, X)] / [sum_{Y} sum_{W} P(W) P(X | W, Z) P(Y | W, Z, X)]"
+	if e.ToString() != want {
+		t.Errorf("napkin: got %s, want %s", e.ToString(), want)
+	}
+
+	// bow arc: X --> Y with X <-> Y
+	nodes = newNodes("X", "Y")
+	x, y = nodes[0], nodes[1]
+	g = graph.NewGraph(nodes)
+	g.AddDirectedEdge(x, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+	_, err = IdentifyEffect(g, []*graph.Node{y}, []*graph.Node{x})
+	var hedge *HedgeError
+	if !errors.As(err, &hedge) || !errors.Is(err, ErrNotIdentifiable) {
+		t.Fatalf("bow arc: want a *HedgeError, got %v", err)
+	}
+	if names(hedge.F) != "X, Y" || names(hedge.FPrime) != "Y" {
+		t.Errorf("bow arc: got hedge {%s}, {%s}", names(hedge.F), names(hedge.FPrime))
+	}
+}
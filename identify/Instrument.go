@@ -0,0 +1,90 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"errors"
+	"fmt"
+)
+
+var ErrNoInstrument = errors.New("no conditional instrument exists")
+
+/*
+Instrument
+
+A conditional instrumental variable for the effect of x on y: Instrument is d-connected
+to x given Conditioning, and d-separated from y given Conditioning once the first edges
+of the causal paths from x are removed (Brito & Pearl, 2002).
+*/
+type Instrument struct {
+	Instrument   *graph.Node
+	Conditioning []*graph.Node
+}
+
+/*
+IsConditionalInstrument
+
+Returns true if z is an instrument for the effect of x on y conditional on w: w contains
+neither y nor a forbidden node (x or a descendant of a node on a causal path), z is not
+forbidden, z is d-connected to x given w, and z is d-separated from y given w in the
+proper back-door graph. Works for DAGs and ADMGs.
+*/
+func IsConditionalInstrument(g *graph.Graph, x, y, z *graph.Node, w []*graph.Node) bool {
+	a, err := newInstrumentSearch(g, x, y)
+	if err != nil {
+		return false
+	}
+	if z == y || a.forbidden.Contains(z) {
+		return false
+	}
+	for _, n := range w {
+		if n == y || n == z || a.forbidden.Contains(n) {
+			return false
+		}
+	}
+	return g.IsDConnectedTo(z, x, w) && a.backdoor.IsDSeparatedFrom(z, y, w)
+}
+
+/*
+FindInstruments
+
+Returns every node that is an ancestral instrument for the effect of x on y, i.e. a
+conditional instrument whose conditioning set consists of ancestors of the instrument
+and y. If some such set exists, then the separator of the instrument and y nearest to y
+in the proper back-door graph works too, and that is the set returned. An ancestral
+instrument exists whenever any conditional instrument does
+(van der Zander, Textor & Liśkiewicz, 2015).
+Returns ErrNoInstrument when no node qualifies.
+*/
+func FindInstruments(g *graph.Graph, x, y *graph.Node) ([]Instrument, error) {
+	a, err := newInstrumentSearch(g, x, y)
+	if err != nil {
+		return nil, err
+	}
+	var instruments []Instrument
+	for _, z := range g.GetNodes() {
+		if z == y || a.forbidden.Contains(z) {
+			continue
+		}
+		restrict := []*graph.Node{}
+		for _, n := range g.GetNodes() {
+			if n != y && n != z && !a.forbidden.Contains(n) {
+				restrict = append(restrict, n)
+			}
+		}
+		w, ok := graph.FindNearestSeparator(a.backdoor, y, z, nil, restrict)
+		if ok && g.IsDConnectedTo(z, x, w) {
+			instruments = append(instruments, Instrument{Instrument: z, Conditioning: w})
+		}
+	}
+	if len(instruments) == 0 {
+		return nil, ErrNoInstrument
+	}
+	return instruments, nil
+}
+
+func newInstrumentSearch(g *graph.Graph, x, y *graph.Node) (*adjustment, error) {
+	if g.IsPattern() || g.IsPag() {
+		return nil, fmt.Errorf("instrumental variables are only defined for DAGs and ADMGs")
+	}
+	return newAdjustment(g, x, y)
+}
@@ -0,0 +1,44 @@
+package identify
+
+import (
+	"GoCausal/graph"
+	"errors"
+	"testing"
+)
+
+func TestInstrumentKnownGraphs(t *testing.T) {
+	nodes := newNodes("W", "Z", "X", "Y")
+	w, z, x, y := nodes[0], nodes[1], nodes[2], nodes[3]
+	g := graph.NewGraph(nodes)
+	g.AddDirectedEdge(z, x)
+	g.AddDirectedEdge(x, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+	if !IsConditionalInstrument(g, x, y, z, nil) {
+		t.Error("z is an unconditional instrument")
+	}
+	if IsConditionalInstrument(g, x, y, z, []*graph.Node{y}) {
+		t.Error("the outcome cannot be conditioned on")
+	}
+
+	// w confounds z and y, so z is an instrument only given w
+	g.AddDirectedEdge(w, z)
+	g.AddDirectedEdge(w, y)
+	if IsConditionalInstrument(g, x, y, z, nil) {
+		t.Error("z --> ... w --> y is open without conditioning on w")
+	}
+	if !IsConditionalInstrument(g, x, y, z, []*graph.Node{w}) {
+		t.Error("z is an instrument given w")
+	}
+	instruments, err := FindInstruments(g, x, y)
+	if err != nil || len(instruments) != 1 || instruments[0].Instrument != z || names(instruments[0].Conditioning) != "W" {
+		t.Errorf("want z given {W}, got %v, %v", instruments, err)
+	}
+
+	g = graph.NewGraph(nodes)
+	g.AddDirectedEdge(x, y)
+	g.AddEdge(graph.BidirectedEdge(x, y))
+	g.AddDirectedEdge(z, y)
+	if _, err := FindInstruments(g, x, y); !errors.Is(err, ErrNoInstrument) {
+		t.Errorf("want ErrNoInstrument, got %v", err)
+	}
+}
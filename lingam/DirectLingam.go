@@ -0,0 +1,172 @@
+package lingam
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"math"
+	"sort"
+)
+
+type Measure int
+
+const (
+	// the pairwise likelihood ratio of Hyvärinen & Smith (2013), comparing maximum
+	// entropy approximations of the differential entropies of variables and residuals
+	PairwiseLikelihood Measure = iota
+	// the Hilbert-Schmidt independence criterion between a variable and the residuals
+	// of the others regressed on it, with Gaussian kernels; quadratic in the sample size
+	KernelIndependence
+)
+
+/*
+DirectLingam
+
+Runs DirectLiNGAM (Shimizu et al., 2011) on data with one row per sample and one column
+per node. The causal ordering is built one node at a time: the next node is the one
+most independent of the residuals of the remaining nodes regressed on it, according to
+measure, and it is then regressed out of the remaining nodes. Edge coefficients are
+fitted along the ordering, keeping the edges significant at level alpha.
+*/
+func DirectLingam(nodes []*graph.Node, data *mat.Dense, measure Measure, alpha float64) (*Result, error) {
+	if err := checkData(nodes, data); err != nil {
+		return nil, err
+	}
+	_, c := data.Dims()
+	columns := make([][]float64, c)
+	for j := range columns {
+		columns[j] = standardize(mat.Col(nil, j, data))
+	}
+	remaining := make([]int, c)
+	for j := range remaining {
+		remaining[j] = j
+	}
+
+	var order []int
+	for len(remaining) > 1 {
+		best, bestScore := -1, math.Inf(1)
+		for _, i := range remaining {
+			score := 0.0
+			for _, j := range remaining {
+				if i == j {
+					continue
+				}
+				switch measure {
+				case PairwiseLikelihood:
+					// a negative ratio is evidence for j -> i
+					score += math.Pow(math.Min(0, likelihoodRatio(columns[i], columns[j])), 2)
+				case KernelIndependence:
+					score += hsic(columns[i], standardize(residual(columns[j], columns[i])))
+				}
+			}
+			if score < bestScore {
+				best, bestScore = i, score
+			}
+		}
+		order = append(order, best)
+		var rest []int
+		for _, j := range remaining {
+			if j != best {
+				columns[j] = standardize(residual(columns[j], columns[best]))
+				rest = append(rest, j)
+			}
+		}
+		remaining = rest
+	}
+	order = append(order, remaining...)
+	return fitOrder(nodes, data, order, alpha)
+}
+
+func standardize(x []float64) []float64 {
+	mean, std := stat.MeanStdDev(x, nil)
+	result := make([]float64, len(x))
+	for k, v := range x {
+		if std > 0 {
+			result[k] = (v - mean) / std
+		}
+	}
+	return result
+}
+
+// residual returns x minus its least squares projection on y.
+func residual(x, y []float64) []float64 {
+	beta := stat.Covariance(x, y, nil) / stat.Variance(y, nil)
+	result := make([]float64, len(x))
+	for k := range x {
+		result[k] = x[k] - beta*y[k]
+	}
+	return result
+}
+
+// entropy approximates the differential entropy of a standardized variable (Hyvärinen, 1998).
+func entropy(u []float64) float64 {
+	const k1, k2, gamma = 79.047, 7.4129, 0.37457
+	logCosh, gauss := 0.0, 0.0
+	for _, v := range u {
+		logCosh += math.Log(math.Cosh(v))
+		gauss += v * math.Exp(-v*v/2)
+	}
+	logCosh /= float64(len(u))
+	gauss /= float64(len(u))
+	return (1+math.Log(2*math.Pi))/2 - k1*math.Pow(logCosh-gamma, 2) - k2*gauss*gauss
+}
+
+// likelihoodRatio is positive when the standardized xi more likely causes xj than the
+// reverse.
+func likelihoodRatio(xi, xj []float64) float64 {
+	return entropy(xj) + entropy(standardize(residual(xi, xj))) -
+		entropy(xi) - entropy(standardize(residual(xj, xi)))
+}
+
+/*
+hsic
+
+Returns the biased empirical Hilbert-Schmidt independence criterion of two samples with
+Gaussian kernels whose widths are set by the median heuristic.
+*/
+func hsic(x, y []float64) float64 {
+	n := len(x)
+	k := centeredGram(x)
+	l := centeredGram(y)
+	total := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			total += k[i*n+j] * l[i*n+j]
+		}
+	}
+	return total / float64(n*n)
+}
+
+func centeredGram(x []float64) []float64 {
+	n := len(x)
+	distances := make([]float64, 0, n*(n-1)/2)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			distances = append(distances, (x[i]-x[j])*(x[i]-x[j]))
+		}
+	}
+	sort.Float64s(distances)
+	width := 1.0
+	if len(distances) > 0 && distances[len(distances)/2] > 0 {
+		width = distances[len(distances)/2]
+	}
+
+	gram := make([]float64, n*n)
+	rowMeans := make([]float64, n)
+	mean := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := math.Exp(-(x[i] - x[j]) * (x[i] - x[j]) / (2 * width))
+			gram[i*n+j] = v
+			rowMeans[i] += v / float64(n)
+		}
+		mean += rowMeans[i] / float64(n)
+	}
+	// the Gram matrix is symmetric, so column means equal row means
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			gram[i*n+j] += mean - rowMeans[i] - rowMeans[j]
+		}
+	}
+	return gram
+}
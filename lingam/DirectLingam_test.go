@@ -0,0 +1,76 @@
+package lingam
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+// simulateLingam returns a random DAG over n nodes and samples from a linear model on it
+// with uniform, hence non-Gaussian, errors.
+func simulateLingam(rng *rand.Rand, n, samples int) (*graph.Graph, *mat.Dense) {
+	nodes := make([]*graph.Node, n)
+	for i := range nodes {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(fmt.Sprintf("X%d", i+1))
+	}
+	g := graph.NewGraph(nodes)
+	order := rng.Perm(n)
+	weights := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < 0.5 {
+				g.AddDirectedEdge(nodes[order[i]], nodes[order[j]])
+				w := 0.5 + rng.Float64()
+				if rng.Intn(2) == 0 {
+					w = -w
+				}
+				weights.Set(order[i], order[j], w)
+			}
+		}
+	}
+	data := mat.NewDense(samples, n, nil)
+	for s := 0; s < samples; s++ {
+		for _, j := range order {
+			value := 2*rng.Float64() - 1
+			for i := 0; i < n; i++ {
+				value += weights.At(i, j) * data.At(s, i)
+			}
+			data.Set(s, j, value)
+		}
+	}
+	return g, data
+}
+
+func TestDirectLingamRecoversCausalOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(38))
+	for _, c := range []struct {
+		measure Measure
+		nodes   int
+		samples int
+	}{
+		{PairwiseLikelihood, 5, 5000},
+		{PairwiseLikelihood, 5, 5000},
+		{KernelIndependence, 4, 600},
+	} {
+		truth, data := simulateLingam(rng, c.nodes, c.samples)
+		result, err := DirectLingam(truth.GetNodes(), data, c.measure, 0.01)
+		if err != nil {
+			t.Fatal(err)
+		}
+		position := map[*graph.Node]int{}
+		for i, n := range result.CausalOrder {
+			position[n] = i
+		}
+		for _, e := range truth.GetGraphEdges() {
+			if position[e.GetNode1()] > position[e.GetNode2()] {
+				t.Errorf("measure %d: %s is ordered after its child %s in\n%s", c.measure, e.GetNode1().GetName(), e.GetNode2().GetName(), truth.ToString())
+			}
+			if !result.Graph.IsDirectedFromTo(e.GetNode1(), e.GetNode2()) {
+				t.Errorf("measure %d: edge %s is missing", c.measure, e.ToString())
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package lingam
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"math"
+	"math/rand"
+)
+
+/*
+FastICA
+
+Estimates the unmixing matrix W of data with one row per sample and one column per
+signal, such that the columns of (data - mean) Wᵀ are as independent as possible.
+The data is whitened and the rotation found by the symmetric fixed-point iteration of
+Hyvärinen (1999) with the log cosh contrast, started from a random orthogonal matrix.
+Returns an error if the covariance is singular or the iteration does not converge
+within maxIter steps.
+*/
+func FastICA(data *mat.Dense, maxIter int, tol float64, rng *rand.Rand) (*mat.Dense, error) {
+	n, p := data.Dims()
+	centered := mat.NewDense(n, p, nil)
+	for j := 0; j < p; j++ {
+		col := mat.Col(nil, j, data)
+		mean := stat.Mean(col, nil)
+		for i, v := range col {
+			centered.Set(i, j, v-mean)
+		}
+	}
+
+	// whitening: z = K x with K = Λ^(-1/2) Uᵀ for the covariance U Λ Uᵀ
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, centered, nil)
+	var eigen mat.EigenSym
+	if !eigen.Factorize(&cov, true) {
+		return nil, fmt.Errorf("eigendecomposition of the covariance failed")
+	}
+	values := eigen.Values(nil)
+	var vectors mat.Dense
+	eigen.VectorsTo(&vectors)
+	whitening := mat.NewDense(p, p, nil)
+	for k, v := range values {
+		if v <= 1e-12 {
+			return nil, fmt.Errorf("covariance of the data is singular")
+		}
+		for j := 0; j < p; j++ {
+			whitening.Set(k, j, vectors.At(j, k)/math.Sqrt(v))
+		}
+	}
+	var z mat.Dense
+	z.Mul(whitening, centered.T())
+
+	w := mat.NewDense(p, p, nil)
+	for i := 0; i < p; i++ {
+		for j := 0; j < p; j++ {
+			w.Set(i, j, rng.NormFloat64())
+		}
+	}
+	w = decorrelate(w)
+	converged := false
+	for iter := 0; iter < maxIter && !converged; iter++ {
+		var wz mat.Dense
+		wz.Mul(w, &z)
+		g := mat.NewDense(p, n, nil)
+		derivative := make([]float64, p)
+		for i := 0; i < p; i++ {
+			for k := 0; k < n; k++ {
+				t := math.Tanh(wz.At(i, k))
+				g.Set(i, k, t)
+				derivative[i] += (1 - t*t) / float64(n)
+			}
+		}
+		var next mat.Dense
+		next.Mul(g, z.T())
+		next.Scale(1/float64(n), &next)
+		for i := 0; i < p; i++ {
+			for j := 0; j < p; j++ {
+				next.Set(i, j, next.At(i, j)-derivative[i]*w.At(i, j))
+			}
+		}
+		updated := decorrelate(&next)
+
+		// converged when every row keeps its direction
+		change := 0.0
+		for i := 0; i < p; i++ {
+			dot := mat.Dot(updated.RowView(i), w.RowView(i))
+			change = math.Max(change, math.Abs(math.Abs(dot)-1))
+		}
+		w = updated
+		converged = change < tol
+	}
+	if !converged {
+		return nil, fmt.Errorf("FastICA did not converge in %d iterations", maxIter)
+	}
+	var unmixing mat.Dense
+	unmixing.Mul(w, whitening)
+	return &unmixing, nil
+}
+
+// decorrelate returns (W Wᵀ)^(-1/2) W, the closest orthogonal matrix to W.
+func decorrelate(w *mat.Dense) *mat.Dense {
+	p, _ := w.Dims()
+	var wwt mat.Dense
+	wwt.Mul(w, w.T())
+	var eigen mat.EigenSym
+	eigen.Factorize(mat.NewSymDense(p, wwt.RawMatrix().Data), true)
+	values := eigen.Values(nil)
+	var vectors mat.Dense
+	eigen.VectorsTo(&vectors)
+	var scaled mat.Dense
+	scaled.CloneFrom(&vectors)
+	for k, v := range values {
+		for i := 0; i < p; i++ {
+			scaled.Set(i, k, scaled.At(i, k)/math.Sqrt(v))
+		}
+	}
+	var root, result mat.Dense
+	root.Mul(&scaled, vectors.T())
+	result.Mul(&root, w)
+	return &result
+}
@@ -0,0 +1,175 @@
+package lingam
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+/*
+IcaLingam
+
+Runs ICA-LiNGAM (Shimizu et al., 2006) on data with one row per sample and one column
+per node. The unmixing matrix W found by FastICA is permuted so that its diagonal has no
+small entries (minimizing Σ 1/|W_ii| as an assignment problem) and scaled to a unit
+diagonal, giving B = I - W. A causal ordering is read off B after setting its smallest
+entries to zero until it can be permuted to strictly lower triangular form, and the edge
+coefficients are fitted along the ordering, keeping the edges significant at level alpha.
+*/
+func IcaLingam(nodes []*graph.Node, data *mat.Dense, alpha float64, rng *rand.Rand) (*Result, error) {
+	if err := checkData(nodes, data); err != nil {
+		return nil, err
+	}
+	unmixing, err := FastICA(data, 1000, 1e-6, rng)
+	if err != nil {
+		return nil, err
+	}
+	p, _ := unmixing.Dims()
+	cost := make([][]float64, p)
+	for i := range cost {
+		cost[i] = make([]float64, p)
+		for j := range cost[i] {
+			cost[i][j] = 1 / math.Max(math.Abs(unmixing.At(i, j)), 1e-12)
+		}
+	}
+	// component i of the ICA estimates the error term of variable assignment[i]
+	assignment := assign(cost)
+	b := mat.NewDense(p, p, nil)
+	for i, j := range assignment {
+		d := unmixing.At(i, j)
+		for k := 0; k < p; k++ {
+			b.Set(j, k, -unmixing.At(i, k)/d)
+		}
+		b.Set(j, j, 0)
+	}
+	order := lowerTriangularOrder(b)
+	if order == nil {
+		return nil, fmt.Errorf("no causal ordering found")
+	}
+	return fitOrder(nodes, data, order, alpha)
+}
+
+/*
+lowerTriangularOrder
+
+Zeroes the p(p+1)/2 smallest entries of b, then the next smallest one at a time, until
+the rows and columns of b can be permuted simultaneously to a strictly lower triangular
+matrix, and returns that permutation, in which b[i][j] != 0 only when j comes before i.
+*/
+func lowerTriangularOrder(b *mat.Dense) []int {
+	p, _ := b.Dims()
+	m := mat.DenseCopyOf(b)
+	positions := make([][2]int, 0, p*p)
+	for i := 0; i < p; i++ {
+		for j := 0; j < p; j++ {
+			positions = append(positions, [2]int{i, j})
+		}
+	}
+	sort.SliceStable(positions, func(a, c int) bool {
+		return math.Abs(m.At(positions[a][0], positions[a][1])) < math.Abs(m.At(positions[c][0], positions[c][1]))
+	})
+	initial := p * (p + 1) / 2
+	for _, pos := range positions[:initial] {
+		m.Set(pos[0], pos[1], 0)
+	}
+	for _, pos := range positions[initial:] {
+		if order := triangularOrder(m); order != nil {
+			return order
+		}
+		m.Set(pos[0], pos[1], 0)
+	}
+	return triangularOrder(m)
+}
+
+// triangularOrder repeatedly takes a remaining row with no nonzero entry in the remaining
+// columns, returning nil if there is none.
+func triangularOrder(m *mat.Dense) []int {
+	p, _ := m.Dims()
+	placed := make([]bool, p)
+	var order []int
+	for len(order) < p {
+		next := -1
+		for i := 0; i < p && next < 0; i++ {
+			if placed[i] {
+				continue
+			}
+			empty := true
+			for j := 0; j < p; j++ {
+				if !placed[j] && m.At(i, j) != 0 {
+					empty = false
+					break
+				}
+			}
+			if empty {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		placed[next] = true
+		order = append(order, next)
+	}
+	return order
+}
+
+/*
+assign
+
+Solves the square assignment problem with the Hungarian algorithm, returning for every
+row the column assigned to it so that the total cost is minimal.
+*/
+func assign(cost [][]float64) []int {
+	n := len(cost)
+	// potentials and matching use 1-based indices, 0 being a virtual column
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	match := make([]int, n+1)
+	way := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		match[0] = i
+		j0 := 0
+		minimum := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minimum {
+			minimum[j] = math.Inf(1)
+		}
+		for match[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := match[j0], math.Inf(1), 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if c := cost[i0-1][j-1] - u[i0] - v[j]; c < minimum[j] {
+					minimum[j], way[j] = c, j0
+				}
+				if minimum[j] < delta {
+					delta, j1 = minimum[j], j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[match[j]] += delta
+					v[j] -= delta
+				} else {
+					minimum[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			match[j0] = match[j1]
+			j0 = j1
+		}
+	}
+	assignment := make([]int, n)
+	for j := 1; j <= n; j++ {
+		assignment[match[j]-1] = j - 1
+	}
+	return assignment
+}
@@ -0,0 +1,143 @@
+package lingam
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIcaLingamRecoversCausalOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(38))
+	for trial := 0; trial < 4; trial++ {
+		truth, data := simulateLingam(rng, 4, 5000)
+		result, err := IcaLingam(truth.GetNodes(), data, 0.01, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		position := map[*graph.Node]int{}
+		for i, n := range result.CausalOrder {
+			position[n] = i
+		}
+		for _, e := range truth.GetGraphEdges() {
+			if position[e.GetNode1()] > position[e.GetNode2()] {
+				t.Errorf("trial %d: %s is ordered after its child %s in\n%s", trial, e.GetNode1().GetName(), e.GetNode2().GetName(), truth.ToString())
+			}
+			if !result.Graph.IsDirectedFromTo(e.GetNode1(), e.GetNode2()) {
+				t.Errorf("trial %d: edge %s is missing", trial, e.ToString())
+			}
+		}
+	}
+}
+
+func TestFastICAUnmixesUniformSources(t *testing.T) {
+	rng := rand.New(rand.NewSource(38))
+	n := 5000
+	sources := mat.NewDense(n, 3, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < 3; j++ {
+			sources.Set(i, j, 2*rng.Float64()-1)
+		}
+	}
+	mixing := mat.NewDense(3, 3, []float64{1, 0.5, -0.3, 0.2, 1, 0.8, -0.6, 0.4, 1})
+	var data mat.Dense
+	data.Mul(sources, mixing.T())
+	unmixing, err := FastICA(&data, 1000, 1e-6, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// W A must be a scaled permutation: one dominant entry in every row
+	var product mat.Dense
+	product.Mul(unmixing, mixing)
+	for i := 0; i < 3; i++ {
+		row := product.RawRowView(i)
+		largest, total := 0.0, 0.0
+		for _, v := range row {
+			largest = math.Max(largest, math.Abs(v))
+			total += math.Abs(v)
+		}
+		if largest < 0.95*total {
+			t.Errorf("row %d of W A is not a scaled unit vector: %v", i, row)
+		}
+	}
+}
+
+func TestAssignMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(38))
+	for trial := 0; trial < 50; trial++ {
+		n := 1 + rng.Intn(5)
+		cost := make([][]float64, n)
+		for i := range cost {
+			cost[i] = make([]float64, n)
+			for j := range cost[i] {
+				cost[i][j] = rng.Float64()
+			}
+		}
+		total := func(assignment []int) float64 {
+			sum := 0.0
+			for i, j := range assignment {
+				sum += cost[i][j]
+			}
+			return sum
+		}
+		best := math.Inf(1)
+		var permute func(k int, perm []int)
+		permute = func(k int, perm []int) {
+			if k == n {
+				best = math.Min(best, total(perm))
+				return
+			}
+			for i := k; i < n; i++ {
+				perm[k], perm[i] = perm[i], perm[k]
+				permute(k+1, perm)
+				perm[k], perm[i] = perm[i], perm[k]
+			}
+		}
+		perm := make([]int, n)
+		for i := range perm {
+			perm[i] = i
+		}
+		permute(0, perm)
+
+		assignment := assign(cost)
+		used := make([]bool, n)
+		for _, j := range assignment {
+			if used[j] {
+				t.Fatalf("trial %d: column %d is assigned twice in %v", trial, j, assignment)
+			}
+			used[j] = true
+		}
+		if math.Abs(total(assignment)-best) > 1e-12 {
+			t.Fatalf("trial %d: assignment costs %.6f, the optimum is %.6f", trial, total(assignment), best)
+		}
+	}
+}
+
+func TestLowerTriangularOrderPrunesSmallEntries(t *testing.T) {
+	rng := rand.New(rand.NewSource(38))
+	for trial := 0; trial < 20; trial++ {
+		p := 2 + rng.Intn(5)
+		order := rng.Perm(p)
+		b := mat.NewDense(p, p, nil)
+		for i := 0; i < p; i++ {
+			for j := 0; j < p; j++ {
+				// a strictly lower triangular matrix in the hidden order, plus estimation noise
+				value := 0.01 * rng.NormFloat64()
+				if j < i {
+					value = 0.5 + rng.Float64()
+				}
+				b.Set(order[i], order[j], value)
+			}
+		}
+		got := lowerTriangularOrder(b)
+		if len(got) != p {
+			t.Fatalf("trial %d: got order %v", trial, got)
+		}
+		for i := range got {
+			if got[i] != order[i] {
+				t.Fatalf("trial %d: got order %v, want %v", trial, got, order)
+			}
+		}
+	}
+}
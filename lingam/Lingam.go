@@ -0,0 +1,135 @@
+package lingam
+
+import (
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+	"math/rand"
+)
+
+/*
+Result
+
+The model found by a LiNGAM method: a DAG with every edge directed, a causal ordering of
+the nodes consistent with it and the weighted adjacency matrix, entry [i][j] holding the
+coefficient of the edge from node i to node j (zero when there is no edge), as in
+estimate.LinearSEM.
+*/
+type Result struct {
+	Graph       *graph.Graph
+	CausalOrder []*graph.Node
+	Adjacency   *mat.Dense
+}
+
+/*
+Method
+
+A LiNGAM method fitted to data with one row per sample and one column per node.
+DirectLingam and IcaLingam are turned into Methods by closures, e.g. for Bootstrap.
+*/
+type Method func(nodes []*graph.Node, data *mat.Dense) (*Result, error)
+
+func checkData(nodes []*graph.Node, data *mat.Dense) error {
+	n, c := data.Dims()
+	if c != len(nodes) {
+		return fmt.Errorf("data has %d columns but %d nodes were given", c, len(nodes))
+	}
+	if n <= c+1 {
+		return fmt.Errorf("%d variables need more than %d samples, got %d", c, c+1, n)
+	}
+	return nil
+}
+
+/*
+fitOrder
+
+Estimates the edge coefficients given a causal ordering: every node is regressed on all
+nodes before it and an edge is kept when its coefficient differs from zero at level
+alpha (Wald test); the coefficients are then refitted on the kept parents.
+With alpha at most zero every earlier node is kept as a parent.
+*/
+func fitOrder(nodes []*graph.Node, data *mat.Dense, order []int, alpha float64) (*Result, error) {
+	critical := 0.0
+	if alpha > 0 {
+		critical = distuv.UnitNormal.Quantile(1 - alpha/2)
+	}
+	g := graph.NewGraph(nodes)
+	for k, j := range order {
+		if k == 0 {
+			continue
+		}
+		fit, err := estimate.Regress(data, j, order[:k])
+		if err != nil {
+			return nil, fmt.Errorf("fitting %s: %w", nodes[j].GetName(), err)
+		}
+		for r, i := range order[:k] {
+			if math.Abs(fit.Coefficients[r]) > critical*fit.StandardErrors[r] {
+				g.AddDirectedEdge(nodes[i], nodes[j])
+			}
+		}
+	}
+	sem, err := estimate.FitLinearSEM(g, data)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Graph: g, Adjacency: sem.GetCoefficients()}
+	for _, j := range order {
+		result.CausalOrder = append(result.CausalOrder, nodes[j])
+	}
+	return result, nil
+}
+
+/*
+BootstrapResult
+
+Summarizes a LiNGAM method over bootstrap resamples of the data: entry [i][j] of
+Probabilities is the fraction of resamples whose graph has the edge from node i to node
+j, and entry [i][j] of MeanAdjacency is its coefficient averaged over all resamples
+(counting zero where the edge is missing). The individual results are kept in Results.
+*/
+type BootstrapResult struct {
+	Probabilities *mat.Dense
+	MeanAdjacency *mat.Dense
+	Results       []*Result
+}
+
+/*
+Bootstrap
+
+Fits method to samples resamples of the rows of data drawn with replacement.
+*/
+func Bootstrap(nodes []*graph.Node, data *mat.Dense, method Method, samples int, rng *rand.Rand) (*BootstrapResult, error) {
+	if samples < 1 {
+		return nil, fmt.Errorf("number of bootstrap samples must be positive")
+	}
+	n, c := data.Dims()
+	result := &BootstrapResult{
+		Probabilities: mat.NewDense(c, c, nil),
+		MeanAdjacency: mat.NewDense(c, c, nil),
+	}
+	resample := mat.NewDense(n, c, nil)
+	for b := 0; b < samples; b++ {
+		for i := 0; i < n; i++ {
+			resample.SetRow(i, data.RawRowView(rng.Intn(n)))
+		}
+		fit, err := method(nodes, resample)
+		if err != nil {
+			return nil, fmt.Errorf("bootstrap sample %d: %w", b, err)
+		}
+		for i := 0; i < c; i++ {
+			for j := 0; j < c; j++ {
+				if w := fit.Adjacency.At(i, j); w != 0 {
+					result.Probabilities.Set(i, j, result.Probabilities.At(i, j)+1)
+					result.MeanAdjacency.Set(i, j, result.MeanAdjacency.At(i, j)+w)
+				}
+			}
+		}
+		result.Results = append(result.Results, fit)
+	}
+	result.Probabilities.Scale(1/float64(samples), result.Probabilities)
+	result.MeanAdjacency.Scale(1/float64(samples), result.MeanAdjacency)
+	return result, nil
+}
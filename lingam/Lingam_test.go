@@ -0,0 +1,41 @@
+package lingam
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+func TestBootstrapEdgeProbabilities(t *testing.T) {
+	rng := rand.New(rand.NewSource(39))
+	truth, data := simulateLingam(rng, 4, 5000)
+	nodes := truth.GetNodes()
+	method := func(nodes []*graph.Node, data *mat.Dense) (*Result, error) {
+		return DirectLingam(nodes, data, PairwiseLikelihood, 0.01)
+	}
+	result, err := Bootstrap(nodes, data, method, 20, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Results) != 20 {
+		t.Fatalf("want 20 results, got %d", len(result.Results))
+	}
+	for i, a := range nodes {
+		for j, b := range nodes {
+			p := result.Probabilities.At(i, j)
+			if truth.IsDirectedFromTo(a, b) && p < 0.9 {
+				t.Errorf("edge %s --> %s has probability %.2f", a.GetName(), b.GetName(), p)
+			}
+			if !truth.IsDirectedFromTo(a, b) && p > 0.2 {
+				t.Errorf("absent edge %s --> %s has probability %.2f", a.GetName(), b.GetName(), p)
+			}
+			if p == 0 && result.MeanAdjacency.At(i, j) != 0 {
+				t.Errorf("edge %s --> %s never found but has mean weight %.3f", a.GetName(), b.GetName(), result.MeanAdjacency.At(i, j))
+			}
+		}
+	}
+	if _, err := Bootstrap(nodes, data, method, 0, rng); err == nil {
+		t.Error("zero bootstrap samples must fail")
+	}
+}
@@ -0,0 +1,239 @@
+package notears
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+	"math"
+	"sort"
+)
+
+/*
+Options
+
+Tuning parameters of NOTEARS; DefaultOptions returns the values of the reference
+implementation.
+*/
+type Options struct {
+	// weight of the L1 penalty on the edge weights
+	Lambda1 float64
+	// maximum number of augmented Lagrangian (dual ascent) steps
+	MaxIter int
+	// the search stops once the acyclicity violation h(W) is at most HTol
+	HTol float64
+	// the search stops once the penalty coefficient ρ exceeds RhoMax
+	RhoMax float64
+	// edges whose weight is smaller in absolute value are dropped from the result
+	WThreshold float64
+}
+
+func DefaultOptions() Options {
+	return Options{Lambda1: 0.1, MaxIter: 100, HTol: 1e-8, RhoMax: 1e16, WThreshold: 0.3}
+}
+
+/*
+Notears
+
+Learns a linear SEM with NOTEARS (Zheng et al., 2018) from data with one row per sample
+and one column per node: the weighted adjacency matrix W minimizes the least squares
+loss 1/2n ‖X - XW‖² plus an L1 penalty subject to h(W) = tr(exp(W∘W)) - d = 0, which
+holds exactly when W is acyclic. The constraint is enforced by an augmented Lagrangian
+whose subproblems are solved by L-BFGS.
+The L1 penalty is made smooth by writing W = U∘U - V∘V and penalizing Σ U² + V², which
+has the same minimizers.
+Weights below options.WThreshold in absolute value are set to zero, and if the result is
+still cyclic the smallest remaining weights are dropped until it is a DAG. The weight of
+every edge of the returned graph is set (see graph.Graph.GetEdgeWeight).
+Returns an error unless MaxIter is positive, HTol is not negative and RhoMax exceeds 1.
+*/
+func Notears(nodes []*graph.Node, data *mat.Dense, options Options) (*graph.Graph, error) {
+	n, d := data.Dims()
+	if d != len(nodes) {
+		return nil, fmt.Errorf("data has %d columns but %d nodes were given", d, len(nodes))
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("at least two samples are needed")
+	}
+	if options.MaxIter < 1 {
+		return nil, fmt.Errorf("MaxIter must be positive")
+	}
+	if options.HTol < 0 {
+		return nil, fmt.Errorf("HTol must not be negative")
+	}
+	if options.RhoMax <= 1 {
+		return nil, fmt.Errorf("RhoMax must exceed the initial penalty coefficient 1")
+	}
+	s := newSolver(data)
+
+	// U = V gives W = 0 without sitting at the stationary point U = V = 0
+	x := make([]float64, 2*d*d)
+	for i := range x {
+		x[i] = 0.1
+	}
+	rho, alpha, h := 1.0, 0.0, math.Inf(1)
+	for iter := 0; iter < options.MaxIter; iter++ {
+		var next []float64
+		var hNext float64
+		for rho < options.RhoMax {
+			problem := optimize.Problem{
+				Func: func(x []float64) float64 {
+					return s.objective(x, nil, rho, alpha, options.Lambda1)
+				},
+				Grad: func(grad, x []float64) {
+					s.objective(x, grad, rho, alpha, options.Lambda1)
+				},
+			}
+			result, err := optimize.Minimize(problem, x, nil, &optimize.LBFGS{})
+			if err != nil && (result == nil || math.IsNaN(result.F) || math.IsInf(result.F, 0)) {
+				return nil, fmt.Errorf("inner optimization failed: %w", err)
+			}
+			next = result.X
+			hNext, _ = acyclicity(s.weights(next))
+			if hNext > 0.25*h {
+				rho *= 10
+			} else {
+				break
+			}
+		}
+		x, h = next, hNext
+		alpha += rho * h
+		if h <= options.HTol || rho >= options.RhoMax {
+			break
+		}
+	}
+	return toGraph(nodes, s.weights(x), options.WThreshold), nil
+}
+
+type solver struct {
+	d int
+	// Xᵀ X / n, all the loss needs
+	gram *mat.Dense
+}
+
+func newSolver(data *mat.Dense) *solver {
+	n, d := data.Dims()
+	centered := mat.NewDense(n, d, nil)
+	for j := 0; j < d; j++ {
+		mean := 0.0
+		for i := 0; i < n; i++ {
+			mean += data.At(i, j) / float64(n)
+		}
+		for i := 0; i < n; i++ {
+			centered.Set(i, j, data.At(i, j)-mean)
+		}
+	}
+	gram := mat.NewDense(d, d, nil)
+	gram.Mul(centered.T(), centered)
+	gram.Scale(1/float64(n), gram)
+	return &solver{d: d, gram: gram}
+}
+
+// weights returns W = U∘U - V∘V with a zero diagonal, x holding U then V row by row.
+func (s *solver) weights(x []float64) *mat.Dense {
+	d := s.d
+	w := mat.NewDense(d, d, nil)
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			if i != j {
+				u, v := x[i*d+j], x[d*d+i*d+j]
+				w.Set(i, j, u*u-v*v)
+			}
+		}
+	}
+	return w
+}
+
+/*
+objective
+
+Returns the augmented Lagrangian 1/2n ‖X - XW‖² + ρ/2 h² + αh + λ Σ U² + V², storing its
+gradient with respect to x in grad unless grad is nil.
+*/
+func (s *solver) objective(x, grad []float64, rho, alpha, lambda float64) float64 {
+	d := s.d
+	w := s.weights(x)
+	// with S = XᵀX/n the loss is tr((I-W)ᵀ S (I-W))/2 and its gradient -S(I-W)
+	residual := mat.NewDense(d, d, nil)
+	for i := 0; i < d; i++ {
+		residual.Set(i, i, 1)
+	}
+	residual.Sub(residual, w)
+	var sr mat.Dense
+	sr.Mul(s.gram, residual)
+	loss := 0.0
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			loss += residual.At(i, j) * sr.At(i, j) / 2
+		}
+	}
+	h, gradH := acyclicity(w)
+	penalty := 0.0
+	for _, v := range x {
+		penalty += v * v
+	}
+	value := loss + rho/2*h*h + alpha*h + lambda*penalty
+	if grad == nil {
+		return value
+	}
+
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			u, v := x[i*d+j], x[d*d+i*d+j]
+			if i == j {
+				grad[i*d+j], grad[d*d+i*d+j] = 0, 0
+				continue
+			}
+			g := -sr.At(i, j) + (rho*h+alpha)*gradH.At(i, j)
+			grad[i*d+j] = 2*u*g + 2*lambda*u
+			grad[d*d+i*d+j] = -2*v*g + 2*lambda*v
+		}
+	}
+	return value
+}
+
+// acyclicity returns h(W) = tr(exp(W∘W)) - d and its gradient exp(W∘W)ᵀ ∘ 2W.
+func acyclicity(w *mat.Dense) (float64, *mat.Dense) {
+	d, _ := w.Dims()
+	var squared, e mat.Dense
+	squared.MulElem(w, w)
+	e.Exp(&squared)
+	grad := mat.NewDense(d, d, nil)
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			grad.Set(i, j, e.At(j, i)*2*w.At(i, j))
+		}
+	}
+	return mat.Trace(&e) - float64(d), grad
+}
+
+// toGraph builds the weighted DAG of the entries of w at least threshold in absolute
+// value, dropping the smallest ones that close cycles.
+func toGraph(nodes []*graph.Node, w *mat.Dense, threshold float64) *graph.Graph {
+	d := len(nodes)
+	type entry struct {
+		i, j   int
+		weight float64
+	}
+	var entries []entry
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			if i != j && math.Abs(w.At(i, j)) >= threshold {
+				entries = append(entries, entry{i, j, w.At(i, j)})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		return math.Abs(entries[a].weight) > math.Abs(entries[b].weight)
+	})
+	g := graph.NewGraph(append([]*graph.Node{}, nodes...))
+	for _, e := range entries {
+		from, to := nodes[e.i], nodes[e.j]
+		if g.IsAdjacentTo(from, to) || graph.ExistsDirectedPathFromToBreadthFirst(to, from, g) {
+			continue
+		}
+		g.AddDirectedEdge(from, to)
+		g.SetEdgeWeight(from, to, e.weight)
+	}
+	return g
+}
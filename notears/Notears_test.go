@@ -0,0 +1,65 @@
+package notears
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+func newTestNodes(n int) []*graph.Node {
+	nodes := make([]*graph.Node, n)
+	for i := range nodes {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(fmt.Sprintf("X%d", i+1))
+	}
+	return nodes
+}
+
+// chainData samples X1 --> X2 --> X3 with weights 2 and -1.5 and unit Gaussian errors.
+func chainData(rng *rand.Rand, samples int) *mat.Dense {
+	data := mat.NewDense(samples, 3, nil)
+	for s := 0; s < samples; s++ {
+		x1 := rng.NormFloat64()
+		x2 := 2*x1 + rng.NormFloat64()
+		x3 := -1.5*x2 + rng.NormFloat64()
+		data.SetRow(s, []float64{x1, x2, x3})
+	}
+	return data
+}
+
+func TestNotearsRejectsInvalidOptions(t *testing.T) {
+	nodes := newTestNodes(3)
+	data := chainData(rand.New(rand.NewSource(39)), 100)
+	for _, c := range []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"zero MaxIter", func(o *Options) { o.MaxIter = 0 }},
+		{"negative HTol", func(o *Options) { o.HTol = -1 }},
+		{"zero RhoMax", func(o *Options) { o.RhoMax = 0 }},
+		{"RhoMax of one", func(o *Options) { o.RhoMax = 1 }},
+	} {
+		options := DefaultOptions()
+		c.modify(&options)
+		if _, err := Notears(nodes, data, options); err == nil {
+			t.Errorf("%s: want an error", c.name)
+		}
+	}
+}
+
+func TestNotearsRecoversChain(t *testing.T) {
+	nodes := newTestNodes(3)
+	data := chainData(rand.New(rand.NewSource(39)), 2000)
+	g, err := Notears(nodes, data, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.GetGraphEdges()) != 2 || !g.IsAdjacentTo(nodes[0], nodes[1]) || !g.IsAdjacentTo(nodes[1], nodes[2]) {
+		t.Errorf("want the skeleton X1 - X2 - X3, got\n%s", g.ToString())
+	}
+	if !graph.IsDag(g) {
+		t.Errorf("result is not a DAG\n%s", g.ToString())
+	}
+}
@@ -0,0 +1,149 @@
+package score
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"math"
+)
+
+/*
+Bic
+
+The Bayesian information criterion of linear Gaussian models,
+-n/2 log(residual variance) - penaltyDiscount * (|parents| + 1)/2 log n per node; a
+discount of 1 gives the usual BIC, larger values sparser graphs. With interventional
+data, the local score of a node only uses the samples where it was not intervened on,
+whose mechanism is the one the parents describe (Hauser & Bühlmann, 2012).
+*/
+type Bic struct {
+	variables       []*graph.Node
+	index           map[*graph.Node]int
+	covariances     []*mat.SymDense
+	sampleSizes     []int
+	penaltyDiscount float64
+}
+
+/*
+NewBic
+
+Returns the score of observational data with one row per sample and one column per
+variable. Missing values (NaN) are rejected, since the covariances would silently turn
+into NaN.
+*/
+func NewBic(variables []*graph.Node, data *mat.Dense, penaltyDiscount float64) (*Bic, error) {
+	n, c := data.Dims()
+	if c != len(variables) {
+		return nil, fmt.Errorf("data has %d columns but %d variables were given", c, len(variables))
+	}
+	settings := make([]int, n)
+	return newBic(variables, data, [][]*graph.Node{nil}, settings, penaltyDiscount)
+}
+
+/*
+NewInterventionalBic
+
+Returns the score of data from several experimental settings, see SplitInterventions.
+*/
+func NewInterventionalBic(interventions *Interventions, penaltyDiscount float64) (*Bic, error) {
+	return newBic(interventions.Variables, interventions.Data, interventions.Targets, interventions.Settings, penaltyDiscount)
+}
+
+func newBic(variables []*graph.Node, data *mat.Dense, targets [][]*graph.Node, settings []int, penaltyDiscount float64) (*Bic, error) {
+	n, c := data.Dims()
+	if len(settings) != n {
+		return nil, fmt.Errorf("data has %d rows but %d settings were given", n, len(settings))
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < c; j++ {
+			if math.IsNaN(data.At(i, j)) {
+				return nil, fmt.Errorf("value of %s in sample %d is missing", variables[j].GetName(), i)
+			}
+		}
+	}
+	s := &Bic{
+		variables:       variables,
+		index:           map[*graph.Node]int{},
+		covariances:     make([]*mat.SymDense, c),
+		sampleSizes:     make([]int, c),
+		penaltyDiscount: penaltyDiscount,
+	}
+	for j, v := range variables {
+		s.index[v] = j
+	}
+	// samples are shared by all nodes intervened on in the same settings
+	cache := map[string]int{}
+	for j, v := range variables {
+		var rows []int
+		var key []bool
+		for _, target := range targets {
+			key = append(key, !containsNode(target, v))
+		}
+		if k, ok := cache[fmt.Sprint(key)]; ok {
+			s.covariances[j], s.sampleSizes[j] = s.covariances[k], s.sampleSizes[k]
+			continue
+		}
+		for i, setting := range settings {
+			if setting < 0 || setting >= len(targets) {
+				return nil, fmt.Errorf("sample %d has unknown setting %d", i, setting)
+			}
+			if key[setting] {
+				rows = append(rows, i)
+			}
+		}
+		if len(rows) < 2 {
+			return nil, fmt.Errorf("%s is intervened on in all but %d samples", v.GetName(), len(rows))
+		}
+		sub := mat.NewDense(len(rows), c, nil)
+		for k, i := range rows {
+			sub.SetRow(k, data.RawRowView(i))
+		}
+		s.covariances[j] = mat.NewSymDense(c, nil)
+		stat.CovarianceMatrix(s.covariances[j], sub, nil)
+		s.sampleSizes[j] = len(rows)
+		cache[fmt.Sprint(key)] = j
+	}
+	return s, nil
+}
+
+func (s *Bic) GetVariables() []*graph.Node {
+	return s.variables
+}
+
+func (s *Bic) LocalScore(node *graph.Node, parents []*graph.Node) (float64, error) {
+	j, ok := s.index[node]
+	if !ok {
+		return 0, fmt.Errorf("variable %s is not covered by the score", node.GetName())
+	}
+	columns := make([]int, len(parents))
+	for k, p := range parents {
+		i, ok := s.index[p]
+		if !ok {
+			return 0, fmt.Errorf("variable %s is not covered by the score", p.GetName())
+		}
+		columns[k] = i
+	}
+	covariance := s.covariances[j]
+	variance := covariance.At(j, j)
+	if len(columns) > 0 {
+		sub := mat.NewSymDense(len(columns), nil)
+		cross := mat.NewVecDense(len(columns), nil)
+		for a, ca := range columns {
+			cross.SetVec(a, covariance.At(ca, j))
+			for b := a; b < len(columns); b++ {
+				sub.SetSym(a, b, covariance.At(ca, columns[b]))
+			}
+		}
+		var beta mat.VecDense
+		if err := beta.SolveVec(sub, cross); err != nil {
+			return 0, fmt.Errorf("covariance of the parents of %s is singular", node.GetName())
+		}
+		variance -= mat.Dot(&beta, cross)
+	}
+	if variance <= 0 {
+		return 0, fmt.Errorf("%s is a deterministic function of its parents", node.GetName())
+	}
+	n := float64(s.sampleSizes[j])
+	return -n/2*math.Log(variance) - s.penaltyDiscount*float64(len(parents)+1)/2*math.Log(n), nil
+}
@@ -0,0 +1,18 @@
+package score
+
+import (
+	"GoCausal/graph"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+func TestNewBicRejectsMissingValues(t *testing.T) {
+	x, y := &graph.Node{}, &graph.Node{}
+	x.SetName("X")
+	y.SetName("Y")
+	data := mat.NewDense(3, 2, []float64{1, 2, 3, math.NaN(), 5, 7})
+	if _, err := NewBic([]*graph.Node{x, y}, data, 1); err == nil {
+		t.Error("want an error for a missing value")
+	}
+}
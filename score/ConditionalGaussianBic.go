@@ -0,0 +1,70 @@
+package score
+
+import (
+	"GoCausal/data"
+	"GoCausal/graph"
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+ConditionalGaussianBic
+
+The Bayesian information criterion of conditional Gaussian models for mixed discrete and
+continuous data (Andrews et al., 2018): the local score of a node is the log-likelihood
+of the node and its parents minus that of the parents, see
+data.DataSet.ConditionalGaussianLikelihood, penalized by penaltyDiscount/2 log n times
+the difference in their numbers of parameters. For a discrete node with continuous
+parents this is an approximation, the parents being modelled given the node rather than
+the reverse, which tends to favour extra discrete parents.
+*/
+type ConditionalGaussianBic struct {
+	dataSet         *data.DataSet
+	penaltyDiscount float64
+	fits            map[string][2]float64
+}
+
+func NewConditionalGaussianBic(ds *data.DataSet, penaltyDiscount float64) *ConditionalGaussianBic {
+	return &ConditionalGaussianBic{dataSet: ds, penaltyDiscount: penaltyDiscount, fits: map[string][2]float64{}}
+}
+
+func (s *ConditionalGaussianBic) GetVariables() []*graph.Node {
+	return s.dataSet.GetVariables()
+}
+
+func (s *ConditionalGaussianBic) LocalScore(node *graph.Node, parents []*graph.Node) (float64, error) {
+	joint, err := s.fit(append([]*graph.Node{node}, parents...))
+	if err != nil {
+		return 0, err
+	}
+	marginal, err := s.fit(parents)
+	if err != nil {
+		return 0, err
+	}
+	n := float64(s.dataSet.GetNumRows())
+	return joint[0] - marginal[0] - s.penaltyDiscount*(joint[1]-marginal[1])/2*math.Log(n), nil
+}
+
+// fit returns the log-likelihood and number of parameters of the variables, cached by
+// their set of columns.
+func (s *ConditionalGaussianBic) fit(nodes []*graph.Node) ([2]float64, error) {
+	columns := make([]int, len(nodes))
+	for k, v := range nodes {
+		columns[k] = s.dataSet.GetColumn(v)
+		if columns[k] < 0 {
+			return [2]float64{}, fmt.Errorf("variable %s is not covered by the score", v.GetName())
+		}
+	}
+	sort.Ints(columns)
+	key := fmt.Sprint(columns)
+	if fit, ok := s.fits[key]; ok {
+		return fit, nil
+	}
+	loglik, dof, err := s.dataSet.ConditionalGaussianLikelihood(nodes)
+	if err != nil {
+		return [2]float64{}, err
+	}
+	s.fits[key] = [2]float64{loglik, float64(dof)}
+	return s.fits[key], nil
+}
@@ -0,0 +1,109 @@
+package score
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"sort"
+)
+
+/*
+Interventions
+
+A data set whose samples come from several experimental settings. Data holds the
+measured variables only; Settings gives the setting of every sample, an index into
+Targets, which lists the variables intervened on in each setting. The observational
+setting, if present, has an empty target.
+*/
+type Interventions struct {
+	Variables []*graph.Node
+	Data      *mat.Dense
+	Targets   [][]*graph.Node
+	Settings  []int
+}
+
+/*
+SplitInterventions
+
+Separates the measured variables from the intervention indicators among the nodes, see
+graph.Node.SetInterventionTarget. A sample intervenes on a variable if a status column
+of it is nonzero or a value column of it is not NaN; the value, when given, replaces the
+one in the column of the variable. Samples are grouped into settings by the set of
+variables they intervene on, in order of first appearance.
+*/
+func SplitInterventions(nodes []*graph.Node, data *mat.Dense) (*Interventions, error) {
+	n, c := data.Dims()
+	if c != len(nodes) {
+		return nil, fmt.Errorf("data has %d columns but %d nodes were given", c, len(nodes))
+	}
+	column := map[*graph.Node]int{}
+	var measured, indicators []int
+	for j, node := range nodes {
+		if node.GetInterventionTarget() == nil {
+			column[node] = len(measured)
+			measured = append(measured, j)
+		} else {
+			indicators = append(indicators, j)
+		}
+	}
+	for _, j := range indicators {
+		if target := nodes[j].GetInterventionTarget(); !containsNode(nodes, target) || target.GetInterventionTarget() != nil {
+			return nil, fmt.Errorf("indicator %s does not refer to a measured variable", nodes[j].GetName())
+		}
+	}
+
+	result := &Interventions{Data: mat.NewDense(n, len(measured), nil), Settings: make([]int, n)}
+	for _, j := range measured {
+		result.Variables = append(result.Variables, nodes[j])
+	}
+	settings := map[string]int{}
+	for i := 0; i < n; i++ {
+		for k, j := range measured {
+			result.Data.Set(i, k, data.At(i, j))
+		}
+		intervened := map[int]bool{}
+		for _, j := range indicators {
+			v := data.At(i, j)
+			k := column[nodes[j].GetInterventionTarget()]
+			switch nodes[j].GetNodeVariableType() {
+			case graph.INTERVENTION_STATUS:
+				if v != 0 {
+					intervened[k] = true
+				}
+			case graph.INTERVENTION_VALUE:
+				if !math.IsNaN(v) {
+					intervened[k] = true
+					result.Data.Set(i, k, v)
+				}
+			}
+		}
+		columns := make([]int, 0, len(intervened))
+		for k := range intervened {
+			columns = append(columns, k)
+		}
+		sort.Ints(columns)
+		key := fmt.Sprint(columns)
+		s, ok := settings[key]
+		if !ok {
+			s = len(result.Targets)
+			settings[key] = s
+			target := make([]*graph.Node, len(columns))
+			for t, k := range columns {
+				target[t] = result.Variables[k]
+			}
+			result.Targets = append(result.Targets, target)
+		}
+		result.Settings[i] = s
+	}
+	return result, nil
+}
+
+func containsNode(nodes []*graph.Node, node *graph.Node) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
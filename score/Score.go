@@ -0,0 +1,31 @@
+package score
+
+import "GoCausal/graph"
+
+/*
+Score
+
+A decomposable score of DAGs over a fixed list of variables: the score of a DAG is the
+sum over its nodes of the local score of the node given its parents. Higher is better.
+*/
+type Score interface {
+	GetVariables() []*graph.Node
+	LocalScore(node *graph.Node, parents []*graph.Node) (float64, error)
+}
+
+/*
+ScoreDag
+
+Returns the score of a DAG over the variables of the score.
+*/
+func ScoreDag(s Score, dag *graph.Graph) (float64, error) {
+	total := 0.0
+	for _, node := range s.GetVariables() {
+		local, err := s.LocalScore(node, dag.GetParents(node))
+		if err != nil {
+			return 0, err
+		}
+		total += local
+	}
+	return total, nil
+}
@@ -0,0 +1,304 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/data"
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"math"
+	"sort"
+)
+
+/*
+CdnodOptions
+
+Alpha and Depth are those of the PC search. When the context node has no domain its
+values are a time index, cut into Segments contiguous ranges of equal size to estimate
+the mechanisms of each range; zero means 10. As for PcOptions, a zero Depth tests only
+marginal independences; start from DefaultCdnodOptions.
+*/
+type CdnodOptions struct {
+	Alpha    float64
+	Depth    int
+	Segments int
+}
+
+/*
+DefaultCdnodOptions
+
+Returns the options of DefaultPcOptions with 10 segments.
+*/
+func DefaultCdnodOptions() CdnodOptions {
+	pc := DefaultPcOptions()
+	return CdnodOptions{Alpha: pc.Alpha, Depth: pc.Depth, Segments: 10}
+}
+
+/*
+CdnodResult
+
+The pattern found by CD-NOD over the variables and the context node, together with the
+variables whose causal mechanism changes with the context, i.e. those adjacent to it.
+*/
+type CdnodResult struct {
+	Graph    *graph.Graph
+	Context  *graph.Node
+	Changing []*graph.Node
+}
+
+/*
+Cdnod
+
+Runs CD-NOD (Huang et al., 2020) for data pooled over domains or collected over time.
+The variables of the data set must contain exactly one node of type SESSION, the
+context, whose column holds the domain index (0 .. k-1 when the node has a domain of k
+categories, see graph.Node.SetDomain) or the time index of every sample. The test is
+built by factory over all variables, context included; a nil factory picks
+citest.NewTest, which treats a context with a domain as discrete.
+
+The PC skeleton over the augmented variables finds the changing mechanisms as the
+neighbours of the context, which is a cause of all of them since it is exogenous. The
+context then serves as a surrogate variable: edges from it are oriented away from it,
+colliders are oriented from the separating sets, never into the context, and Meek's
+rules propagate the orientations. An edge between two changing variables left
+undirected is oriented by the independent change principle: the direction in which the
+mechanisms of cause and effect vary independently across contexts is chosen, unless it
+would create a new unshielded collider or a directed cycle. Each mechanism is summarized
+per domain (or time segment) by a linear Gaussian model, the marginal of the cause by its
+mean and variance and the conditional of the effect by its regression coefficients and
+residual variance, and their dependence across contexts is measured by HSIC.
+*/
+func Cdnod(ds *data.DataSet, factory citest.Factory, options CdnodOptions) (*CdnodResult, error) {
+	nodes, matrix := ds.GetVariables(), ds.GetData()
+	if factory == nil {
+		factory = citest.NewTest
+	}
+	var context *graph.Node
+	contextColumn := -1
+	for j, node := range nodes {
+		if node.GetNodeType() == graph.SESSION {
+			if context != nil {
+				return nil, fmt.Errorf("nodes %s and %s are both context nodes", context.GetName(), node.GetName())
+			}
+			context, contextColumn = node, j
+		}
+	}
+	if context == nil {
+		return nil, fmt.Errorf("no node of type SESSION to serve as context")
+	}
+	segments, err := contextSegments(context, mat.Col(nil, contextColumn, matrix), options.Segments)
+	if err != nil {
+		return nil, err
+	}
+
+	test, err := factory(nodes, matrix)
+	if err != nil {
+		return nil, err
+	}
+	g, sepsets, err := FindSkeleton(test, PcOptions{Alpha: options.Alpha, Depth: options.Depth})
+	if err != nil {
+		return nil, err
+	}
+	result := &CdnodResult{Graph: g, Context: context}
+	for _, node := range g.GetAdjacentNodes(context) {
+		g.RemoveConnectingEdges(context, node)
+		g.AddDirectedEdge(context, node)
+		result.Changing = append(result.Changing, node)
+	}
+	OrientColliders(g, sepsets, []*graph.Node{context})
+	graph.ApplyMeekRules(g)
+
+	index := map[*graph.Node]int{}
+	for j, node := range nodes {
+		index[node] = j
+	}
+	changing := map[*graph.Node]bool{}
+	for _, node := range result.Changing {
+		changing[node] = true
+	}
+	if len(segments) > 2 {
+		orientByChanges(g, changing, func(cause, effect *graph.Node) float64 {
+			return changeDependence(matrix, segments, index[cause], index[effect])
+		})
+	}
+	g.SetPattern(true)
+	return result, nil
+}
+
+/*
+orientByChanges
+
+Orients the undirected edges between two changing nodes one at a time, each in the
+direction with the smaller dependence between the changes of the mechanisms of cause and
+effect, and propagates every orientation with Meek's rules. As in Meek's rules, an
+orientation that would create a new unshielded collider or a directed cycle is skipped
+and the edge is left undirected.
+*/
+func orientByChanges(g *graph.Graph, changing map[*graph.Node]bool, dependence func(cause, effect *graph.Node) float64) {
+	for {
+		oriented := false
+		for _, e := range g.GetGraphEdges() {
+			a, b := e.GetNode1(), e.GetNode2()
+			if !graph.IsUndirectedEdge(e) || !changing[a] || !changing[b] {
+				continue
+			}
+			forward, backward := dependence(a, b), dependence(b, a)
+			if forward == backward {
+				continue
+			}
+			if backward < forward {
+				a, b = b, a
+			}
+			if !consistentOrientation(g, a, b) {
+				continue
+			}
+			g.RemoveConnectingEdges(a, b)
+			g.AddDirectedEdge(a, b)
+			oriented = true
+			break
+		}
+		if !oriented {
+			return
+		}
+		graph.ApplyMeekRules(g)
+	}
+}
+
+// consistentOrientation reports whether the undirected edge a --- b can become a --> b
+// without making a and a parent of b an unshielded collider or closing a directed cycle.
+func consistentOrientation(g *graph.Graph, a, b *graph.Node) bool {
+	for _, c := range g.GetParents(b) {
+		if c != a && !g.IsAdjacentTo(a, c) {
+			return false
+		}
+	}
+	return !graph.ExistsDirectedPathFromToBreadthFirst(b, a, g)
+}
+
+// contextSegments groups the rows by domain, or into contiguous ranges of the time index.
+func contextSegments(context *graph.Node, values []float64, count int) ([][]int, error) {
+	if k := len(context.GetDomain()); k > 0 {
+		segments := make([][]int, k)
+		for i, v := range values {
+			if v < 0 || int(v) >= k || v != math.Trunc(v) {
+				return nil, fmt.Errorf("value %v of %s is not a domain index", v, context.GetName())
+			}
+			segments[int(v)] = append(segments[int(v)], i)
+		}
+		return segments, nil
+	}
+	if count <= 0 {
+		count = 10
+	}
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return values[order[a]] < values[order[b]]
+	})
+	segments := make([][]int, count)
+	for k, i := range order {
+		s := k * count / len(order)
+		segments[s] = append(segments[s], i)
+	}
+	return segments, nil
+}
+
+/*
+changeDependence
+
+Returns the HSIC between the per-segment parameters of the mechanism of cause and those
+of the mechanism of effect given cause, under a linear Gaussian model. Segments with too
+few samples to fit are skipped.
+*/
+func changeDependence(matrix *mat.Dense, segments [][]int, cause, effect int) float64 {
+	var marginals, conditionals [][]float64
+	for _, rows := range segments {
+		if len(rows) < 4 {
+			continue
+		}
+		sub := mat.NewDense(len(rows), 2, nil)
+		for k, i := range rows {
+			sub.Set(k, 0, matrix.At(i, cause))
+			sub.Set(k, 1, matrix.At(i, effect))
+		}
+		fit, err := estimate.Regress(sub, 1, []int{0})
+		if err != nil {
+			continue
+		}
+		mean, std := stat.MeanStdDev(mat.Col(nil, 0, sub), nil)
+		marginals = append(marginals, []float64{mean, math.Log(std)})
+		conditionals = append(conditionals, []float64{fit.Intercept, fit.Coefficients[0], math.Log(fit.ResidualVariance) / 2})
+	}
+	return hsic(standardizeRows(marginals), standardizeRows(conditionals))
+}
+
+// standardizeRows scales every coordinate of the vectors to zero mean and unit variance.
+func standardizeRows(x [][]float64) [][]float64 {
+	if len(x) == 0 {
+		return x
+	}
+	result := make([][]float64, len(x))
+	for i := range x {
+		result[i] = make([]float64, len(x[i]))
+	}
+	column := make([]float64, len(x))
+	for d := range x[0] {
+		for i := range x {
+			column[i] = x[i][d]
+		}
+		mean, std := stat.MeanStdDev(column, nil)
+		for i := range x {
+			if std > 0 {
+				result[i][d] = (x[i][d] - mean) / std
+			}
+		}
+	}
+	return result
+}
+
+// hsic returns the biased empirical HSIC of paired vectors with Gaussian kernels of unit
+// width per coordinate.
+func hsic(x, y [][]float64) float64 {
+	n := len(x)
+	if n < 2 {
+		return 0
+	}
+	k := centeredGram(x)
+	l := centeredGram(y)
+	total := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			total += k.At(i, j) * l.At(i, j)
+		}
+	}
+	return total / float64(n*n)
+}
+
+func centeredGram(x [][]float64) *mat.Dense {
+	n := len(x)
+	gram := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			d := 0.0
+			for k := range x[i] {
+				d += (x[i][k] - x[j][k]) * (x[i][k] - x[j][k])
+			}
+			gram.Set(i, j, math.Exp(-d/(2*float64(len(x[i])))))
+		}
+	}
+	centering := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			centering.Set(i, j, -1/float64(n))
+		}
+		centering.Set(i, i, 1-1/float64(n))
+	}
+	var left, result mat.Dense
+	left.Mul(centering, gram)
+	result.Mul(&left, centering)
+	return &result
+}
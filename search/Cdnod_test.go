@@ -0,0 +1,118 @@
+package search
+
+import (
+	"GoCausal/data"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+// simulateDomains samples X1 --> X2 --> X3 in every domain of the context C: the
+// marginal of X1 and the regression of X2 on X1 shift independently across domains
+// while the mechanism of X3 stays fixed.
+func simulateDomains(rng *rand.Rand, domains, perDomain int) *data.DataSet {
+	categories := make([]string, domains)
+	for d := range categories {
+		categories[d] = fmt.Sprint(d)
+	}
+	context := data.NewDiscreteVariable("C", categories)
+	context.SetNodeType(graph.SESSION)
+	variables := []*graph.Node{context, data.NewContinuousVariable("X1"), data.NewContinuousVariable("X2"), data.NewContinuousVariable("X3")}
+	matrix := mat.NewDense(domains*perDomain, 4, nil)
+	for d := 0; d < domains; d++ {
+		mean, scale := 4*rng.Float64()-2, 0.5+1.5*rng.Float64()
+		slope, noise := 0.5+rng.Float64(), 0.5+rng.Float64()
+		for s := 0; s < perDomain; s++ {
+			x1 := mean + scale*rng.NormFloat64()
+			x2 := slope*x1 + noise*rng.NormFloat64()
+			x3 := 0.8*x2 + rng.NormFloat64()
+			matrix.SetRow(d*perDomain+s, []float64{float64(d), x1, x2, x3})
+		}
+	}
+	ds, err := data.NewDataSet(variables, matrix)
+	if err != nil {
+		panic(err)
+	}
+	return ds
+}
+
+func TestCdnodFindsChangingMechanisms(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	ds := simulateDomains(rng, 20, 300)
+	result, err := Cdnod(ds, nil, DefaultCdnodOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := ds.GetVariables()
+	c, x1, x2, x3 := nodes[0], nodes[1], nodes[2], nodes[3]
+	if result.Context != c {
+		t.Fatalf("context: got %s, want C", result.Context.GetName())
+	}
+	if len(result.Changing) != 2 || result.Changing[0] != x1 || result.Changing[1] != x2 {
+		t.Errorf("changing mechanisms: got %v, want X1 and X2", nodeNames(result.Changing))
+	}
+	g := result.Graph
+	// C --> X2 --> X3 orients X2 --> X3 by R1, the changes orient X1 --> X2
+	for _, pair := range [][2]*graph.Node{{c, x1}, {c, x2}, {x1, x2}, {x2, x3}} {
+		if !g.IsDirectedFromTo(pair[0], pair[1]) {
+			t.Errorf("want %s --> %s in\n%s", pair[0].GetName(), pair[1].GetName(), g.ToString())
+		}
+	}
+	if g.IsAdjacentTo(c, x3) || g.IsAdjacentTo(x1, x3) {
+		t.Errorf("want C and X1 separated from X3 in\n%s", g.ToString())
+	}
+
+	context := ds.GetVariable("C")
+	context.SetNodeType(graph.MEASURED)
+	if _, err := Cdnod(ds, nil, DefaultCdnodOptions()); err == nil {
+		t.Error("data without a context node must fail")
+	}
+}
+
+func TestOrientByChangesKeepsPatternConsistent(t *testing.T) {
+	nodes := newTestNodes(5)
+	a, b, c, d, e := nodes[0], nodes[1], nodes[2], nodes[3], nodes[4]
+	// the changes favour a --> b and c --> d, but a --> b would make a --> b <-- e an
+	// unshielded collider and c --> d would close the cycle d --> e --> c
+	g := graph.NewGraph(nodes)
+	g.AddEdge(graph.UndirectedEdge(a, b))
+	g.AddDirectedEdge(e, b)
+	g.AddEdge(graph.UndirectedEdge(c, d))
+	g.AddDirectedEdge(d, e)
+	g.AddDirectedEdge(e, c)
+	changing := map[*graph.Node]bool{a: true, b: true, c: true, d: true}
+	favoured := map[[2]*graph.Node]bool{{a, b}: true, {c, d}: true}
+	orientByChanges(g, changing, func(cause, effect *graph.Node) float64 {
+		if favoured[[2]*graph.Node{cause, effect}] {
+			return 0
+		}
+		return 1
+	})
+	if !g.IsUndirectedFromTo(a, b) || !g.IsUndirectedFromTo(c, d) {
+		t.Errorf("inconsistent orientations must be skipped:\n%s", g.ToString())
+	}
+
+	// without e both orientations are allowed
+	g = graph.NewGraph(nodes[:4])
+	g.AddEdge(graph.UndirectedEdge(a, b))
+	g.AddEdge(graph.UndirectedEdge(c, d))
+	orientByChanges(g, changing, func(cause, effect *graph.Node) float64 {
+		if favoured[[2]*graph.Node{cause, effect}] {
+			return 0
+		}
+		return 1
+	})
+	if !g.IsDirectedFromTo(a, b) || !g.IsDirectedFromTo(c, d) {
+		t.Errorf("want a --> b and c --> d:\n%s", g.ToString())
+	}
+}
+
+func nodeNames(nodes []*graph.Node) []string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.GetName()
+	}
+	return names
+}
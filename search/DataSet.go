@@ -0,0 +1,30 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/data"
+	"GoCausal/score"
+)
+
+/*
+NewTest
+
+Returns the default independence test for the data set: Fisher's z test if all its
+variables are continuous and the conditional Gaussian test otherwise.
+*/
+func NewTest(ds *data.DataSet) (citest.CITest, error) {
+	return citest.NewTest(ds.GetVariables(), ds.GetData())
+}
+
+/*
+NewScore
+
+Returns the default score for the data set: the linear Gaussian BIC if all its variables
+are continuous and the conditional Gaussian BIC otherwise.
+*/
+func NewScore(ds *data.DataSet, penaltyDiscount float64) (score.Score, error) {
+	if ds.IsContinuous() {
+		return score.NewBic(ds.GetVariables(), ds.GetData(), penaltyDiscount)
+	}
+	return score.NewConditionalGaussianBic(ds, penaltyDiscount), nil
+}
@@ -0,0 +1,181 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"GoCausal/score"
+	"fmt"
+	"math"
+)
+
+// the subsets of the variables are bit masks of 32 bits
+const maxExactVariables = 31
+
+// the default memory limit, which fits the tables for 25 variables
+const defaultExactMemory = 4 << 30
+
+/*
+ExactOptions
+
+MaxParents caps the number of parents of every node; a negative value means no cap.
+Beware that the zero value therefore allows no parents at all; start from
+DefaultExactOptions. Since the local score of every allowed parent set is computed, a
+small cap is what makes problems beyond 15 or so variables tractable. MaxMemory bounds
+in bytes the tables of the dynamic programming, which grow as n 2^n for n variables;
+zero means 4 GiB, enough for 25 variables.
+*/
+type ExactOptions struct {
+	MaxParents int
+	MaxMemory  int64
+}
+
+/*
+DefaultExactOptions
+
+Returns options without a cap on the number of parents and the default memory limit.
+*/
+func DefaultExactOptions() ExactOptions {
+	return ExactOptions{MaxParents: -1}
+}
+
+/*
+ExactSearch
+
+Returns a DAG of highest score over the variables of the score by the dynamic
+programming of Silander & Myllymäki (2006). For every node and every set of candidate
+parents the best parent set among its subsets is found first; then, for every set of
+variables, the best sink, i.e. the node that can come last in an ordering of the set
+with its best parents among the others. The optimal ordering, and with it the optimal
+DAG, is read off from the full set backwards.
+Parent sets the score fails on, such as those with singular covariances, score -Inf and
+are never chosen.
+Returns an error, before any score is computed, if the tables would exceed the memory
+limit or there are more than 31 variables, and an error if some node has no parent set
+the score accepts.
+*/
+func ExactSearch(s score.Score, options ExactOptions) (*graph.Graph, error) {
+	nodes := s.GetVariables()
+	p := len(nodes)
+	if p > maxExactVariables {
+		return nil, fmt.Errorf("exact search supports at most %d variables, got %d", maxExactVariables, p)
+	}
+	if options.MaxMemory <= 0 {
+		options.MaxMemory = defaultExactMemory
+	}
+	// per node a score for each subset of the others, then a score and a sink for each
+	// subset of all variables
+	if p > 0 {
+		needed := 8*int64(p)<<uint(p-1) + 9*int64(1)<<uint(p)
+		if needed > options.MaxMemory {
+			return nil, fmt.Errorf("exact search over %d variables needs about %d MiB, more than the limit of %d MiB (MaxMemory, by default %d MiB)",
+				p, needed>>20, options.MaxMemory>>20, defaultExactMemory>>20)
+		}
+	}
+
+	subsets := 1 << uint(p)
+	bestScores := make([][]float64, p)
+	for v := 0; v < p; v++ {
+		others := 1 << uint(p-1)
+		bestScores[v] = make([]float64, others)
+		for c := 0; c < others; c++ {
+			parents := expandMask(uint32(c), v)
+			bestScores[v][c] = math.Inf(-1)
+			if options.MaxParents < 0 || bitCount(parents) <= options.MaxParents {
+				if local, err := s.LocalScore(nodes[v], maskNodes(nodes, parents)); err == nil {
+					bestScores[v][c] = local
+				}
+			}
+			for rest := uint32(c); rest != 0; rest &= rest - 1 {
+				smaller := uint32(c) &^ (rest & -rest)
+				if bestScores[v][smaller] > bestScores[v][c] {
+					bestScores[v][c] = bestScores[v][smaller]
+				}
+			}
+		}
+		if math.IsInf(bestScores[v][others-1], -1) {
+			return nil, fmt.Errorf("the score fails on every allowed parent set of %s", nodes[v].GetName())
+		}
+	}
+
+	networkScores := make([]float64, subsets)
+	sinks := make([]int8, subsets)
+	for set := 1; set < subsets; set++ {
+		networkScores[set] = math.Inf(-1)
+		for rest := uint32(set); rest != 0; rest &= rest - 1 {
+			sink := bitIndex(rest & -rest)
+			others := uint32(set) &^ (1 << uint(sink))
+			total := networkScores[others] + bestScores[sink][compressMask(others, sink)]
+			if total > networkScores[set] {
+				networkScores[set], sinks[set] = total, int8(sink)
+			}
+		}
+	}
+
+	g := graph.NewGraph(append([]*graph.Node{}, nodes...))
+	for set := uint32(subsets - 1); set != 0; {
+		sink := int(sinks[set])
+		set &^= 1 << uint(sink)
+		for _, parent := range maskNodes(nodes, bestParentSet(bestScores[sink], compressMask(set, sink), sink)) {
+			g.AddDirectedEdge(parent, nodes[sink])
+		}
+	}
+	return g, nil
+}
+
+// bestParentSet follows the table of best scores of node v down from the candidates c
+// to a subset whose own local score attains the best score, the parent set the table
+// was built from, and returns it as a subset of all variables. Keeping only the scores
+// saves the third of the memory that storing the parent sets would take.
+func bestParentSet(best []float64, c uint32, v int) uint32 {
+	for {
+		next := c
+		for rest := c; rest != 0; rest &= rest - 1 {
+			if smaller := c &^ (rest & -rest); best[smaller] == best[c] {
+				next = smaller
+				break
+			}
+		}
+		if next == c {
+			return expandMask(c, v)
+		}
+		c = next
+	}
+}
+
+// compressMask drops bit v from a subset of all variables, giving a subset of the others.
+func compressMask(set uint32, v int) uint32 {
+	low := set & (1<<uint(v) - 1)
+	return low | (set>>uint(v+1))<<uint(v)
+}
+
+// expandMask is the inverse of compressMask.
+func expandMask(set uint32, v int) uint32 {
+	low := set & (1<<uint(v) - 1)
+	return low | (set>>uint(v))<<uint(v+1)
+}
+
+func maskNodes(nodes []*graph.Node, set uint32) []*graph.Node {
+	var result []*graph.Node
+	for i := range nodes {
+		if set&(1<<uint(i)) != 0 {
+			result = append(result, nodes[i])
+		}
+	}
+	return result
+}
+
+func bitCount(set uint32) int {
+	count := 0
+	for ; set != 0; set &= set - 1 {
+		count++
+	}
+	return count
+}
+
+func bitIndex(bit uint32) int {
+	index := 0
+	for bit > 1 {
+		bit >>= 1
+		index++
+	}
+	return index
+}
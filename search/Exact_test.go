@@ -0,0 +1,96 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"GoCausal/score"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// failingScore fails on every parent set containing one of the failing parents, and on
+// every parent set of the failing node.
+type failingScore struct {
+	score.Score
+	parents map[*graph.Node]bool
+	node    *graph.Node
+}
+
+func (s *failingScore) LocalScore(node *graph.Node, parents []*graph.Node) (float64, error) {
+	if node == s.node {
+		return 0, fmt.Errorf("node %s is not supported", node.GetName())
+	}
+	for _, p := range parents {
+		if s.parents[p] {
+			return 0, fmt.Errorf("parent %s is not supported", p.GetName())
+		}
+	}
+	return s.Score.LocalScore(node, parents)
+}
+
+func TestExactSearchBeatsGreedySearches(t *testing.T) {
+	rng := rand.New(rand.NewSource(45))
+	for trial := 0; trial < 6; trial++ {
+		dag, bic, _ := simulateBic(t, rng, 7, 7+rng.Intn(5), 50000)
+		exact, err := ExactSearch(bic, DefaultExactOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		exactScore, err := score.ScoreDag(bic, exact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pattern, err := Ges(bic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gesDag, err := graph.PdagToDag(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hillClimb, err := HillClimb(bic, DefaultHillClimbOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, g := range map[string]*graph.Graph{"true DAG": dag, "GES": gesDag, "hill climbing": hillClimb} {
+			other, err := score.ScoreDag(bic, g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if other > exactScore+1e-6 {
+				t.Errorf("trial %d: %s scores %.4f, above the exact optimum %.4f", trial, name, other, exactScore)
+			}
+		}
+	}
+}
+
+func TestExactSearchOptions(t *testing.T) {
+	rng := rand.New(rand.NewSource(45))
+	_, bic, _ := simulateBic(t, rng, 6, 6, 2000)
+	nodes := bic.GetVariables()
+
+	_, err := ExactSearch(bic, ExactOptions{MaxParents: -1, MaxMemory: 1024})
+	if err == nil || !strings.Contains(err.Error(), "by default 4096 MiB") {
+		t.Errorf("want an error naming the default limit, got %v", err)
+	}
+
+	g, err := ExactSearch(bic, ExactOptions{})
+	if err != nil || len(g.GetGraphEdges()) != 0 {
+		t.Errorf("MaxParents 0 allows no edges, got %v", err)
+	}
+
+	failing := &failingScore{Score: bic, parents: map[*graph.Node]bool{nodes[0]: true}}
+	g, err = ExactSearch(failing, DefaultExactOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.GetChildren(nodes[0])) != 0 {
+		t.Errorf("%s cannot be a parent, got\n%s", nodes[0].GetName(), g.ToString())
+	}
+
+	failing = &failingScore{Score: bic, node: nodes[1]}
+	if _, err := ExactSearch(failing, DefaultExactOptions()); err == nil {
+		t.Error("want an error when a node has no parent set the score accepts")
+	}
+}
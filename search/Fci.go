@@ -0,0 +1,556 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+	"GoCausal/utils"
+)
+
+/*
+Fci
+
+Runs FCI (Spirtes et al., 2000) over the variables of the test, with the skeleton search
+of PC configured by options, and returns a PAG. After the unshielded colliders of the
+skeleton are oriented from the separating sets, every remaining edge x *-* y is tested
+again given subsets of the possible d-separating set of x, and of y, up to options.Depth
+elements: the nodes reached from it by a path on which every inner node is a collider
+or the middle of a triangle. The edges found absent are removed, the graph is reset to
+o-o edges, the colliders are oriented again and the rules of Zhang (2008) complete the
+orientation. FCI is consistent in the presence of latent confounders, at a cost in tests
+that grows quickly with the size of the possible d-separating sets.
+*/
+func Fci(test citest.CITest, options PcOptions) (*graph.Graph, error) {
+	g, sepsets, err := FindSkeleton(test, options)
+	if err != nil {
+		return nil, err
+	}
+	reorientAllWithCircles(g)
+	orientSepsetColliders(g, sepsets)
+
+	possible := map[*graph.Node][]*graph.Node{}
+	for _, n := range g.GetNodes() {
+		possible[n] = possibleDsep(g, n)
+	}
+	for _, e := range g.GetGraphEdges() {
+		x, y := e.GetNode1(), e.GetNode2()
+		adjacent := map[*graph.Node][]*graph.Node{x: possible[x], y: possible[y]}
+		found := false
+		for depth := 0; !found && (options.Depth < 0 || depth <= options.Depth); depth++ {
+			if depth >= len(possible[x]) && depth >= len(possible[y]) {
+				break
+			}
+			var z []*graph.Node
+			if z, found, err = findSepset(test, x, y, adjacent, depth, options.Alpha); err != nil {
+				return nil, err
+			}
+			if found {
+				g.RemoveConnectingEdges(x, y)
+				sepsets.Set(x, y, z)
+			}
+		}
+	}
+	reorientAllWithCircles(g)
+	orientSepsetColliders(g, sepsets)
+
+	o := &pagOrientation{g: g, sepsets: sepsets, test: test, options: options}
+	if err := o.run(); err != nil {
+		return nil, err
+	}
+	g.SetPag(true)
+	return g, nil
+}
+
+// orientSepsetColliders orients every unshielded triple a *-* b *-* c of g whose middle
+// node is not in the separating set of a and c as a *-> b <-* c.
+func orientSepsetColliders(g *graph.Graph, sepsets *SepsetMap) {
+	for _, t := range unshieldedTriples(g) {
+		a, b, c := t.GetX(), t.GetY(), t.GetZ()
+		if _, ok := sepsets.Get(a, c); ok && !sepsets.Separates(a, c, b) {
+			orientCollider(g, a, b, c)
+		}
+	}
+}
+
+// possibleDsep returns the nodes reached from x by a path on which every inner node b,
+// between a and c, is a collider a *-> b <-* c or has a and c adjacent.
+func possibleDsep(g *graph.Graph, x *graph.Node) []*graph.Node {
+	var reached []*graph.Node
+	seen := utils.NewSet(x)
+	visited := map[[2]*graph.Node]bool{}
+	var queue [][2]*graph.Node
+	for _, n := range g.GetAdjacentNodes(x) {
+		visited[[2]*graph.Node{x, n}] = true
+		queue = append(queue, [2]*graph.Node{x, n})
+	}
+	for len(queue) > 0 {
+		a, b := queue[0][0], queue[0][1]
+		queue = queue[1:]
+		if !seen.Contains(b) {
+			seen.Add(b)
+			reached = append(reached, b)
+		}
+		for _, c := range g.GetAdjacentNodes(b) {
+			if c == a || c == x || visited[[2]*graph.Node{b, c}] {
+				continue
+			}
+			collider := g.GetEndpoint(a, b) == graph.ARROW && g.GetEndpoint(c, b) == graph.ARROW
+			if collider || g.IsAdjacentTo(a, c) {
+				visited[[2]*graph.Node{b, c}] = true
+				queue = append(queue, [2]*graph.Node{b, c})
+			}
+		}
+	}
+	return reached
+}
+
+// pagOrientation applies the final orientation rules of FCI to a PAG whose unshielded
+// colliders are oriented.
+type pagOrientation struct {
+	g       *graph.Graph
+	sepsets *SepsetMap
+	test    citest.CITest
+	options PcOptions
+	// RFCI checks the adjacencies along a discriminating path before using it
+	reduced bool
+}
+
+/*
+run
+
+Applies rules R1-R4 and R8-R10 of Zhang (2008) until none applies, which makes the PAG
+complete for arrowheads and tails in the absence of selection bias. R1 is not applied
+to triples marked ambiguous on the graph. Under the reduced checks of RFCI, R4 may
+remove edges found to be absent, after which the rules start over.
+*/
+func (o *pagOrientation) run() error {
+	for {
+		changed := o.rule1() || o.rule2() || o.rule3()
+		removed, err := o.rule4()
+		if err != nil {
+			return err
+		}
+		changed = o.rule8() || o.rule9() || o.rule10() || changed || removed
+		if !changed {
+			return nil
+		}
+	}
+}
+
+// endpoint returns the endpoint at b of the edge between a and b.
+func (o *pagOrientation) endpoint(a, b *graph.Node) graph.Endpoint {
+	return o.g.GetEndpoint(a, b)
+}
+
+func (o *pagOrientation) isDirected(a, b *graph.Node) bool {
+	return o.endpoint(b, a) == graph.TAIL && o.endpoint(a, b) == graph.ARROW
+}
+
+// R1: a *-> b o-* c with a, c non-adjacent gives b --> c.
+func (o *pagOrientation) rule1() bool {
+	changed := false
+	for _, b := range o.g.GetNodes() {
+		adjacent := o.g.GetAdjacentNodes(b)
+		for _, a := range adjacent {
+			if o.endpoint(a, b) != graph.ARROW {
+				continue
+			}
+			for _, c := range adjacent {
+				if c == a || o.endpoint(c, b) != graph.CIRCLE || o.g.IsAdjacentTo(a, c) || o.g.IsAmbiguousTriple(graph.NewTriple(a, b, c)) {
+					continue
+				}
+				o.g.SetEndpoint(c, b, graph.TAIL)
+				o.g.SetEndpoint(b, c, graph.ARROW)
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// R2: a --> b *-> c or a *-> b --> c, with a *-o c, gives a *-> c.
+func (o *pagOrientation) rule2() bool {
+	changed := false
+	for _, a := range o.g.GetNodes() {
+		for _, c := range o.g.GetAdjacentNodes(a) {
+			if o.endpoint(a, c) != graph.CIRCLE {
+				continue
+			}
+			for _, b := range o.g.GetAdjacentNodes(a) {
+				if b == c || !o.g.IsAdjacentTo(b, c) {
+					continue
+				}
+				if (o.isDirected(a, b) && o.endpoint(b, c) == graph.ARROW) || (o.endpoint(a, b) == graph.ARROW && o.isDirected(b, c)) {
+					o.g.SetEndpoint(a, c, graph.ARROW)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// R3: a *-> b <-* c, a *-o d o-* c with a, c non-adjacent and d *-o b gives d *-> b.
+func (o *pagOrientation) rule3() bool {
+	changed := false
+	for _, b := range o.g.GetNodes() {
+		adjacent := o.g.GetAdjacentNodes(b)
+		for _, d := range adjacent {
+			if o.endpoint(d, b) != graph.CIRCLE {
+				continue
+			}
+			found := false
+			for i, a := range adjacent {
+				for _, c := range adjacent[i+1:] {
+					if a == d || c == d || o.g.IsAdjacentTo(a, c) || !o.g.IsAdjacentTo(a, d) || !o.g.IsAdjacentTo(c, d) {
+						continue
+					}
+					if o.endpoint(a, b) == graph.ARROW && o.endpoint(c, b) == graph.ARROW &&
+						o.endpoint(a, d) == graph.CIRCLE && o.endpoint(c, d) == graph.CIRCLE {
+						found = true
+					}
+				}
+			}
+			if found {
+				o.g.SetEndpoint(d, b, graph.ARROW)
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+/*
+rule4
+
+R4: on a discriminating path <d, ..., a, b, c> for b with b o-* c, b --> c if b is in the
+separating set of d and c, and a <-> b <-> c otherwise.
+*/
+func (o *pagOrientation) rule4() (bool, error) {
+	changed := false
+	for _, b := range o.g.GetNodes() {
+		for _, c := range o.g.GetAdjacentNodes(b) {
+			if o.endpoint(c, b) != graph.CIRCLE {
+				continue
+			}
+			for _, a := range o.g.GetAdjacentNodes(b) {
+				if a == c || o.endpoint(b, a) != graph.ARROW || !o.g.IsAdjacentTo(a, c) || !o.isDirected(a, c) {
+					continue
+				}
+				path := o.discriminatingPath(a, b, c)
+				if path == nil {
+					continue
+				}
+				d := path[0]
+				sepset, found, err := o.sepset(d, c)
+				if err != nil {
+					return false, err
+				}
+				if !found {
+					continue
+				}
+				if o.reduced {
+					removed, err := o.checkPath(path, sepset)
+					if err != nil {
+						return false, err
+					}
+					if removed {
+						return true, nil
+					}
+				}
+				if o.sepsets.Separates(d, c, b) {
+					o.g.SetEndpoint(c, b, graph.TAIL)
+					o.g.SetEndpoint(b, c, graph.ARROW)
+				} else {
+					o.g.SetEndpoint(a, b, graph.ARROW)
+					o.g.SetEndpoint(c, b, graph.ARROW)
+					o.g.SetEndpoint(b, c, graph.ARROW)
+				}
+				changed = true
+				break
+			}
+		}
+	}
+	return changed, nil
+}
+
+// discriminatingPath searches breadth first for a discriminating path <d, ..., a, b, c>
+// for b, in which every node between d and b is a collider and a parent of c and d is
+// not adjacent to c. Returns the path, or nil if there is none.
+func (o *pagOrientation) discriminatingPath(a, b, c *graph.Node) []*graph.Node {
+	previous := map[*graph.Node]*graph.Node{a: b}
+	visited := utils.NewSet(a, b, c)
+	q := utils.NewQueue(a)
+	for q.Size() > 0 {
+		x := q.Pop()
+		for _, y := range o.g.GetAdjacentNodes(x) {
+			if visited.Contains(y) || o.endpoint(y, x) != graph.ARROW {
+				continue
+			}
+			if !o.g.IsAdjacentTo(y, c) {
+				path := []*graph.Node{y}
+				for n := x; n != b; n = previous[n] {
+					path = append(path, n)
+				}
+				return append(path, b, c)
+			}
+			if o.endpoint(x, y) == graph.ARROW && o.isDirected(y, c) {
+				visited.Add(y)
+				previous[y] = x
+				q.Append(y)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPath tests, given the separating set of the ends of a discriminating path, the
+// adjacencies along the path and from its colliders to its last node, removing those
+// found absent as RFCI requires. Returns true if an edge was removed.
+func (o *pagOrientation) checkPath(path, sepset []*graph.Node) (bool, error) {
+	c := path[len(path)-1]
+	pairs := [][2]*graph.Node{}
+	for i := 0; i+1 < len(path); i++ {
+		pairs = append(pairs, [2]*graph.Node{path[i], path[i+1]})
+	}
+	for _, x := range path[1 : len(path)-2] {
+		pairs = append(pairs, [2]*graph.Node{x, c})
+	}
+	removed := false
+	for _, pair := range pairs {
+		var z []*graph.Node
+		for _, n := range sepset {
+			if n != pair[0] && n != pair[1] {
+				z = append(z, n)
+			}
+		}
+		found, err := o.removeIfSeparated(pair[0], pair[1], z)
+		if err != nil {
+			return false, err
+		}
+		removed = removed || found
+	}
+	return removed, nil
+}
+
+// removeIfSeparated removes the edge x *-* y if x and y are independent given z,
+// recording the smallest subset of z that separates them.
+func (o *pagOrientation) removeIfSeparated(x, y *graph.Node, z []*graph.Node) (bool, error) {
+	independent, err := citest.IsIndependent(o.test, x, y, z, o.options.Alpha)
+	if err != nil || !independent {
+		return false, err
+	}
+	sepset, err := minimalSepset(o.test, x, y, z, o.options.Alpha)
+	if err != nil {
+		return false, err
+	}
+	o.g.RemoveConnectingEdges(x, y)
+	o.sepsets.Set(x, y, sepset)
+	return true, nil
+}
+
+// sepset returns the recorded separating set of x and y, or searches the subsets of the
+// current neighbours of either for one.
+func (o *pagOrientation) sepset(x, y *graph.Node) ([]*graph.Node, bool, error) {
+	if z, ok := o.sepsets.Get(x, y); ok {
+		return z, true, nil
+	}
+	adjacent := map[*graph.Node][]*graph.Node{x: o.g.GetAdjacentNodes(x), y: o.g.GetAdjacentNodes(y)}
+	for depth := 0; o.options.Depth < 0 || depth <= o.options.Depth; depth++ {
+		if depth > len(adjacent[x]) && depth > len(adjacent[y]) {
+			break
+		}
+		z, found, err := findSepset(o.test, x, y, adjacent, depth, o.options.Alpha)
+		if err != nil {
+			return nil, false, err
+		}
+		if found {
+			o.sepsets.Set(x, y, z)
+			return z, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// R8: a --> b --> c or a -o b --> c, with a o-> c, gives a --> c.
+func (o *pagOrientation) rule8() bool {
+	changed := false
+	for _, a := range o.g.GetNodes() {
+		for _, c := range o.g.GetAdjacentNodes(a) {
+			if !o.isPartiallyDirected(a, c) {
+				continue
+			}
+			for _, b := range o.g.GetAdjacentNodes(a) {
+				if b == c || !o.g.IsAdjacentTo(b, c) || !o.isDirected(b, c) {
+					continue
+				}
+				if o.isDirected(a, b) || (o.endpoint(b, a) == graph.TAIL && o.endpoint(a, b) == graph.CIRCLE) {
+					o.g.SetEndpoint(c, a, graph.TAIL)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// R9: a o-> c with an uncovered potentially directed path <a, b, ..., c> from a to c on
+// which b and c are not adjacent gives a --> c.
+func (o *pagOrientation) rule9() bool {
+	changed := false
+	for _, a := range o.g.GetNodes() {
+		for _, c := range o.g.GetAdjacentNodes(a) {
+			if !o.isPartiallyDirected(a, c) {
+				continue
+			}
+			for _, b := range o.g.GetAdjacentNodes(a) {
+				if b == c || o.g.IsAdjacentTo(b, c) || !o.isPotentiallyDirected(a, b) {
+					continue
+				}
+				if o.existsUncoveredPdPath(a, b, c, c) {
+					o.g.SetEndpoint(c, a, graph.TAIL)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// R10: a o-> c with b --> c <-- d, and uncovered potentially directed paths from a to b
+// and from a to d whose second nodes m and w are distinct and non-adjacent, gives a --> c.
+func (o *pagOrientation) rule10() bool {
+	changed := false
+	for _, a := range o.g.GetNodes() {
+		for _, c := range o.g.GetAdjacentNodes(a) {
+			if !o.isPartiallyDirected(a, c) {
+				continue
+			}
+			var parents []*graph.Node
+			for _, n := range o.g.GetAdjacentNodes(c) {
+				if n != a && o.isDirected(n, c) {
+					parents = append(parents, n)
+				}
+			}
+			var starts []*graph.Node
+			for _, n := range o.g.GetAdjacentNodes(a) {
+				if n != c && o.isPotentiallyDirected(a, n) {
+					starts = append(starts, n)
+				}
+			}
+			if o.rule10Applies(a, c, parents, starts) {
+				o.g.SetEndpoint(c, a, graph.TAIL)
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func (o *pagOrientation) rule10Applies(a, c *graph.Node, parents, starts []*graph.Node) bool {
+	for i, b := range parents {
+		for _, d := range parents[i+1:] {
+			for _, m := range starts {
+				if !o.existsUncoveredPdPath(a, m, b, c) {
+					continue
+				}
+				for _, w := range starts {
+					if w != m && !o.g.IsAdjacentTo(m, w) && o.existsUncoveredPdPath(a, w, d, c) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isPartiallyDirected reports whether a o-> c.
+func (o *pagOrientation) isPartiallyDirected(a, c *graph.Node) bool {
+	return o.endpoint(c, a) == graph.CIRCLE && o.endpoint(a, c) == graph.ARROW
+}
+
+// isPotentiallyDirected reports whether the edge between a and b could be a --> b, i.e.
+// has no arrowhead at a and no tail at b.
+func (o *pagOrientation) isPotentiallyDirected(a, b *graph.Node) bool {
+	return o.endpoint(b, a) != graph.ARROW && o.endpoint(a, b) != graph.TAIL
+}
+
+// existsUncoveredPdPath reports whether an uncovered potentially directed path starting
+// with the edge from a to next leads to target without passing through avoid (unless it
+// is the target). Paths are explored by their last two nodes, which is all the
+// conditions depend on.
+func (o *pagOrientation) existsUncoveredPdPath(a, next, target, avoid *graph.Node) bool {
+	if next == target {
+		return true
+	}
+	visited := map[[2]*graph.Node]bool{{a, next}: true}
+	stack := [][2]*graph.Node{{a, next}}
+	for len(stack) > 0 {
+		step := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		previous, x := step[0], step[1]
+		for _, y := range o.g.GetAdjacentNodes(x) {
+			if y == previous || y == a || (y == avoid && y != target) || o.g.IsAdjacentTo(previous, y) || !o.isPotentiallyDirected(x, y) {
+				continue
+			}
+			if y == target {
+				return true
+			}
+			if !visited[[2]*graph.Node{x, y}] {
+				visited[[2]*graph.Node{x, y}] = true
+				stack = append(stack, [2]*graph.Node{x, y})
+			}
+		}
+	}
+	return false
+}
+
+// minimalSepset returns a smallest subset of z given which x and y are independent.
+func minimalSepset(test citest.CITest, x, y *graph.Node, z []*graph.Node, alpha float64) ([]*graph.Node, error) {
+	for k := 0; k <= len(z); k++ {
+		var sepset []*graph.Node
+		var err error
+		utils.ForEachCombination(len(z), k, func(subset []int) bool {
+			candidate := make([]*graph.Node, len(subset))
+			for i, s := range subset {
+				candidate[i] = z[s]
+			}
+			independent, testErr := citest.IsIndependent(test, x, y, candidate, alpha)
+			if testErr != nil {
+				err = testErr
+				return false
+			}
+			if independent {
+				sepset = candidate
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sepset != nil {
+			return sepset, nil
+		}
+	}
+	return z, nil
+}
+
+// reorientAllWithCircles replaces every edge of g by a o-o edge.
+func reorientAllWithCircles(g *graph.Graph) {
+	for _, e := range g.GetGraphEdges() {
+		a, b := e.GetNode1(), e.GetNode2()
+		g.RemoveConnectingEdges(a, b)
+		edge, _ := graph.NewEdge(a, b, graph.CIRCLE, graph.CIRCLE)
+		g.AddEdge(edge)
+	}
+}
+
+// orientCollider orients the triple as a *-> b <-* c.
+func orientCollider(g *graph.Graph, a, b, c *graph.Node) {
+	g.SetEndpoint(a, b, graph.ARROW)
+	g.SetEndpoint(c, b, graph.ARROW)
+}
@@ -0,0 +1,207 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"math/rand"
+	"testing"
+)
+
+// mark adds the edge a *-* b with the given endpoints at a and at b.
+func mark(t *testing.T, g *graph.Graph, a, b *graph.Node, atA, atB graph.Endpoint) {
+	t.Helper()
+	e, err := graph.NewEdge(a, b, atA, atB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.AddEdge(e)
+}
+
+// pagWith returns the orientation state of an empty graph over n nodes.
+func pagWith(n int) (*pagOrientation, []*graph.Node) {
+	nodes := newTestNodes(n)
+	g := graph.NewGraph(nodes)
+	return &pagOrientation{g: g, sepsets: NewSepsetMap(), options: DefaultPcOptions()}, nodes
+}
+
+func TestZhangRule1(t *testing.T) {
+	o, n := pagWith(3)
+	a, b, c := n[0], n[1], n[2]
+	mark(t, o.g, a, b, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, b, c, graph.CIRCLE, graph.CIRCLE)
+	if !o.rule1() || !o.isDirected(b, c) {
+		t.Errorf("a o-> b o-o c gives b --> c, got %s", o.g.GetEdge(b, c).ToString())
+	}
+	if o.endpoint(b, a) != graph.CIRCLE {
+		t.Error("R1 must not touch a o-> b")
+	}
+}
+
+func TestZhangRule2(t *testing.T) {
+	o, n := pagWith(3)
+	a, b, c := n[0], n[1], n[2]
+	mark(t, o.g, a, b, graph.TAIL, graph.ARROW)
+	mark(t, o.g, b, c, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, a, c, graph.CIRCLE, graph.CIRCLE)
+	if !o.rule2() || o.endpoint(a, c) != graph.ARROW || o.endpoint(c, a) != graph.CIRCLE {
+		t.Errorf("a --> b o-> c with a o-o c gives a o-> c, got %s", o.g.GetEdge(a, c).ToString())
+	}
+}
+
+func TestZhangRule3(t *testing.T) {
+	o, n := pagWith(4)
+	a, b, c, d := n[0], n[1], n[2], n[3]
+	mark(t, o.g, a, b, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, c, b, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, a, d, graph.CIRCLE, graph.CIRCLE)
+	mark(t, o.g, c, d, graph.CIRCLE, graph.CIRCLE)
+	mark(t, o.g, d, b, graph.CIRCLE, graph.CIRCLE)
+	if !o.rule3() || o.endpoint(d, b) != graph.ARROW || o.endpoint(b, d) != graph.CIRCLE {
+		t.Errorf("a o-> b <-o c with a o-o d o-o c gives d o-> b, got %s", o.g.GetEdge(d, b).ToString())
+	}
+}
+
+func TestZhangRule4(t *testing.T) {
+	for _, bSeparates := range []bool{true, false} {
+		// discriminating path <d, a, b, c> for b
+		o, n := pagWith(4)
+		a, b, c, d := n[0], n[1], n[2], n[3]
+		mark(t, o.g, d, a, graph.CIRCLE, graph.ARROW)
+		mark(t, o.g, b, a, graph.CIRCLE, graph.ARROW)
+		mark(t, o.g, a, c, graph.TAIL, graph.ARROW)
+		mark(t, o.g, b, c, graph.CIRCLE, graph.CIRCLE)
+		if bSeparates {
+			o.sepsets.Set(d, c, []*graph.Node{a, b})
+		} else {
+			o.sepsets.Set(d, c, []*graph.Node{a})
+		}
+		changed, err := o.rule4()
+		if err != nil || !changed {
+			t.Fatalf("R4 applies, got %v, %v", changed, err)
+		}
+		if bSeparates && !o.isDirected(b, c) {
+			t.Errorf("b in the separating set gives b --> c, got %s", o.g.GetEdge(b, c).ToString())
+		}
+		if !bSeparates && (o.endpoint(a, b) != graph.ARROW || o.endpoint(c, b) != graph.ARROW || o.endpoint(b, c) != graph.ARROW) {
+			t.Errorf("b outside the separating set gives a <-> b <-> c, got %s and %s", o.g.GetEdge(a, b).ToString(), o.g.GetEdge(b, c).ToString())
+		}
+	}
+}
+
+func TestZhangRule8(t *testing.T) {
+	cases := []struct {
+		name     string
+		atA, atB graph.Endpoint
+		applies  bool
+	}{
+		{"a --> b", graph.TAIL, graph.ARROW, true},
+		{"a -o b", graph.TAIL, graph.CIRCLE, true},
+		{"a o-- b", graph.CIRCLE, graph.TAIL, false},
+		{"a o-o b", graph.CIRCLE, graph.CIRCLE, false},
+	}
+	for _, k := range cases {
+		o, n := pagWith(3)
+		a, b, c := n[0], n[1], n[2]
+		mark(t, o.g, a, b, k.atA, k.atB)
+		mark(t, o.g, b, c, graph.TAIL, graph.ARROW)
+		mark(t, o.g, a, c, graph.CIRCLE, graph.ARROW)
+		if o.rule8() != k.applies || o.isDirected(a, c) != k.applies {
+			t.Errorf("%s --> c with a o-> c: want R8 to apply %v, got %s", k.name, k.applies, o.g.GetEdge(a, c).ToString())
+		}
+	}
+}
+
+func TestZhangRule9(t *testing.T) {
+	// uncovered potentially directed path <a, b, d, c>
+	o, n := pagWith(4)
+	a, b, c, d := n[0], n[1], n[2], n[3]
+	mark(t, o.g, a, c, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, a, b, graph.CIRCLE, graph.CIRCLE)
+	mark(t, o.g, b, d, graph.CIRCLE, graph.CIRCLE)
+	mark(t, o.g, d, c, graph.CIRCLE, graph.ARROW)
+	if !o.rule9() || !o.isDirected(a, c) {
+		t.Errorf("want a --> c, got %s", o.g.GetEdge(a, c).ToString())
+	}
+
+	// covering the path by b *-* c blocks the rule
+	o, n = pagWith(4)
+	a, b, c, d = n[0], n[1], n[2], n[3]
+	mark(t, o.g, a, c, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, a, b, graph.CIRCLE, graph.CIRCLE)
+	mark(t, o.g, b, d, graph.CIRCLE, graph.CIRCLE)
+	mark(t, o.g, d, c, graph.CIRCLE, graph.ARROW)
+	mark(t, o.g, b, c, graph.CIRCLE, graph.ARROW)
+	if o.rule9() {
+		t.Errorf("no uncovered path starts with a non-neighbour of c, got %s", o.g.GetEdge(a, c).ToString())
+	}
+}
+
+func TestZhangRule10(t *testing.T) {
+	for _, mwAdjacent := range []bool{false, true} {
+		o, n := pagWith(4)
+		a, c, b, d := n[0], n[1], n[2], n[3]
+		mark(t, o.g, a, c, graph.CIRCLE, graph.ARROW)
+		mark(t, o.g, b, c, graph.TAIL, graph.ARROW)
+		mark(t, o.g, d, c, graph.TAIL, graph.ARROW)
+		mark(t, o.g, a, b, graph.CIRCLE, graph.ARROW)
+		mark(t, o.g, a, d, graph.CIRCLE, graph.ARROW)
+		if mwAdjacent {
+			mark(t, o.g, b, d, graph.CIRCLE, graph.CIRCLE)
+		}
+		if o.rule10() != !mwAdjacent || o.isDirected(a, c) != !mwAdjacent {
+			t.Errorf("b and d adjacent %v: got %s", mwAdjacent, o.g.GetEdge(a, c).ToString())
+		}
+	}
+}
+
+// checkPagAgainstMag verifies that every non-circle mark of the PAG is the mark of the
+// MAG, and, if exact, that the adjacencies agree.
+func checkPagAgainstMag(t *testing.T, name string, mag, pag *graph.Graph, exact bool) {
+	t.Helper()
+	nodes := mag.GetNodes()
+	for i, x := range nodes {
+		for _, y := range nodes[i+1:] {
+			if mag.IsAdjacentTo(x, y) != pag.IsAdjacentTo(x, y) {
+				if exact || mag.IsAdjacentTo(x, y) {
+					t.Fatalf("%s: adjacency of %s and %s is %v in the MAG\nMAG\n%s\nPAG\n%s",
+						name, x.GetName(), y.GetName(), mag.IsAdjacentTo(x, y), mag.ToString(), pag.ToString())
+				}
+				continue
+			}
+			if !mag.IsAdjacentTo(x, y) {
+				continue
+			}
+			for _, pair := range [][2]*graph.Node{{x, y}, {y, x}} {
+				got := pag.GetEndpoint(pair[0], pair[1])
+				if got != graph.CIRCLE && got != mag.GetEndpoint(pair[0], pair[1]) {
+					t.Fatalf("%s: mark at %s of %s *-* %s is not the MAG's\nMAG\n%s\nPAG\n%s",
+						name, pair[1].GetName(), pair[0].GetName(), pair[1].GetName(), mag.ToString(), pag.ToString())
+				}
+			}
+		}
+	}
+}
+
+func TestFciAndRfciWithOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for trial := 0; trial < 60; trial++ {
+		n := 5 + rng.Intn(4)
+		dag := randomDag(rng, n, 0.25+0.3*rng.Float64())
+		nodes := dag.GetNodes()
+		perm := rng.Perm(n)
+		latents := []*graph.Node{nodes[perm[0]], nodes[perm[1]]}
+		mag, err := graph.DagToMag(dag, latents, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pag, err := Fci(newSeparationOracle(mag), DefaultPcOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPagAgainstMag(t, "FCI", mag, pag, true)
+		rfci, err := Rfci(newSeparationOracle(mag), DefaultPcOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPagAgainstMag(t, "RFCI", mag, rfci, false)
+	}
+}
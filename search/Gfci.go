@@ -0,0 +1,76 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+	"GoCausal/score"
+	"fmt"
+)
+
+/*
+Gfci
+
+Runs GFCI (Ogarrio et al., 2016): GES with the score gives a first graph, whose
+adjacencies are then pruned by the adjacency search of PC with the test, configured by
+options, and the result is returned as a PAG. The score and the test must cover the
+same variables. An unshielded triple a o-o b o-o c is oriented as a collider if it is
+one in the GES pattern, or if a and c are adjacent there and b is not in the separating
+set that removed their edge; a GES collider whose middle node separates its ends is
+left unoriented and recorded as ambiguous on the graph, the other unshielded triples as
+underlines. For the ends of a GES collider, which the adjacency search never separated,
+a separating set is searched among their neighbours. The rules of FCI then complete the
+orientation.
+*/
+func Gfci(test citest.CITest, s score.Score, options PcOptions) (*graph.Graph, error) {
+	pattern, err := Ges(s)
+	if err != nil {
+		return nil, err
+	}
+	nodes := test.GetVariables()
+	if len(nodes) != pattern.GetNumNodes() {
+		return nil, fmt.Errorf("the test covers %d variables but the score %d", len(nodes), pattern.GetNumNodes())
+	}
+	g := graph.NewGraph(append([]*graph.Node{}, nodes...))
+	for _, n := range nodes {
+		if !pattern.ContainsNode(n) {
+			return nil, fmt.Errorf("variable %s is not covered by the score", n.GetName())
+		}
+	}
+	for _, e := range pattern.GetGraphEdges() {
+		g.AddEdge(graph.UndirectedEdge(e.GetNode1(), e.GetNode2()))
+	}
+	sepsets := NewSepsetMap()
+	if err := pruneSkeleton(test, g, sepsets, options); err != nil {
+		return nil, err
+	}
+	reorientAllWithCircles(g)
+
+	o := &pagOrientation{g: g, sepsets: sepsets, test: test, options: options}
+	for _, t := range unshieldedTriples(g) {
+		a, b, c := t.GetX(), t.GetY(), t.GetZ()
+		switch {
+		case pattern.IsDirectedFromTo(a, b) && pattern.IsDirectedFromTo(c, b) && !pattern.IsAdjacentTo(a, c):
+			// a and c were never adjacent, so the adjacency search recorded no separating
+			// set for them
+			_, found, err := o.sepset(a, c)
+			if err != nil {
+				return nil, err
+			}
+			if found && sepsets.Separates(a, c, b) {
+				g.AddAmbiguousTriple(t)
+			} else {
+				orientCollider(g, a, b, c)
+			}
+		case pattern.IsAdjacentTo(a, c) && !sepsets.Separates(a, c, b):
+			orientCollider(g, a, b, c)
+		default:
+			g.AddUnderlineTriple(t)
+		}
+	}
+
+	if err := o.run(); err != nil {
+		return nil, err
+	}
+	g.SetPag(true)
+	return g, nil
+}
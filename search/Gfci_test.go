@@ -0,0 +1,79 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+	"GoCausal/score"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+func TestGfciOrientsCollider(t *testing.T) {
+	// X1 --> X3 <-- X2 and X3 --> X4
+	rng := rand.New(rand.NewSource(46))
+	nodes := newTestNodes(4)
+	samples := 5000
+	data := mat.NewDense(samples, 4, nil)
+	for s := 0; s < samples; s++ {
+		x1, x2 := rng.NormFloat64(), rng.NormFloat64()
+		x3 := 0.8*x1 - 0.7*x2 + rng.NormFloat64()
+		x4 := 0.9*x3 + rng.NormFloat64()
+		data.SetRow(s, []float64{x1, x2, x3, x4})
+	}
+	test, err := citest.NewFisherZ(nodes, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bic, err := score.NewBic(nodes, data, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pag, err := Gfci(test, bic, DefaultPcOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x1, x2, x3, x4 := nodes[0], nodes[1], nodes[2], nodes[3]
+	if pag.GetEndpoint(x1, x3) != graph.ARROW || pag.GetEndpoint(x2, x3) != graph.ARROW {
+		t.Errorf("want X1 *-> X3 <-* X2, got\n%s", pag.ToString())
+	}
+	if pag.GetEndpoint(x4, x3) != graph.TAIL || pag.GetEndpoint(x3, x4) != graph.ARROW {
+		t.Errorf("want X3 --> X4, got\n%s", pag.ToString())
+	}
+	if pag.IsAdjacentTo(x1, x2) || pag.IsAdjacentTo(x1, x4) || pag.IsAdjacentTo(x2, x4) {
+		t.Errorf("unexpected adjacency in\n%s", pag.ToString())
+	}
+	if len(pag.GetAmbiguousTriples()) != 0 {
+		t.Errorf("X3 does not separate X1 and X2, got ambiguous triples %v", pag.GetAmbiguousTriples())
+	}
+}
+
+func TestGfciLeavesSeparatedGesColliderAmbiguous(t *testing.T) {
+	// the score sees X1 --> X2 <-- X3 while the test follows X1 --> X2 --> X3
+	rng := rand.New(rand.NewSource(46))
+	nodes := newTestNodes(3)
+	x1, x2, x3 := nodes[0], nodes[1], nodes[2]
+	samples := 2000
+	data := mat.NewDense(samples, 3, nil)
+	for s := 0; s < samples; s++ {
+		a, c := rng.NormFloat64(), rng.NormFloat64()
+		data.SetRow(s, []float64{a, 0.8*a + 0.8*c + rng.NormFloat64(), c})
+	}
+	bic, err := score.NewBic(nodes, data, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := graph.NewGraph(nodes)
+	chain.AddDirectedEdge(x1, x2)
+	chain.AddDirectedEdge(x2, x3)
+	pag, err := Gfci(newSeparationOracle(chain), bic, DefaultPcOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pag.GetEndpoint(x1, x2) != graph.CIRCLE || pag.GetEndpoint(x3, x2) != graph.CIRCLE {
+		t.Errorf("X2 separates X1 and X3, want X1 o-o X2 o-o X3, got\n%s", pag.ToString())
+	}
+	if !pag.IsAmbiguousTriple(graph.NewTriple(x1, x2, x3)) && !pag.IsAmbiguousTriple(graph.NewTriple(x3, x2, x1)) {
+		t.Errorf("want the ambiguous triple X1, X2, X3, got %v", pag.GetAmbiguousTriples())
+	}
+}
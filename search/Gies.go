@@ -0,0 +1,365 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"GoCausal/score"
+	"GoCausal/utils"
+	"fmt"
+	"sort"
+)
+
+// smallest score gain for a step to be taken, which keeps ties from cycling
+const minImprovement = 1e-9
+
+/*
+Ges
+
+Runs greedy equivalence search (Chickering, 2002) over the variables of the score, see
+Gies, on observational data. Returns the pattern of the best DAG found.
+*/
+func Ges(s score.Score) (*graph.Graph, error) {
+	return Gies(s, nil)
+}
+
+/*
+Gies
+
+Runs greedy interventional equivalence search (Hauser & Bühlmann, 2012) over the
+variables of the score, which should only use, for every node, the samples where it was
+not intervened on (see score.NewInterventionalBic). targets holds the intervened nodes
+of each experimental setting, an empty slice for observational data.
+
+Starting from the empty graph, the search moves between interventional essential graphs
+(see graph.DagToInterventionalCpdag): the forward phase takes the best edge insertion
+while the score improves, the backward phase the best edge deletion, and the turning
+phase the best edge reversal in a representative DAG; the phases repeat until none of
+them improves the score. Insertions and deletions use the operators of GES and their
+validity conditions, which carry over to interventional essential graphs.
+*/
+func Gies(s score.Score, targets [][]*graph.Node) (*graph.Graph, error) {
+	local := newLocalScores(s)
+	g := graph.NewGraph(append([]*graph.Node{}, s.GetVariables()...))
+	steps := []func(*graph.Graph, *localScores) (*graph.Graph, error){forwardStep, backwardStep, turningStep}
+	for {
+		improved := false
+		for _, step := range steps {
+			for {
+				next, err := step(g, local)
+				if err != nil {
+					return nil, err
+				}
+				if next == nil {
+					break
+				}
+				if g, err = essentialGraph(next, targets); err != nil {
+					return nil, err
+				}
+				improved = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	g.SetPattern(true)
+	return g, nil
+}
+
+// essentialGraph returns the interventional essential graph of a consistent extension
+// of the partially directed graph.
+func essentialGraph(pdag *graph.Graph, targets [][]*graph.Node) (*graph.Graph, error) {
+	dag, err := graph.PdagToDag(pdag)
+	if err != nil {
+		return nil, fmt.Errorf("search step left no consistent extension: %v", err)
+	}
+	return graph.DagToInterventionalCpdag(dag, targets)
+}
+
+/*
+forwardStep
+
+Returns the graph after the best valid Insert(x, y, T) of Chickering (2002), or nil if
+none improves the score: x --> y is added and every y -- t with t in T becomes t --> y,
+where T is a set of undirected neighbours of y not adjacent to x. The operator is valid
+if NA(y, x) ∪ T, with NA(y, x) the undirected neighbours of y adjacent to x, is a clique
+and every semi-directed path from y to x meets it.
+*/
+func forwardStep(g *graph.Graph, local *localScores) (*graph.Graph, error) {
+	best := minImprovement
+	var bestX, bestY *graph.Node
+	var bestT []*graph.Node
+	for _, x := range g.GetNodes() {
+		for _, y := range g.GetNodes() {
+			if x == y || g.IsAdjacentTo(x, y) {
+				continue
+			}
+			var na, candidates []*graph.Node
+			for _, n := range g.GetAdjacentNodes(y) {
+				if !g.IsUndirectedFromTo(n, y) {
+					continue
+				}
+				if g.IsAdjacentTo(n, x) {
+					na = append(na, n)
+				} else {
+					candidates = append(candidates, n)
+				}
+			}
+			parents := g.GetParents(y)
+			var err error
+			forEachSubset(candidates, func(t []*graph.Node) bool {
+				set := append(append([]*graph.Node{}, na...), t...)
+				if !isClique(g, set) || existsSemiDirectedPath(g, y, x, set) {
+					return true
+				}
+				base := append(append([]*graph.Node{}, parents...), set...)
+				with, e := local.get(y, append(base, x))
+				if e != nil {
+					err = e
+					return false
+				}
+				without, e := local.get(y, base)
+				if e != nil {
+					err = e
+					return false
+				}
+				if with-without > best {
+					best, bestX, bestY, bestT = with-without, x, y, append([]*graph.Node{}, t...)
+				}
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if bestX == nil {
+		return nil, nil
+	}
+	next := g.Copy()
+	next.AddDirectedEdge(bestX, bestY)
+	for _, t := range bestT {
+		next.RemoveConnectingEdges(t, bestY)
+		next.AddDirectedEdge(t, bestY)
+	}
+	return next, nil
+}
+
+/*
+backwardStep
+
+Returns the graph after the best valid Delete(x, y, H) of Chickering (2002), or nil if
+none improves the score: the edge x --> y or x -- y is removed and, for every h in H, a
+set of undirected neighbours of y adjacent to x, the edges y -- h and x -- h become
+y --> h and x --> h. The operator is valid if NA(y, x) \ H is a clique.
+*/
+func backwardStep(g *graph.Graph, local *localScores) (*graph.Graph, error) {
+	best := minImprovement
+	var bestX, bestY *graph.Node
+	var bestH []*graph.Node
+	for _, x := range g.GetNodes() {
+		for _, y := range g.GetNodes() {
+			if !g.IsDirectedFromTo(x, y) && !g.IsUndirectedFromTo(x, y) {
+				continue
+			}
+			var na []*graph.Node
+			for _, n := range g.GetAdjacentNodes(y) {
+				if n != x && g.IsUndirectedFromTo(n, y) && g.IsAdjacentTo(n, x) {
+					na = append(na, n)
+				}
+			}
+			var parents []*graph.Node
+			for _, p := range g.GetParents(y) {
+				if p != x {
+					parents = append(parents, p)
+				}
+			}
+			var err error
+			forEachSubset(na, func(h []*graph.Node) bool {
+				removed := utils.NewSet(h...)
+				var rest []*graph.Node
+				for _, n := range na {
+					if !removed.Contains(n) {
+						rest = append(rest, n)
+					}
+				}
+				if !isClique(g, rest) {
+					return true
+				}
+				base := append(append([]*graph.Node{}, parents...), rest...)
+				with, e := local.get(y, append(base, x))
+				if e != nil {
+					err = e
+					return false
+				}
+				without, e := local.get(y, base)
+				if e != nil {
+					err = e
+					return false
+				}
+				if without-with > best {
+					best, bestX, bestY, bestH = without-with, x, y, append([]*graph.Node{}, h...)
+				}
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if bestX == nil {
+		return nil, nil
+	}
+	next := g.Copy()
+	next.RemoveConnectingEdges(bestX, bestY)
+	for _, h := range bestH {
+		for _, n := range []*graph.Node{bestY, bestX} {
+			if next.IsUndirectedFromTo(n, h) {
+				next.RemoveConnectingEdges(n, h)
+				next.AddDirectedEdge(n, h)
+			}
+		}
+	}
+	return next, nil
+}
+
+/*
+turningStep
+
+Returns a DAG consistent with the graph in which the edge whose reversal improves the
+score most is reversed, or nil if no reversal keeping the graph acyclic improves it.
+*/
+func turningStep(g *graph.Graph, local *localScores) (*graph.Graph, error) {
+	dag, err := graph.PdagToDag(g)
+	if err != nil {
+		return nil, err
+	}
+	best := minImprovement
+	var bestA, bestB *graph.Node
+	for _, e := range dag.GetGraphEdges() {
+		a, b := e.GetNode1(), e.GetNode2()
+		if !dag.IsDirectedFromTo(a, b) {
+			a, b = b, a
+		}
+		parentsA, parentsB := dag.GetParents(a), dag.GetParents(b)
+		var rest []*graph.Node
+		for _, p := range parentsB {
+			if p != a {
+				rest = append(rest, p)
+			}
+		}
+		var delta float64
+		for _, term := range []struct {
+			node    *graph.Node
+			parents []*graph.Node
+			sign    float64
+		}{{a, append(append([]*graph.Node{}, parentsA...), b), 1}, {b, rest, 1}, {a, parentsA, -1}, {b, parentsB, -1}} {
+			v, err := local.get(term.node, term.parents)
+			if err != nil {
+				return nil, err
+			}
+			delta += term.sign * v
+		}
+		if delta <= best {
+			continue
+		}
+		reversed := dag.Copy()
+		reversed.RemoveConnectingEdges(a, b)
+		if graph.ExistsDirectedPathFromToBreadthFirst(a, b, reversed) {
+			continue
+		}
+		best, bestA, bestB = delta, a, b
+	}
+	if bestA == nil {
+		return nil, nil
+	}
+	dag.RemoveConnectingEdges(bestA, bestB)
+	dag.AddDirectedEdge(bestB, bestA)
+	return dag, nil
+}
+
+func isClique(g *graph.Graph, nodes []*graph.Node) bool {
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			if !g.IsAdjacentTo(a, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// existsSemiDirectedPath reports whether a path of edges directed forward or undirected
+// leads from one node to the other while avoiding the blocked nodes.
+func existsSemiDirectedPath(g *graph.Graph, from, to *graph.Node, blocked []*graph.Node) bool {
+	visited := utils.NewSet(blocked...)
+	visited.Add(from)
+	q := utils.NewQueue(from)
+	for q.Size() > 0 {
+		n := q.Pop()
+		for _, m := range g.GetAdjacentNodes(n) {
+			if !g.IsDirectedFromTo(n, m) && !g.IsUndirectedFromTo(n, m) {
+				continue
+			}
+			if m == to {
+				return true
+			}
+			if visited.Add(m) {
+				q.Append(m)
+			}
+		}
+	}
+	return false
+}
+
+// forEachSubset calls f with every subset of nodes, by increasing size, until f returns
+// false.
+func forEachSubset(nodes []*graph.Node, f func([]*graph.Node) bool) {
+	subset := make([]*graph.Node, 0, len(nodes))
+	for k := 0; k <= len(nodes); k++ {
+		more := true
+		utils.ForEachCombination(len(nodes), k, func(indices []int) bool {
+			subset = subset[:0]
+			for _, i := range indices {
+				subset = append(subset, nodes[i])
+			}
+			more = f(subset)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// localScores caches the local scores of a score by node and parent set.
+type localScores struct {
+	score  score.Score
+	index  map[*graph.Node]int
+	values map[string]float64
+}
+
+func newLocalScores(s score.Score) *localScores {
+	l := &localScores{score: s, index: map[*graph.Node]int{}, values: map[string]float64{}}
+	for i, v := range s.GetVariables() {
+		l.index[v] = i
+	}
+	return l
+}
+
+func (l *localScores) get(node *graph.Node, parents []*graph.Node) (float64, error) {
+	key := make([]int, len(parents))
+	for i, p := range parents {
+		key[i] = l.index[p]
+	}
+	sort.Ints(key)
+	k := fmt.Sprint(l.index[node], key)
+	if v, ok := l.values[k]; ok {
+		return v, nil
+	}
+	v, err := l.score.LocalScore(node, parents)
+	if err != nil {
+		return 0, err
+	}
+	l.values[k] = v
+	return v, nil
+}
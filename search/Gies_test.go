@@ -0,0 +1,85 @@
+package search
+
+import (
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"GoCausal/score"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+// simulateBic returns a random DAG, a BIC score of data sampled from a random linear
+// SEM on it, and the data.
+func simulateBic(t *testing.T, rng *rand.Rand, n, edges, samples int) (*graph.Graph, *score.Bic, *mat.Dense) {
+	t.Helper()
+	dag, err := graph.RandomDag(newTestNodes(n), edges, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sem, err := estimate.RandomLinearSEM(dag, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := sem.Simulate(samples, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bic, err := score.NewBic(dag.GetNodes(), data, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dag, bic, data
+}
+
+func TestGesRecoversCpdag(t *testing.T) {
+	rng := rand.New(rand.NewSource(43))
+	for trial := 0; trial < 5; trial++ {
+		dag, bic, _ := simulateBic(t, rng, 6, 6, 20000)
+		cpdag, err := graph.DagToCpdag(dag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pattern, err := Ges(bic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sameMarks(cpdag, pattern); err != nil {
+			t.Fatalf("%v\nDAG\n%s\nGES\n%s", err, dag.ToString(), pattern.ToString())
+		}
+	}
+}
+
+func TestGiesOrientsChainWithIntervention(t *testing.T) {
+	rng := rand.New(rand.NewSource(43))
+	nodes := newTestNodes(3)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	samples := 6000
+	data := mat.NewDense(samples, 3, nil)
+	settings := make([]int, samples)
+	for s := 0; s < samples; s++ {
+		va := rng.NormFloat64()
+		vb := 0.8*va + rng.NormFloat64()
+		if s%2 == 1 {
+			// do(b): b no longer listens to a
+			settings[s] = 1
+			vb = 2 + rng.NormFloat64()
+		}
+		vc := -0.9*vb + rng.NormFloat64()
+		data.SetRow(s, []float64{va, vb, vc})
+	}
+	targets := [][]*graph.Node{nil, {b}}
+	interventions := &score.Interventions{Variables: nodes, Data: data, Targets: targets, Settings: settings}
+	bic, err := score.NewInterventionalBic(interventions, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Gies(bic, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.IsDirectedFromTo(a, b) || !g.IsDirectedFromTo(b, c) || g.IsAdjacentTo(a, c) {
+		t.Errorf("want a --> b --> c, got\n%s", g.ToString())
+	}
+
+}
@@ -0,0 +1,423 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"GoCausal/score"
+	"fmt"
+	"math/rand"
+)
+
+/*
+HillClimbOptions
+
+MaxParents caps the number of parents of every node; a negative value means no cap.
+Beware that the zero value therefore allows no parents at all and returns the empty
+graph; start from DefaultHillClimbOptions.
+Knowledge, which may be nil, lists required and forbidden edges; the required ones form
+the starting DAG. After the first local optimum, each of Restarts restarts applies
+Perturbations random moves (zero means the number of variables) to the best DAG found so
+far and searches again from there, drawing from Rng, which is required for restarts.
+*/
+type HillClimbOptions struct {
+	MaxParents    int
+	Knowledge     *Knowledge
+	Restarts      int
+	Perturbations int
+	Rng           *rand.Rand
+}
+
+/*
+DefaultHillClimbOptions
+
+Returns options without a cap on the number of parents, knowledge or restarts.
+*/
+func DefaultHillClimbOptions() HillClimbOptions {
+	return HillClimbOptions{MaxParents: -1}
+}
+
+/*
+TabuOptions
+
+The options of the hill climbing, plus: moves touching a pair of nodes changed in the
+last TabuLength moves are not taken unless they give the best score yet (zero means 10);
+the search stops after Patience moves without improving the best score (zero means 20)
+or after MaxIterations moves (zero means 1000).
+*/
+type TabuOptions struct {
+	HillClimbOptions
+	TabuLength    int
+	Patience      int
+	MaxIterations int
+}
+
+/*
+DefaultTabuOptions
+
+Returns DefaultHillClimbOptions with the default tabu length, patience and number of
+moves.
+*/
+func DefaultTabuOptions() TabuOptions {
+	return TabuOptions{HillClimbOptions: DefaultHillClimbOptions(), TabuLength: 10, Patience: 20, MaxIterations: 1000}
+}
+
+/*
+HillClimb
+
+Runs greedy hill climbing over DAGs: starting from the required edges, the best of all
+single edge additions, deletions and reversals that keep the graph acyclic and respect
+the options is applied until none improves the score. Returns the best DAG found.
+*/
+func HillClimb(s score.Score, options HillClimbOptions) (*graph.Graph, error) {
+	d, err := newDagSearch(s, options)
+	if err != nil {
+		return nil, err
+	}
+	return d.run(d.climb)
+}
+
+/*
+TabuSearch
+
+Runs tabu search over DAGs: like HillClimb, but the best allowed move is applied even if
+it lowers the score, which lets the search leave local optima, and recently changed
+pairs of nodes are tabu so that it does not move straight back. Returns the best DAG
+visited.
+*/
+func TabuSearch(s score.Score, options TabuOptions) (*graph.Graph, error) {
+	d, err := newDagSearch(s, options.HillClimbOptions)
+	if err != nil {
+		return nil, err
+	}
+	if options.TabuLength <= 0 {
+		options.TabuLength = 10
+	}
+	if options.Patience <= 0 {
+		options.Patience = 20
+	}
+	if options.MaxIterations <= 0 {
+		options.MaxIterations = 1000
+	}
+	return d.run(func(adj [][]bool) ([][]bool, float64, error) {
+		return d.tabu(adj, options)
+	})
+}
+
+const (
+	addEdge = iota
+	deleteEdge
+	reverseEdge
+)
+
+// move changes the edge between from and to, which for deletions and reversals is the
+// edge from --> to.
+type move struct {
+	kind     int
+	from, to int
+}
+
+// dagSearch holds a DAG over the variables of the score as an adjacency matrix,
+// adj[i][j] meaning i --> j.
+type dagSearch struct {
+	nodes   []*graph.Node
+	local   *localScores
+	options HillClimbOptions
+}
+
+func newDagSearch(s score.Score, options HillClimbOptions) (*dagSearch, error) {
+	if options.Restarts > 0 && options.Rng == nil {
+		return nil, fmt.Errorf("random restarts need a random number generator")
+	}
+	if options.Perturbations <= 0 {
+		options.Perturbations = len(s.GetVariables())
+	}
+	return &dagSearch{nodes: s.GetVariables(), local: newLocalScores(s), options: options}, nil
+}
+
+// run searches from the required edges, then from perturbations of the best DAG found.
+func (d *dagSearch) run(search func([][]bool) ([][]bool, float64, error)) (*graph.Graph, error) {
+	start, err := d.initial()
+	if err != nil {
+		return nil, err
+	}
+	best, bestScore, err := search(start)
+	if err != nil {
+		return nil, err
+	}
+	for r := 0; r < d.options.Restarts; r++ {
+		adj := copyAdjacency(best)
+		for k := 0; k < d.options.Perturbations; k++ {
+			moves := d.moves(adj)
+			if len(moves) == 0 {
+				break
+			}
+			d.apply(adj, moves[d.options.Rng.Intn(len(moves))])
+		}
+		adj, total, err := search(adj)
+		if err != nil {
+			return nil, err
+		}
+		if total > bestScore+minImprovement {
+			best, bestScore = adj, total
+		}
+	}
+	g := graph.NewGraph(append([]*graph.Node{}, d.nodes...))
+	for i := range best {
+		for j := range best[i] {
+			if best[i][j] {
+				g.AddDirectedEdge(d.nodes[i], d.nodes[j])
+			}
+		}
+	}
+	return g, nil
+}
+
+func (d *dagSearch) initial() ([][]bool, error) {
+	index := map[*graph.Node]int{}
+	for i, n := range d.nodes {
+		index[n] = i
+	}
+	adj := make([][]bool, len(d.nodes))
+	for i := range adj {
+		adj[i] = make([]bool, len(d.nodes))
+	}
+	for _, edge := range d.options.Knowledge.GetRequired() {
+		i, ok1 := index[edge[0]]
+		j, ok2 := index[edge[1]]
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("required edge %s --> %s is not over the variables of the score", edge[0].GetName(), edge[1].GetName())
+		}
+		adj[i][j] = true
+	}
+	for j := range adj {
+		if d.options.MaxParents >= 0 && len(parentIndices(adj, j)) > d.options.MaxParents {
+			return nil, fmt.Errorf("%s has more required parents than allowed", d.nodes[j].GetName())
+		}
+		if hasPath(adj, j, j) {
+			return nil, fmt.Errorf("required edges form a cycle through %s", d.nodes[j].GetName())
+		}
+	}
+	return adj, nil
+}
+
+// climb applies the best improving move until there is none.
+func (d *dagSearch) climb(adj [][]bool) ([][]bool, float64, error) {
+	total, err := d.score(adj)
+	if err != nil {
+		return nil, 0, err
+	}
+	for {
+		var best *move
+		bestDelta := minImprovement
+		for _, m := range d.moves(adj) {
+			delta, err := d.delta(adj, m)
+			if err != nil {
+				return nil, 0, err
+			}
+			if delta > bestDelta {
+				m := m
+				best, bestDelta = &m, delta
+			}
+		}
+		if best == nil {
+			return adj, total, nil
+		}
+		d.apply(adj, *best)
+		total += bestDelta
+	}
+}
+
+// tabu applies the best allowed move, improving or not, until the search stalls.
+func (d *dagSearch) tabu(adj [][]bool, options TabuOptions) ([][]bool, float64, error) {
+	total, err := d.score(adj)
+	if err != nil {
+		return nil, 0, err
+	}
+	best, bestScore := copyAdjacency(adj), total
+	expires := map[[2]int]int{}
+	stale := 0
+	for iteration := 0; iteration < options.MaxIterations && stale < options.Patience; iteration++ {
+		var chosen *move
+		var chosenDelta float64
+		for _, m := range d.moves(adj) {
+			delta, err := d.delta(adj, m)
+			if err != nil {
+				return nil, 0, err
+			}
+			pair := [2]int{m.from, m.to}
+			if pair[0] > pair[1] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if expires[pair] > iteration && total+delta <= bestScore+minImprovement {
+				continue
+			}
+			if chosen == nil || delta > chosenDelta {
+				m := m
+				chosen, chosenDelta = &m, delta
+			}
+		}
+		if chosen == nil {
+			break
+		}
+		d.apply(adj, *chosen)
+		total += chosenDelta
+		pair := [2]int{chosen.from, chosen.to}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		expires[pair] = iteration + 1 + options.TabuLength
+		if total > bestScore+minImprovement {
+			best, bestScore, stale = copyAdjacency(adj), total, 0
+		} else {
+			stale++
+		}
+	}
+	return best, bestScore, nil
+}
+
+// moves returns the additions, deletions and reversals allowed from adj.
+func (d *dagSearch) moves(adj [][]bool) []move {
+	knowledge, maxParents := d.options.Knowledge, d.options.MaxParents
+	var moves []move
+	for i := range adj {
+		for j := range adj {
+			if i == j {
+				continue
+			}
+			from, to := d.nodes[i], d.nodes[j]
+			switch {
+			case adj[i][j]:
+				if knowledge.IsRequired(from, to) {
+					continue
+				}
+				moves = append(moves, move{deleteEdge, i, j})
+				if knowledge.IsForbidden(to, from) || (maxParents >= 0 && len(parentIndices(adj, i)) >= maxParents) {
+					continue
+				}
+				adj[i][j] = false
+				if !hasPath(adj, i, j) {
+					moves = append(moves, move{reverseEdge, i, j})
+				}
+				adj[i][j] = true
+			case !adj[j][i]:
+				if knowledge.IsForbidden(from, to) || (maxParents >= 0 && len(parentIndices(adj, j)) >= maxParents) {
+					continue
+				}
+				if !hasPath(adj, j, i) {
+					moves = append(moves, move{addEdge, i, j})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// delta returns the change of score the move brings about.
+func (d *dagSearch) delta(adj [][]bool, m move) (float64, error) {
+	before, err := d.nodeScore(adj, m.to)
+	if err != nil {
+		return 0, err
+	}
+	if m.kind == reverseEdge {
+		other, err := d.nodeScore(adj, m.from)
+		if err != nil {
+			return 0, err
+		}
+		before += other
+	}
+	d.apply(adj, m)
+	after, err := d.nodeScore(adj, m.to)
+	if err == nil && m.kind == reverseEdge {
+		var other float64
+		other, err = d.nodeScore(adj, m.from)
+		after += other
+	}
+	d.undo(adj, m)
+	if err != nil {
+		return 0, err
+	}
+	return after - before, nil
+}
+
+func (d *dagSearch) apply(adj [][]bool, m move) {
+	switch m.kind {
+	case addEdge:
+		adj[m.from][m.to] = true
+	case deleteEdge:
+		adj[m.from][m.to] = false
+	case reverseEdge:
+		adj[m.from][m.to], adj[m.to][m.from] = false, true
+	}
+}
+
+func (d *dagSearch) undo(adj [][]bool, m move) {
+	switch m.kind {
+	case addEdge:
+		adj[m.from][m.to] = false
+	case deleteEdge:
+		adj[m.from][m.to] = true
+	case reverseEdge:
+		adj[m.from][m.to], adj[m.to][m.from] = true, false
+	}
+}
+
+func (d *dagSearch) nodeScore(adj [][]bool, j int) (float64, error) {
+	var parents []*graph.Node
+	for _, i := range parentIndices(adj, j) {
+		parents = append(parents, d.nodes[i])
+	}
+	return d.local.get(d.nodes[j], parents)
+}
+
+func (d *dagSearch) score(adj [][]bool) (float64, error) {
+	total := 0.0
+	for j := range adj {
+		v, err := d.nodeScore(adj, j)
+		if err != nil {
+			return 0, err
+		}
+		total += v
+	}
+	return total, nil
+}
+
+func parentIndices(adj [][]bool, j int) []int {
+	var parents []int
+	for i := range adj {
+		if adj[i][j] {
+			parents = append(parents, i)
+		}
+	}
+	return parents
+}
+
+// hasPath reports whether a directed path of at least one edge leads from one node to
+// the other.
+func hasPath(adj [][]bool, from, to int) bool {
+	visited := make([]bool, len(adj))
+	stack := []int{from}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for j, edge := range adj[i] {
+			if !edge {
+				continue
+			}
+			if j == to {
+				return true
+			}
+			if !visited[j] {
+				visited[j] = true
+				stack = append(stack, j)
+			}
+		}
+	}
+	return false
+}
+
+func copyAdjacency(adj [][]bool) [][]bool {
+	result := make([][]bool, len(adj))
+	for i := range adj {
+		result[i] = append([]bool{}, adj[i]...)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"GoCausal/score"
+	"math/rand"
+	"testing"
+)
+
+func TestHillClimbZeroOptionsAllowNoParents(t *testing.T) {
+	rng := rand.New(rand.NewSource(44))
+	_, bic, _ := simulateBic(t, rng, 5, 5, 2000)
+	g, err := HillClimb(bic, HillClimbOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.GetGraphEdges()) != 0 {
+		t.Errorf("MaxParents 0 allows no edges, got\n%s", g.ToString())
+	}
+	g, err = HillClimb(bic, DefaultHillClimbOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.GetGraphEdges()) == 0 {
+		t.Error("the default options find edges")
+	}
+}
+
+func TestHillClimbRespectsKnowledge(t *testing.T) {
+	rng := rand.New(rand.NewSource(44))
+	dag, bic, _ := simulateBic(t, rng, 5, 5, 2000)
+	edges := dag.GetGraphEdges()
+	forbidden := edges[0]
+	var required [2]*graph.Node
+	for _, a := range dag.GetNodes() {
+		for _, b := range dag.GetNodes() {
+			if a != b && !dag.IsAdjacentTo(a, b) && !dag.IsAncestorOf(b, a) {
+				required = [2]*graph.Node{a, b}
+			}
+		}
+	}
+	knowledge := NewKnowledge()
+	if err := knowledge.SetForbidden(forbidden.GetNode1(), forbidden.GetNode2()); err != nil {
+		t.Fatal(err)
+	}
+	if err := knowledge.SetRequired(required[0], required[1]); err != nil {
+		t.Fatal(err)
+	}
+	options := DefaultTabuOptions()
+	options.Knowledge = knowledge
+	for name, search := range map[string]func() (*graph.Graph, error){
+		"hill climbing": func() (*graph.Graph, error) { return HillClimb(bic, options.HillClimbOptions) },
+		"tabu search":   func() (*graph.Graph, error) { return TabuSearch(bic, options) },
+	} {
+		g, err := search()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.IsDirectedFromTo(forbidden.GetNode1(), forbidden.GetNode2()) {
+			t.Errorf("%s: forbidden edge %s was added", name, forbidden.ToString())
+		}
+		if !g.IsDirectedFromTo(required[0], required[1]) {
+			t.Errorf("%s: required edge %s --> %s is missing", name, required[0].GetName(), required[1].GetName())
+		}
+		if !graph.IsDag(g) {
+			t.Errorf("%s: result is not a DAG", name)
+		}
+	}
+}
+
+func TestHillClimbRestartsAreReproducible(t *testing.T) {
+	rng := rand.New(rand.NewSource(44))
+	_, bic, _ := simulateBic(t, rng, 7, 9, 1000)
+	plain, err := HillClimb(bic, DefaultHillClimbOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainScore, err := score.ScoreDag(bic, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []*graph.Graph
+	for k := 0; k < 2; k++ {
+		options := DefaultHillClimbOptions()
+		options.Restarts = 5
+		options.Rng = rand.New(rand.NewSource(7))
+		g, err := HillClimb(bic, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restartScore, err := score.ScoreDag(bic, g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restartScore < plainScore-1e-6 {
+			t.Errorf("restarts lowered the score from %.3f to %.3f", plainScore, restartScore)
+		}
+		results = append(results, g)
+	}
+	if err := sameMarks(results[0], results[1]); err != nil {
+		t.Errorf("the same seed gave different DAGs: %v", err)
+	}
+
+	options := DefaultHillClimbOptions()
+	options.Restarts = 1
+	if _, err := HillClimb(bic, options); err == nil {
+		t.Error("restarts without a random number generator must fail")
+	}
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"fmt"
+)
+
+/*
+Knowledge
+
+Background knowledge about the edges of a DAG: required edges (a whitelist) must appear
+with the given direction, forbidden edges (a blacklist) must not. Forbidding x --> y
+leaves y --> x allowed.
+*/
+type Knowledge struct {
+	required  map[[2]*graph.Node]bool
+	forbidden map[[2]*graph.Node]bool
+}
+
+func NewKnowledge() *Knowledge {
+	return &Knowledge{required: map[[2]*graph.Node]bool{}, forbidden: map[[2]*graph.Node]bool{}}
+}
+
+/*
+SetRequired
+
+Requires the edge from --> to. Returns an error if it is forbidden or its reverse is
+required.
+*/
+func (k *Knowledge) SetRequired(from, to *graph.Node) error {
+	if k.forbidden[[2]*graph.Node{from, to}] {
+		return fmt.Errorf("edge %s --> %s is forbidden", from.GetName(), to.GetName())
+	}
+	if k.required[[2]*graph.Node{to, from}] {
+		return fmt.Errorf("edge %s --> %s is required", to.GetName(), from.GetName())
+	}
+	k.required[[2]*graph.Node{from, to}] = true
+	return nil
+}
+
+/*
+SetForbidden
+
+Forbids the edge from --> to. Returns an error if it is required.
+*/
+func (k *Knowledge) SetForbidden(from, to *graph.Node) error {
+	if k.required[[2]*graph.Node{from, to}] {
+		return fmt.Errorf("edge %s --> %s is required", from.GetName(), to.GetName())
+	}
+	k.forbidden[[2]*graph.Node{from, to}] = true
+	return nil
+}
+
+func (k *Knowledge) IsRequired(from, to *graph.Node) bool {
+	return k != nil && k.required[[2]*graph.Node{from, to}]
+}
+
+func (k *Knowledge) IsForbidden(from, to *graph.Node) bool {
+	return k != nil && k.forbidden[[2]*graph.Node{from, to}]
+}
+
+/*
+GetRequired
+
+Returns the required edges as (from, to) pairs.
+*/
+func (k *Knowledge) GetRequired() [][2]*graph.Node {
+	if k == nil {
+		return nil
+	}
+	var edges [][2]*graph.Node
+	for edge := range k.required {
+		edges = append(edges, edge)
+	}
+	return edges
+}
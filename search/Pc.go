@@ -0,0 +1,177 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+	"GoCausal/utils"
+)
+
+/*
+PcOptions
+
+Alpha is the level of the independence tests. Depth caps the size of conditioning sets;
+a negative depth means no cap. Beware that the zero value caps the depth at 0, so that
+only marginal independences are tested; start from DefaultPcOptions.
+*/
+type PcOptions struct {
+	Alpha float64
+	Depth int
+}
+
+/*
+DefaultPcOptions
+
+Returns tests at level 0.05 with no cap on the size of conditioning sets.
+*/
+func DefaultPcOptions() PcOptions {
+	return PcOptions{Alpha: 0.05, Depth: -1}
+}
+
+/*
+Pc
+
+Runs the PC algorithm (Spirtes & Glymour, 1991) over the variables of the test: the
+skeleton is found by FindSkeleton, unshielded colliders are oriented from the separating
+sets and Meek's rules complete the orientation. Returns the resulting pattern.
+*/
+func Pc(test citest.CITest, options PcOptions) (*graph.Graph, error) {
+	g, sepsets, err := FindSkeleton(test, options)
+	if err != nil {
+		return nil, err
+	}
+	OrientColliders(g, sepsets, nil)
+	graph.ApplyMeekRules(g)
+	g.SetPattern(true)
+	return g, nil
+}
+
+/*
+FindSkeleton
+
+Runs the adjacency search of PC-stable (Colombo & Maathuis, 2014): starting from the
+complete undirected graph over the variables of the test, an edge x -- y is removed as
+soon as x and y are found independent given some subset of the neighbours of x or of y,
+trying subsets of size 0, 1, ... up to options.Depth. The neighbours are fixed at the
+start of each size, which makes the result independent of the variable order.
+Returns the skeleton together with the separating sets of the removed edges.
+*/
+func FindSkeleton(test citest.CITest, options PcOptions) (*graph.Graph, *SepsetMap, error) {
+	nodes := test.GetVariables()
+	g := graph.NewGraph(append([]*graph.Node{}, nodes...))
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			g.AddEdge(graph.UndirectedEdge(a, b))
+		}
+	}
+	sepsets := NewSepsetMap()
+	if err := pruneSkeleton(test, g, sepsets, options); err != nil {
+		return nil, nil, err
+	}
+	return g, sepsets, nil
+}
+
+// pruneSkeleton removes the edges of g between nodes found independent by the adjacency
+// search of PC-stable, recording the separating sets.
+func pruneSkeleton(test citest.CITest, g *graph.Graph, sepsets *SepsetMap, options PcOptions) error {
+	nodes := g.GetNodes()
+	for depth := 0; options.Depth < 0 || depth <= options.Depth; depth++ {
+		adjacent := map[*graph.Node][]*graph.Node{}
+		more := false
+		for _, n := range nodes {
+			adjacent[n] = g.GetAdjacentNodes(n)
+			if len(adjacent[n])-1 >= depth {
+				more = true
+			}
+		}
+		if !more {
+			break
+		}
+		for i, x := range nodes {
+			for _, y := range nodes[i+1:] {
+				if !g.IsAdjacentTo(x, y) {
+					continue
+				}
+				z, found, err := findSepset(test, x, y, adjacent, depth, options.Alpha)
+				if err != nil {
+					return err
+				}
+				if found {
+					g.RemoveConnectingEdges(x, y)
+					sepsets.Set(x, y, z)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findSepset looks for a subset of size depth of the neighbours of x, then of y, that
+// makes x and y independent.
+func findSepset(test citest.CITest, x, y *graph.Node, adjacent map[*graph.Node][]*graph.Node, depth int, alpha float64) ([]*graph.Node, bool, error) {
+	for _, pair := range [][2]*graph.Node{{x, y}, {y, x}} {
+		var candidates []*graph.Node
+		for _, n := range adjacent[pair[0]] {
+			if n != pair[1] {
+				candidates = append(candidates, n)
+			}
+		}
+		var sepset []*graph.Node
+		var err error
+		utils.ForEachCombination(len(candidates), depth, func(subset []int) bool {
+			z := make([]*graph.Node, len(subset))
+			for k, c := range subset {
+				z[k] = candidates[c]
+			}
+			independent, testErr := citest.IsIndependent(test, x, y, z, alpha)
+			if testErr != nil {
+				err = testErr
+				return false
+			}
+			if independent {
+				sepset = z
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if sepset != nil {
+			return sepset, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+/*
+OrientColliders
+
+Orients every unshielded triple a -- b -- c whose middle node b is not in the separating
+set of a and c as the collider a --> b <-- c. Orientations conflicting with edges already
+directed are skipped, and no edge is ever directed into a node of exogenous.
+*/
+func OrientColliders(g *graph.Graph, sepsets *SepsetMap, exogenous []*graph.Node) {
+	fixed := utils.NewSet(exogenous...)
+	for _, b := range g.GetNodes() {
+		if fixed.Contains(b) {
+			continue
+		}
+		adjacent := g.GetAdjacentNodes(b)
+		for i, a := range adjacent {
+			for _, c := range adjacent[i+1:] {
+				if g.IsAdjacentTo(a, c) {
+					continue
+				}
+				if _, ok := sepsets.Get(a, c); !ok || sepsets.Separates(a, c, b) {
+					continue
+				}
+				for _, n := range []*graph.Node{a, c} {
+					if g.IsUndirectedFromTo(n, b) {
+						g.RemoveConnectingEdges(n, b)
+						g.AddDirectedEdge(n, b)
+					}
+				}
+			}
+		}
+	}
+}
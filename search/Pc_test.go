@@ -0,0 +1,118 @@
+package search
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func newTestNodes(n int) []*graph.Node {
+	nodes := make([]*graph.Node, n)
+	for i := range nodes {
+		nodes[i] = &graph.Node{}
+		nodes[i].SetName(fmt.Sprintf("X%d", i+1))
+	}
+	return nodes
+}
+
+func randomDag(rng *rand.Rand, n int, p float64) *graph.Graph {
+	nodes := newTestNodes(n)
+	g := graph.NewGraph(nodes)
+	order := rng.Perm(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < p {
+				g.AddDirectedEdge(nodes[order[i]], nodes[order[j]])
+			}
+		}
+	}
+	return g
+}
+
+// separationOracle answers independence queries by m-separation in a DAG or MAG, with
+// p-value 1 for separated and 0 for connected pairs.
+type separationOracle struct {
+	g         *graph.Graph
+	variables []*graph.Node
+}
+
+func newSeparationOracle(g *graph.Graph) *separationOracle {
+	return &separationOracle{g: g, variables: g.GetNodes()}
+}
+
+func (o *separationOracle) GetVariables() []*graph.Node {
+	return o.variables
+}
+
+func (o *separationOracle) Test(x, y *graph.Node, z []*graph.Node) (float64, float64, error) {
+	if o.g.IsMSeparatedFrom(x, y, z) {
+		return 1, 0, nil
+	}
+	return 0, 1, nil
+}
+
+// sameMarks reports the first pair of nodes whose endpoints differ between want and got,
+// reading the endpoints from the edge lists.
+func sameMarks(want, got *graph.Graph) error {
+	marks := func(g *graph.Graph) map[[2]string]graph.Endpoint {
+		m := map[[2]string]graph.Endpoint{}
+		for _, e := range g.GetGraphEdges() {
+			a, b := e.GetNode1().GetName(), e.GetNode2().GetName()
+			m[[2]string{a, b}] = e.GetEndpoint2()
+			m[[2]string{b, a}] = e.GetEndpoint1()
+		}
+		return m
+	}
+	w, g := marks(want), marks(got)
+	for pair, mark := range w {
+		if g[pair] != mark {
+			return fmt.Errorf("mark at %s on %s -- %s: want %v, got %v", pair[1], pair[0], pair[1], mark, g[pair])
+		}
+	}
+	for pair := range g {
+		if _, ok := w[pair]; !ok {
+			return fmt.Errorf("unexpected edge %s -- %s", pair[0], pair[1])
+		}
+	}
+	return nil
+}
+
+func TestPcRecoversCpdagFromOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for trial := 0; trial < 100; trial++ {
+		dag := randomDag(rng, 3+rng.Intn(6), 0.2+0.4*rng.Float64())
+		cpdag, err := graph.DagToCpdag(dag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pattern, err := Pc(newSeparationOracle(dag), DefaultPcOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sameMarks(cpdag, pattern); err != nil {
+			t.Fatalf("%v\nDAG\n%s\nPC\n%s", err, dag.ToString(), pattern.ToString())
+		}
+	}
+}
+
+func TestPcZeroDepthTestsOnlyMarginalIndependence(t *testing.T) {
+	nodes := newTestNodes(3)
+	dag := graph.NewGraph(nodes)
+	dag.AddDirectedEdge(nodes[0], nodes[1])
+	dag.AddDirectedEdge(nodes[1], nodes[2])
+	g, _, err := FindSkeleton(newSeparationOracle(dag), PcOptions{Alpha: 0.05})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.IsAdjacentTo(nodes[0], nodes[2]) {
+		t.Error("with depth 0, X1 and X3 are never tested given X2")
+	}
+	g, _, err = FindSkeleton(newSeparationOracle(dag), DefaultPcOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.IsAdjacentTo(nodes[0], nodes[2]) {
+		t.Error("X1 and X3 are independent given X2")
+	}
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+)
+
+/*
+Rfci
+
+Runs RFCI (Colombo et al., 2012) over the variables of the test, with the skeleton
+search of PC configured by options, and returns a PAG. RFCI avoids the possible
+d-separating set searches of FCI: before an unshielded triple a o-o b o-o c with b not
+in the separating set S of a and c is oriented as a collider, a and b and b and c are
+tested given S; an edge found absent is removed with a minimal separating set taken from
+S, which may create new unshielded triples to examine. Triples whose middle node
+separates the ends are recorded as underlines (definite non-colliders) on the graph.
+The rules of FCI then complete the orientation, the adjacencies along a discriminating
+path being checked the same way before R4 uses it. The result may contain edges FCI
+would remove, but every arrowhead and tail it shows is sound.
+*/
+func Rfci(test citest.CITest, options PcOptions) (*graph.Graph, error) {
+	g, sepsets, err := FindSkeleton(test, options)
+	if err != nil {
+		return nil, err
+	}
+	reorientAllWithCircles(g)
+	o := &pagOrientation{g: g, sepsets: sepsets, test: test, options: options, reduced: true}
+
+	queue := unshieldedTriples(g)
+	var colliders []*graph.Triple
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		a, b, c := t.GetX(), t.GetY(), t.GetZ()
+		if !g.IsAdjacentTo(a, b) || !g.IsAdjacentTo(b, c) || g.IsAdjacentTo(a, c) {
+			continue
+		}
+		if sepsets.Separates(a, c, b) {
+			g.AddUnderlineTriple(t)
+			continue
+		}
+		sepset, _ := sepsets.Get(a, c)
+		removed := false
+		for _, pair := range [][2]*graph.Node{{a, b}, {b, c}} {
+			found, err := o.removeIfSeparated(pair[0], pair[1], sepset)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				removed = true
+				queue = append(queue, triplesAround(g, pair[0], pair[1])...)
+			}
+		}
+		if !removed {
+			colliders = append(colliders, t)
+		}
+	}
+	for _, t := range colliders {
+		a, b, c := t.GetX(), t.GetY(), t.GetZ()
+		if g.IsAdjacentTo(a, b) && g.IsAdjacentTo(b, c) && !g.IsAdjacentTo(a, c) {
+			orientCollider(g, a, b, c)
+		}
+	}
+
+	if err := o.run(); err != nil {
+		return nil, err
+	}
+	g.SetPag(true)
+	return g, nil
+}
+
+// unshieldedTriples returns the triples a *-* b *-* c of g with a and c non-adjacent.
+func unshieldedTriples(g *graph.Graph) []*graph.Triple {
+	var triples []*graph.Triple
+	for _, b := range g.GetNodes() {
+		adjacent := g.GetAdjacentNodes(b)
+		for i, a := range adjacent {
+			for _, c := range adjacent[i+1:] {
+				if !g.IsAdjacentTo(a, c) {
+					triples = append(triples, graph.NewTriple(a, b, c))
+				}
+			}
+		}
+	}
+	return triples
+}
+
+// triplesAround returns the unshielded triples with ends x and y.
+func triplesAround(g *graph.Graph, x, y *graph.Node) []*graph.Triple {
+	var triples []*graph.Triple
+	for _, b := range g.GetAdjacentNodes(x) {
+		if g.IsAdjacentTo(b, y) {
+			triples = append(triples, graph.NewTriple(x, b, y))
+		}
+	}
+	return triples
+}
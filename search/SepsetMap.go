@@ -0,0 +1,46 @@
+package search
+
+import "GoCausal/graph"
+
+/*
+SepsetMap
+
+Records, for pairs of nodes found conditionally independent, the set they were
+separated by. Lookups are symmetric in the two nodes.
+*/
+type SepsetMap struct {
+	sepsets map[[2]*graph.Node][]*graph.Node
+}
+
+func NewSepsetMap() *SepsetMap {
+	return &SepsetMap{sepsets: map[[2]*graph.Node][]*graph.Node{}}
+}
+
+func (m *SepsetMap) Set(x, y *graph.Node, z []*graph.Node) {
+	m.sepsets[[2]*graph.Node{x, y}] = append([]*graph.Node{}, z...)
+	m.sepsets[[2]*graph.Node{y, x}] = m.sepsets[[2]*graph.Node{x, y}]
+}
+
+/*
+Get
+
+Returns the separating set of x and y, and false if none was recorded.
+*/
+func (m *SepsetMap) Get(x, y *graph.Node) ([]*graph.Node, bool) {
+	z, ok := m.sepsets[[2]*graph.Node{x, y}]
+	return z, ok
+}
+
+/*
+Separates
+
+Returns true if a separating set of x and y was recorded and contains node.
+*/
+func (m *SepsetMap) Separates(x, y, node *graph.Node) bool {
+	for _, n := range m.sepsets[[2]*graph.Node{x, y}] {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
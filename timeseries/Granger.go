@@ -0,0 +1,148 @@
+package timeseries
+
+import (
+	"GoCausal/estimate"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+/*
+GrangerResult
+
+Conditional Granger causality tests between all ordered pairs of variables of a vector
+autoregression of order MaxLag. Entry [i][j] of FStatistics and PValues tests whether
+the past of variable i helps predict variable j given the past of every variable, with
+DegreesOfFreedom F degrees of freedom; diagonal entries are zero.
+The full VAR equation of every variable is kept in Fits, its regressors being the lagged
+columns of LaggedData (lag 1 to MaxLag, variables in order within a lag).
+*/
+type GrangerResult struct {
+	Variables        []*graph.Node
+	MaxLag           int
+	FStatistics      *mat.Dense
+	PValues          *mat.Dense
+	DegreesOfFreedom [2]int
+	Fits             []*estimate.RegressionResult
+}
+
+/*
+GrangerCausality
+
+Fits a VAR(maxLag) to a time series with one row per time point and one column per
+variable by ordinary least squares, one equation per variable, and tests every pair
+with the F-test comparing the equation of the effect with and without the lags of the
+cause.
+*/
+func GrangerCausality(variables []*graph.Node, series *mat.Dense, maxLag int) (*GrangerResult, error) {
+	_, p := series.Dims()
+	if p != len(variables) {
+		return nil, fmt.Errorf("series has %d columns but %d variables were given", p, len(variables))
+	}
+	if maxLag < 1 {
+		return nil, fmt.Errorf("maximum lag must be positive")
+	}
+	lagged, err := LaggedData(series, maxLag)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := lagged.Dims()
+	full := p*maxLag + 1
+	if n <= full {
+		return nil, fmt.Errorf("a VAR(%d) in %d variables needs more than %d time points", maxLag, p, full+maxLag)
+	}
+	result := &GrangerResult{
+		Variables:        variables,
+		MaxLag:           maxLag,
+		FStatistics:      mat.NewDense(p, p, nil),
+		PValues:          mat.NewDense(p, p, nil),
+		DegreesOfFreedom: [2]int{maxLag, n - full},
+	}
+	f := distuv.F{D1: float64(maxLag), D2: float64(n - full)}
+	for j := 0; j < p; j++ {
+		fit, err := estimate.Regress(lagged, j, laggedColumns(p, maxLag, -1))
+		if err != nil {
+			return nil, fmt.Errorf("fitting %s: %w", variables[j].GetName(), err)
+		}
+		result.Fits = append(result.Fits, fit)
+		rssFull := fit.ResidualVariance * float64(n-full)
+		for i := 0; i < p; i++ {
+			if i == j {
+				continue
+			}
+			restricted, err := estimate.Regress(lagged, j, laggedColumns(p, maxLag, i))
+			if err != nil {
+				return nil, fmt.Errorf("fitting %s without %s: %w", variables[j].GetName(), variables[i].GetName(), err)
+			}
+			rssRestricted := restricted.ResidualVariance * float64(n-full+maxLag)
+			stat := (rssRestricted - rssFull) / float64(maxLag) / (rssFull / float64(n-full))
+			result.FStatistics.Set(i, j, stat)
+			result.PValues.Set(i, j, f.Survival(stat))
+		}
+	}
+	return result, nil
+}
+
+// laggedColumns returns the columns of LaggedData at lags 1 .. maxLag, leaving out those
+// of variable skip.
+func laggedColumns(p, maxLag, skip int) []int {
+	var columns []int
+	for lag := 1; lag <= maxLag; lag++ {
+		for i := 0; i < p; i++ {
+			if i != skip {
+				columns = append(columns, lag*p+i)
+			}
+		}
+	}
+	return columns
+}
+
+/*
+Graph
+
+Returns the time-lag graph of the test results at level alpha: for every pair where i
+Granger-causes j, an edge from i at lag l to j at lag 0 for every lag l whose coefficient
+in the VAR equation of j is significant at level alpha, or for the lag with the largest
+t-statistic if none is.
+*/
+func (r *GrangerResult) Graph(alpha float64) (*graph.TimeLagGraph, error) {
+	g, err := graph.NewTimeLagGraph(r.Variables, r.MaxLag)
+	if err != nil {
+		return nil, err
+	}
+	p := len(r.Variables)
+	critical := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(r.DegreesOfFreedom[1])}.Quantile(1 - alpha/2)
+	for j := 0; j < p; j++ {
+		for i := 0; i < p; i++ {
+			if i == j || r.PValues.At(i, j) >= alpha {
+				continue
+			}
+			best, bestT := 1, 0.0
+			var lags []int
+			for lag := 1; lag <= r.MaxLag; lag++ {
+				k := (lag-1)*p + i
+				t := r.Fits[j].Coefficients[k] / r.Fits[j].StandardErrors[k]
+				if t < 0 {
+					t = -t
+				}
+				if t > critical {
+					lags = append(lags, lag)
+				}
+				if t > bestT {
+					best, bestT = lag, t
+				}
+			}
+			if lags == nil {
+				lags = []int{best}
+			}
+			for _, lag := range lags {
+				from := g.GetLaggedNode(r.Variables[i], lag)
+				if err := g.AddLaggedEdge(from, r.Variables[j]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,61 @@
+package timeseries
+
+import (
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+func TestGrangerCausalityOnVar1(t *testing.T) {
+	rng := rand.New(rand.NewSource(40))
+	// X1(t) = 0.5 X1(t-1) + e1, X2(t) = 0.4 X2(t-1) + 0.5 X1(t-1) + e2, X3(t) = 0.5 X3(t-1) + e3
+	variables := make([]*graph.Node, 3)
+	for i := range variables {
+		variables[i] = &graph.Node{}
+		variables[i].SetName(fmt.Sprintf("X%d", i+1))
+	}
+	series := mat.NewDense(1000, 3, nil)
+	for s := 1; s < 1000; s++ {
+		x1 := 0.5*series.At(s-1, 0) + rng.NormFloat64()
+		x2 := 0.4*series.At(s-1, 1) + 0.5*series.At(s-1, 0) + rng.NormFloat64()
+		x3 := 0.5*series.At(s-1, 2) + rng.NormFloat64()
+		series.SetRow(s, []float64{x1, x2, x3})
+	}
+
+	result, err := GrangerCausality(variables, series, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range variables {
+		for j := range variables {
+			if i == j {
+				continue
+			}
+			p := result.PValues.At(i, j)
+			if causes := i == 0 && j == 1; causes != (p < 0.01) {
+				t.Errorf("%s Granger-causes %s: %v, but the p-value is %.4f", variables[i].GetName(), variables[j].GetName(), causes, p)
+			}
+		}
+	}
+	if result.DegreesOfFreedom != [2]int{1, 999 - 4} {
+		t.Errorf("degrees of freedom: got %v, want [1 995]", result.DegreesOfFreedom)
+	}
+	g, err := result.Graph(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// autoregressive terms are not tested, so only the link X1 --> X2 appears
+	checkLaggedLinks(t, g, variables, map[[3]int]bool{{0, 1, 1}: true})
+
+	if _, err := GrangerCausality(variables, series, 0); err == nil {
+		t.Error("a zero maximum lag must fail")
+	}
+	if _, err := GrangerCausality(variables[:2], series, 1); err == nil {
+		t.Error("a variable per column is required")
+	}
+	if _, err := GrangerCausality(variables, mat.DenseCopyOf(series.Slice(0, 5, 0, 3)), 1); err == nil {
+		t.Error("5 time points are too few for a VAR(1) in 3 variables")
+	}
+}
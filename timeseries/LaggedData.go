@@ -0,0 +1,35 @@
+package timeseries
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+LaggedData
+
+Turns a time series with one row per time point and one column per variable into
+lag-augmented data for maxLag lags: row k holds the variables at time k + maxLag,
+then at the time before and so on back to time k, so that the columns follow the node
+order of a graph.TimeLagGraph over the same variables and the result can be handed to
+any search algorithm working on i.i.d. rows.
+*/
+func LaggedData(series *mat.Dense, maxLag int) (*mat.Dense, error) {
+	t, p := series.Dims()
+	if maxLag < 0 {
+		return nil, fmt.Errorf("maximum lag must not be negative")
+	}
+	if t <= maxLag {
+		return nil, fmt.Errorf("series of %d time points is too short for %d lags", t, maxLag)
+	}
+	rows := t - maxLag
+	lagged := mat.NewDense(rows, p*(maxLag+1), nil)
+	for k := 0; k < rows; k++ {
+		for lag := 0; lag <= maxLag; lag++ {
+			for j := 0; j < p; j++ {
+				lagged.Set(k, lag*p+j, series.At(k+maxLag-lag, j))
+			}
+		}
+	}
+	return lagged, nil
+}
@@ -0,0 +1,42 @@
+package timeseries
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"testing"
+)
+
+func TestLaggedDataLayout(t *testing.T) {
+	// X1(t) = t and X2(t) = 10 t for t = 0 .. 3
+	series := mat.NewDense(4, 2, []float64{
+		0, 0,
+		1, 10,
+		2, 20,
+		3, 30,
+	})
+	lagged, err := LaggedData(series, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// columns X1, X2 at lag 0, then at lag 1, then at lag 2
+	want := mat.NewDense(2, 6, []float64{
+		2, 20, 1, 10, 0, 0,
+		3, 30, 2, 20, 1, 10,
+	})
+	if !mat.Equal(lagged, want) {
+		t.Errorf("got\n%v\nwant\n%v", mat.Formatted(lagged), mat.Formatted(want))
+	}
+
+	lagged, err = LaggedData(series, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(lagged, series) {
+		t.Errorf("no lags must return the series, got\n%v", mat.Formatted(lagged))
+	}
+	if _, err := LaggedData(series, 4); err == nil {
+		t.Error("4 lags of 4 time points must fail")
+	}
+	if _, err := LaggedData(series, -1); err == nil {
+		t.Error("a negative lag must fail")
+	}
+}
@@ -0,0 +1,431 @@
+package timeseries
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+	"GoCausal/utils"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"sort"
+)
+
+/*
+PcmciOptions
+
+MaxLag is the largest lag considered for links. PcAlpha is the level of the condition
+selection tests and Alpha that of the final tests deciding which links are kept.
+MaxConditions caps the size of the conditioning sets tried in condition selection;
+zero means no cap.
+*/
+type PcmciOptions struct {
+	MaxLag        int
+	PcAlpha       float64
+	Alpha         float64
+	MaxConditions int
+}
+
+/*
+PcmciResult
+
+The links found by PCMCI or PCMCI+. Entry [i][j][lag] of PValues and Strengths refers to
+the link from variable i at the given lag to variable j at lag 0: the largest p-value of
+the tests of that link and the test statistic of that test. Entries of links that were
+never tested, such as lag 0 links in PCMCI, are NaN.
+*/
+type PcmciResult struct {
+	Graph     *graph.TimeLagGraph
+	PValues   [][][]float64
+	Strengths [][][]float64
+}
+
+// link is variable i at a lag
+type link struct {
+	variable int
+	lag      int
+}
+
+type pcmci struct {
+	options   PcmciOptions
+	variables []*graph.Node
+	test      citest.CITest
+	// window over twice the maximum lag, so that the parents of a lagged node fit in it
+	window *graph.TimeLagGraph
+	result *PcmciResult
+}
+
+func newPcmci(variables []*graph.Node, series *mat.Dense, factory citest.Factory, options PcmciOptions) (*pcmci, error) {
+	_, p := series.Dims()
+	if p != len(variables) {
+		return nil, fmt.Errorf("series has %d columns but %d variables were given", p, len(variables))
+	}
+	if options.MaxLag < 1 {
+		return nil, fmt.Errorf("maximum lag must be positive")
+	}
+	window, err := graph.NewTimeLagGraph(variables, 2*options.MaxLag)
+	if err != nil {
+		return nil, err
+	}
+	lagged, err := LaggedData(series, 2*options.MaxLag)
+	if err != nil {
+		return nil, err
+	}
+	test, err := factory(window.GetNodes(), lagged)
+	if err != nil {
+		return nil, err
+	}
+	g, err := graph.NewTimeLagGraph(variables, options.MaxLag)
+	if err != nil {
+		return nil, err
+	}
+	result := &PcmciResult{Graph: g}
+	for i := 0; i < p; i++ {
+		var pValues, strengths [][]float64
+		for j := 0; j < p; j++ {
+			pv := make([]float64, options.MaxLag+1)
+			st := make([]float64, options.MaxLag+1)
+			for lag := range pv {
+				pv[lag], st[lag] = math.NaN(), math.NaN()
+			}
+			pValues = append(pValues, pv)
+			strengths = append(strengths, st)
+		}
+		result.PValues = append(result.PValues, pValues)
+		result.Strengths = append(result.Strengths, strengths)
+	}
+	return &pcmci{options: options, variables: variables, test: test, window: window, result: result}, nil
+}
+
+func (m *pcmci) node(l link) *graph.Node {
+	return m.window.GetLaggedNode(m.variables[l.variable], l.lag)
+}
+
+// testLink tests the link from l to variable j at lag 0 given conditions, recording the
+// result if its p-value is the largest so far.
+func (m *pcmci) testLink(l link, j int, conditions []link) (float64, float64, error) {
+	z := make([]*graph.Node, len(conditions))
+	for k, c := range conditions {
+		z[k] = m.node(c)
+	}
+	p, stat, err := m.test.Test(m.node(l), m.node(link{j, 0}), z)
+	if err != nil {
+		return 0, 0, err
+	}
+	m.record(l.variable, j, l.lag, p, stat)
+	if l.lag == 0 {
+		// contemporaneous links are reported both ways
+		m.record(j, l.variable, 0, p, stat)
+	}
+	return p, stat, nil
+}
+
+func (m *pcmci) record(i, j, lag int, p, stat float64) {
+	old := m.result.PValues[i][j][lag]
+	if math.IsNaN(old) || p > old {
+		m.result.PValues[i][j][lag] = p
+		m.result.Strengths[i][j][lag] = stat
+	}
+}
+
+/*
+selectConditions
+
+Runs the PC1 condition selection for variable j: starting from every lagged link, links
+independent of j at level PcAlpha given the strongest other remaining links are removed,
+with conditioning sets of growing size. Returns the remaining links, strongest first.
+*/
+func (m *pcmci) selectConditions(j int) ([]link, error) {
+	var parents []link
+	for lag := 1; lag <= m.options.MaxLag; lag++ {
+		for i := range m.variables {
+			parents = append(parents, link{i, lag})
+		}
+	}
+	strength := map[link]float64{}
+	for _, l := range parents {
+		strength[l] = math.Inf(1)
+	}
+	for dim := 0; dim < len(parents); dim++ {
+		if m.options.MaxConditions > 0 && dim > m.options.MaxConditions {
+			break
+		}
+		var kept []link
+		for _, l := range parents {
+			var conditions []link
+			for _, c := range parents {
+				if c != l && len(conditions) < dim {
+					conditions = append(conditions, c)
+				}
+			}
+			p, stat, err := m.testLink(l, j, conditions)
+			if err != nil {
+				return nil, err
+			}
+			strength[l] = math.Min(strength[l], math.Abs(stat))
+			if p <= m.options.PcAlpha {
+				kept = append(kept, l)
+			}
+		}
+		parents = kept
+		sort.SliceStable(parents, func(a, b int) bool {
+			return strength[parents[a]] > strength[parents[b]]
+		})
+	}
+	return parents, nil
+}
+
+// mciConditions returns the parents of j without l together with the parents of the
+// variable of l shifted to its lag.
+func mciConditions(parents [][]link, l link, j int) []link {
+	seen := map[link]bool{l: true}
+	var conditions []link
+	for _, c := range parents[j] {
+		if !seen[c] {
+			seen[c] = true
+			conditions = append(conditions, c)
+		}
+	}
+	for _, c := range parents[l.variable] {
+		shifted := link{c.variable, c.lag + l.lag}
+		if !seen[shifted] {
+			seen[shifted] = true
+			conditions = append(conditions, shifted)
+		}
+	}
+	return conditions
+}
+
+/*
+Pcmci
+
+Runs PCMCI (Runge et al., 2019) on a time series with one row per time point and one
+column per variable. Condition selection (PC1) finds a small superset of the lagged
+parents of every variable; every lagged link i at lag τ -> j is then kept if the
+momentary conditional independence (MCI) test, conditioning on the selected parents of
+j and on those of i shifted by τ, rejects at level Alpha. Contemporaneous links are not
+considered; see PcmciPlus.
+The tests are built by factory over the nodes of a time-lag graph with twice the maximum
+lag, from the LaggedData of the series, so 2 MaxLag time points are lost.
+*/
+func Pcmci(variables []*graph.Node, series *mat.Dense, factory citest.Factory, options PcmciOptions) (*PcmciResult, error) {
+	m, err := newPcmci(variables, series, factory, options)
+	if err != nil {
+		return nil, err
+	}
+	parents := make([][]link, len(variables))
+	for j := range variables {
+		if parents[j], err = m.selectConditions(j); err != nil {
+			return nil, err
+		}
+	}
+	// the final tests define the reported p-values
+	for i := range variables {
+		for j := range variables {
+			for lag := 1; lag <= options.MaxLag; lag++ {
+				m.result.PValues[i][j][lag] = math.NaN()
+			}
+		}
+	}
+	g := m.result.Graph
+	for j := range variables {
+		for lag := 1; lag <= options.MaxLag; lag++ {
+			for i := range variables {
+				l := link{i, lag}
+				p, _, err := m.testLink(l, j, mciConditions(parents, l, j))
+				if err != nil {
+					return nil, err
+				}
+				if p <= options.Alpha {
+					if err := g.AddLaggedEdge(g.GetLaggedNode(variables[i], lag), variables[j]); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return m.result, nil
+}
+
+/*
+PcmciPlus
+
+Runs PCMCI+ (Runge, 2020), which also finds contemporaneous links. After condition
+selection for the lagged parents, a PC-style skeleton search over lagged and
+contemporaneous links removes every link found independent at level Alpha given the
+lagged parents of both ends (MCI conditions) and growing subsets of the contemporaneous
+neighbours of the effect. Unshielded colliders among contemporaneous links are then
+oriented from the separating sets and Meek's rules propagate the orientations, lagged
+links being oriented forward in time. Contemporaneous links that stay unoriented, or
+for which colliders disagree, are undirected.
+*/
+func PcmciPlus(variables []*graph.Node, series *mat.Dense, factory citest.Factory, options PcmciOptions) (*PcmciResult, error) {
+	m, err := newPcmci(variables, series, factory, options)
+	if err != nil {
+		return nil, err
+	}
+	p := len(variables)
+	lagged := make([][]link, p)
+	for j := range variables {
+		if lagged[j], err = m.selectConditions(j); err != nil {
+			return nil, err
+		}
+	}
+	// links removed by condition selection keep its p-values, the others get those of
+	// the skeleton search
+	for j := range variables {
+		for _, l := range lagged[j] {
+			m.result.PValues[l.variable][j][l.lag] = math.NaN()
+		}
+	}
+
+	// adjacent[j] holds the links into j at lag 0 still in the skeleton
+	adjacent := make([][]link, p)
+	for j := range variables {
+		adjacent[j] = append([]link{}, lagged[j]...)
+		for i := range variables {
+			if i != j {
+				adjacent[j] = append(adjacent[j], link{i, 0})
+			}
+		}
+	}
+	sepsets := map[[2]link][]link{}
+	contains := func(links []link, l link) bool {
+		for _, c := range links {
+			if c == l {
+				return true
+			}
+		}
+		return false
+	}
+	remove := func(links []link, l link) []link {
+		var rest []link
+		for _, c := range links {
+			if c != l {
+				rest = append(rest, c)
+			}
+		}
+		return rest
+	}
+	for dim := 0; ; dim++ {
+		if options.MaxConditions > 0 && dim > options.MaxConditions {
+			break
+		}
+		tested := false
+		for j := range variables {
+			for _, l := range append([]link{}, adjacent[j]...) {
+				if !contains(adjacent[j], l) {
+					continue
+				}
+				var neighbours []link
+				for _, c := range adjacent[j] {
+					if c.lag == 0 && c != l {
+						neighbours = append(neighbours, c)
+					}
+				}
+				if len(neighbours) < dim {
+					continue
+				}
+				tested = true
+				base := mciConditions(lagged, l, j)
+				independent := false
+				utils.ForEachCombination(len(neighbours), dim, func(subset []int) bool {
+					conditions := append([]link{}, base...)
+					var s []link
+					for _, k := range subset {
+						s = append(s, neighbours[k])
+						if !contains(conditions, neighbours[k]) {
+							conditions = append(conditions, neighbours[k])
+						}
+					}
+					pv, _, testErr := m.testLink(l, j, conditions)
+					if testErr != nil {
+						err = testErr
+						return false
+					}
+					if pv > options.Alpha {
+						independent = true
+						sepsets[[2]link{l, {j, 0}}] = s
+						sepsets[[2]link{{j, 0}, l}] = s
+						return false
+					}
+					return true
+				})
+				if err != nil {
+					return nil, err
+				}
+				if independent {
+					adjacent[j] = remove(adjacent[j], l)
+					if l.lag == 0 {
+						adjacent[l.variable] = remove(adjacent[l.variable], link{j, 0})
+					}
+				}
+			}
+		}
+		if !tested {
+			break
+		}
+	}
+
+	// orientation on a graph of lag 0 and the lagged parents of the lag 0 nodes
+	g := m.result.Graph
+	work := graph.NewGraph(append([]*graph.Node{}, g.GetNodes()...))
+	at := func(l link) *graph.Node {
+		return g.GetLaggedNode(variables[l.variable], l.lag)
+	}
+	for j := range variables {
+		for _, l := range adjacent[j] {
+			if l.lag > 0 {
+				work.AddDirectedEdge(at(l), variables[j])
+			} else if l.variable < j {
+				work.AddEdge(graph.UndirectedEdge(at(l), variables[j]))
+			}
+		}
+	}
+	// colliders a *-- k -- j with a at any lag, a and j non-adjacent, k not separating them
+	into := map[[2]int]bool{}
+	for k := range variables {
+		for _, a := range adjacent[k] {
+			for _, c := range adjacent[k] {
+				if c.lag != 0 || c.variable == a.variable && a.lag == 0 {
+					continue
+				}
+				j := c.variable
+				if a == (link{j, 0}) || contains(adjacent[j], a) || a.lag == 0 && contains(adjacent[a.variable], c) {
+					continue
+				}
+				if contains(sepsets[[2]link{a, {j, 0}}], link{k, 0}) {
+					continue
+				}
+				into[[2]int{j, k}] = true
+				if a.lag == 0 {
+					into[[2]int{a.variable, k}] = true
+				}
+			}
+		}
+	}
+	for pair := range into {
+		a, b := variables[pair[0]], variables[pair[1]]
+		if into[[2]int{pair[1], pair[0]}] || !work.IsUndirectedFromTo(a, b) {
+			continue
+		}
+		work.RemoveConnectingEdges(a, b)
+		work.AddDirectedEdge(a, b)
+	}
+	graph.ApplyMeekRules(work)
+
+	for j := range variables {
+		for _, l := range adjacent[j] {
+			if l.lag > 0 {
+				err = g.AddLaggedEdge(at(l), variables[j])
+			} else if work.IsDirectedFromTo(at(l), variables[j]) {
+				err = g.AddLaggedEdge(at(l), variables[j])
+			} else if l.variable < j && work.IsUndirectedFromTo(at(l), variables[j]) {
+				err = g.AddLaggedUndirectedEdge(at(l), variables[j])
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.result, nil
+}
@@ -0,0 +1,97 @@
+package timeseries
+
+import (
+	"GoCausal/citest"
+	"GoCausal/graph"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+	"testing"
+)
+
+func fisherZFactory(variables []*graph.Node, data *mat.Dense) (citest.CITest, error) {
+	return citest.NewFisherZ(variables, data)
+}
+
+// simulateVar samples the process
+//
+//	X1(t) = 0.6 X1(t-1) + e1
+//	X2(t) = 0.5 X2(t-1) + 0.6 X1(t-1) + e2
+//	X3(t) = 0.4 X3(t-1) - 0.5 X2(t-2) + contemporaneous X1(t) + e3
+//
+// with standard Gaussian errors, after a burn-in of 100 time points.
+func simulateVar(rng *rand.Rand, length int, contemporaneous float64) ([]*graph.Node, *mat.Dense) {
+	variables := make([]*graph.Node, 3)
+	for i := range variables {
+		variables[i] = &graph.Node{}
+		variables[i].SetName(fmt.Sprintf("X%d", i+1))
+	}
+	burnIn := 100
+	x := mat.NewDense(length+burnIn, 3, nil)
+	for t := 2; t < length+burnIn; t++ {
+		x1 := 0.6*x.At(t-1, 0) + rng.NormFloat64()
+		x2 := 0.5*x.At(t-1, 1) + 0.6*x.At(t-1, 0) + rng.NormFloat64()
+		x3 := 0.4*x.At(t-1, 2) - 0.5*x.At(t-2, 1) + contemporaneous*x1 + rng.NormFloat64()
+		x.SetRow(t, []float64{x1, x2, x3})
+	}
+	return variables, mat.DenseCopyOf(x.Slice(burnIn, length+burnIn, 0, 3))
+}
+
+// checkLaggedLinks compares the lagged links of g with want, whose keys are
+// {cause, effect, lag}.
+func checkLaggedLinks(t *testing.T, g *graph.TimeLagGraph, variables []*graph.Node, want map[[3]int]bool) {
+	t.Helper()
+	for i := range variables {
+		for j := range variables {
+			for lag := 1; lag <= g.GetMaxLag(); lag++ {
+				got := g.IsDirectedFromTo(g.GetLaggedNode(variables[i], lag), variables[j])
+				if got != want[[3]int{i, j, lag}] {
+					t.Errorf("link %s at lag %d --> %s: got %v, want %v",
+						variables[i].GetName(), lag, variables[j].GetName(), got, want[[3]int{i, j, lag}])
+				}
+			}
+		}
+	}
+}
+
+func TestPcmciRecoversLaggedLinks(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	variables, series := simulateVar(rng, 1000, 0)
+	result, err := Pcmci(variables, series, fisherZFactory, PcmciOptions{MaxLag: 2, PcAlpha: 0.2, Alpha: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLaggedLinks(t, result.Graph, variables, map[[3]int]bool{
+		{0, 0, 1}: true,
+		{1, 1, 1}: true,
+		{0, 1, 1}: true,
+		{2, 2, 1}: true,
+		{1, 2, 2}: true,
+	})
+	if p := result.PValues[1][2][2]; p > 1e-6 {
+		t.Errorf("p-value of X2 at lag 2 --> X3 is %g", p)
+	}
+}
+
+func TestPcmciPlusFindsContemporaneousLinks(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	variables, series := simulateVar(rng, 1000, 0.8)
+	result, err := PcmciPlus(variables, series, fisherZFactory, PcmciOptions{MaxLag: 2, PcAlpha: 0.2, Alpha: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLaggedLinks(t, result.Graph, variables, map[[3]int]bool{
+		{0, 0, 1}: true,
+		{1, 1, 1}: true,
+		{0, 1, 1}: true,
+		{2, 2, 1}: true,
+		{1, 2, 2}: true,
+	})
+	g := result.Graph
+	if !g.IsAdjacentTo(variables[0], variables[2]) {
+		t.Error("X1 and X3 are contemporaneously linked")
+	}
+	if g.IsAdjacentTo(variables[0], variables[1]) || g.IsAdjacentTo(variables[1], variables[2]) {
+		t.Errorf("only X1 and X3 are contemporaneously linked, got\n%s", g.ToString())
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+/*
+ForEachCombination
+
+Calls f with every k-element subset of 0 .. n-1, in lexicographic order, until f
+returns false. The slice passed to f is reused between calls.
+*/
+func ForEachCombination(n, k int, f func([]int) bool) {
+	if k < 0 || k > n {
+		return
+	}
+	subset := make([]int, k)
+	var rec func(start, depth int) bool
+	rec = func(start, depth int) bool {
+		if depth == k {
+			return f(subset)
+		}
+		for i := start; i <= n-(k-depth); i++ {
+			subset[depth] = i
+			if !rec(i+1, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	rec(0, 0)
+}
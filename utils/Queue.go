@@ -1,58 +1,183 @@
 package utils
 
-type elem struct {
-	value interface{}
-	prev  *elem
-	next  *elem
+type elem[T comparable] struct {
+	value T
+	next  *elem[T]
 }
 
-type LinkedQueue struct {
-	head *elem
-	tail *elem
-	size int
+/*
+Queue
+
+FIFO queue backed by a singly linked list.
+The zero value is an empty queue ready to use.
+Membership is tracked with a counter map so that Contains runs in constant time.
+*/
+type Queue[T comparable] struct {
+	head   *elem[T]
+	tail   *elem[T]
+	size   int
+	counts map[T]int
+}
+
+func NewQueue[T comparable](values ...T) *Queue[T] {
+	queue := &Queue[T]{}
+	for _, v := range values {
+		queue.Append(v)
+	}
+	return queue
 }
 
-func (queue *LinkedQueue) Size() int {
+func (queue *Queue[T]) Size() int {
 	return queue.size
 }
 
-func (queue *LinkedQueue) Peek() interface{} {
+func (queue *Queue[T]) IsEmpty() bool {
+	return queue.size == 0
+}
+
+func (queue *Queue[T]) Peek() T {
 	if queue.head == nil {
 		panic("Empty queue.")
 	}
 	return queue.head.value
 }
 
-func (queue *LinkedQueue) Append(value interface{}) {
-	newElem := &elem{value, queue.tail, nil}
+func (queue *Queue[T]) Append(value T) {
+	newElem := &elem[T]{value: value}
 	if queue.tail == nil {
 		queue.head = newElem
-		queue.tail = newElem
 	} else {
 		queue.tail.next = newElem
-		queue.tail = newElem
 	}
+	queue.tail = newElem
+	if queue.counts == nil {
+		queue.counts = map[T]int{}
+	}
+	queue.counts[value]++
 	queue.size++
-	newElem = nil
 }
 
-func (queue *LinkedQueue) Pop() interface{} {
+func (queue *Queue[T]) Pop() T {
 	if queue.head == nil {
 		panic("Empty queue.")
 	}
 	firstElem := queue.head
 	queue.head = firstElem.next
+	if queue.head == nil {
+		queue.tail = nil
+	}
 	queue.size--
-	return firstElem
+	if queue.counts[firstElem.value] <= 1 {
+		delete(queue.counts, firstElem.value)
+	} else {
+		queue.counts[firstElem.value]--
+	}
+	return firstElem.value
+}
+
+func (queue *Queue[T]) Contains(value T) bool {
+	_, ok := queue.counts[value]
+	return ok
+}
+
+/*
+Values
+
+Returns the queued values from head to tail.
+*/
+func (queue *Queue[T]) Values() []T {
+	values := make([]T, 0, queue.size)
+	for e := queue.head; e != nil; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+/*
+Deque
+
+Double-ended queue backed by a growable ring buffer.
+The zero value is an empty deque ready to use.
+*/
+type Deque[T any] struct {
+	buf  []T
+	head int
+	size int
+}
+
+func NewDeque[T any](values ...T) *Deque[T] {
+	deque := &Deque[T]{}
+	for _, v := range values {
+		deque.PushBack(v)
+	}
+	return deque
+}
+
+func (deque *Deque[T]) grow() {
+	capacity := len(deque.buf) * 2
+	if capacity == 0 {
+		capacity = 8
+	}
+	buf := make([]T, capacity)
+	for i := 0; i < deque.size; i++ {
+		buf[i] = deque.buf[(deque.head+i)%len(deque.buf)]
+	}
+	deque.buf = buf
+	deque.head = 0
 }
 
-func (queue *LinkedQueue) Contains(i interface{}) bool {
-	node := queue.head
-	for node != nil {
-		if node.value == i {
-			return true
-		}
-		node = node.next
+func (deque *Deque[T]) Size() int {
+	return deque.size
+}
+
+func (deque *Deque[T]) IsEmpty() bool {
+	return deque.size == 0
+}
+
+func (deque *Deque[T]) PushBack(value T) {
+	if deque.size == len(deque.buf) {
+		deque.grow()
 	}
-	return false
+	deque.buf[(deque.head+deque.size)%len(deque.buf)] = value
+	deque.size++
+}
+
+func (deque *Deque[T]) PushFront(value T) {
+	if deque.size == len(deque.buf) {
+		deque.grow()
+	}
+	deque.head = (deque.head - 1 + len(deque.buf)) % len(deque.buf)
+	deque.buf[deque.head] = value
+	deque.size++
+}
+
+func (deque *Deque[T]) PeekFront() T {
+	if deque.size == 0 {
+		panic("Empty deque.")
+	}
+	return deque.buf[deque.head]
+}
+
+func (deque *Deque[T]) PeekBack() T {
+	if deque.size == 0 {
+		panic("Empty deque.")
+	}
+	return deque.buf[(deque.head+deque.size-1)%len(deque.buf)]
+}
+
+func (deque *Deque[T]) PopFront() T {
+	value := deque.PeekFront()
+	var zero T
+	deque.buf[deque.head] = zero
+	deque.head = (deque.head + 1) % len(deque.buf)
+	deque.size--
+	return value
+}
+
+func (deque *Deque[T]) PopBack() T {
+	value := deque.PeekBack()
+	var zero T
+	deque.buf[(deque.head+deque.size-1)%len(deque.buf)] = zero
+	deque.size--
+	return value
 }
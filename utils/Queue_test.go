@@ -0,0 +1,100 @@
+package utils
+
+import "testing"
+
+func TestQueue(t *testing.T) {
+	var queue Queue[int]
+	for i := 0; i < 5; i++ {
+		queue.Append(i % 3)
+	}
+	if queue.Size() != 5 || !queue.Contains(2) || queue.Contains(3) {
+		t.Fatalf("after appending: size %d, values %v", queue.Size(), queue.Values())
+	}
+	for i := 0; i < 5; i++ {
+		if v := queue.Pop(); v != i%3 {
+			t.Fatalf("pop %d: got %d, want %d", i, v, i%3)
+		}
+		// 0 and 1 are queued twice, so they stay members until their second pop
+		if i == 0 && !queue.Contains(0) {
+			t.Fatalf("0 is still queued")
+		}
+	}
+	if !queue.IsEmpty() || queue.Contains(0) {
+		t.Fatalf("queue should be empty, got %v", queue.Values())
+	}
+	queue.Append(7)
+	if queue.Peek() != 7 || queue.Size() != 1 {
+		t.Fatalf("append after emptying: got %v", queue.Values())
+	}
+}
+
+// dequeModel checks the deque against a slice holding the same values.
+func dequeModel(t *testing.T, deque *Deque[int], model []int) {
+	t.Helper()
+	if deque.Size() != len(model) {
+		t.Fatalf("size %d, want %d", deque.Size(), len(model))
+	}
+	if len(model) == 0 {
+		return
+	}
+	if deque.PeekFront() != model[0] || deque.PeekBack() != model[len(model)-1] {
+		t.Fatalf("front %d and back %d, want %d and %d", deque.PeekFront(), deque.PeekBack(), model[0], model[len(model)-1])
+	}
+}
+
+func TestDequeWrapAroundAndGrowth(t *testing.T) {
+	deque := NewDeque[int]()
+	var model []int
+	// move the head around the initial buffer of 8 several times
+	for i := 0; i < 20; i++ {
+		deque.PushBack(i)
+		model = append(model, i)
+		deque.PushBack(-i)
+		model = append(model, -i)
+		if v := deque.PopFront(); v != model[0] {
+			t.Fatalf("step %d: pop front %d, want %d", i, v, model[0])
+		}
+		model = model[1:]
+		dequeModel(t, deque, model)
+	}
+	// grow while the contents wrap past the end of the buffer
+	for i := 100; i < 130; i++ {
+		if i%2 == 0 {
+			deque.PushFront(i)
+			model = append([]int{i}, model...)
+		} else {
+			deque.PushBack(i)
+			model = append(model, i)
+		}
+		dequeModel(t, deque, model)
+	}
+	for len(model) > 0 {
+		if len(model)%2 == 0 {
+			if v := deque.PopBack(); v != model[len(model)-1] {
+				t.Fatalf("pop back %d, want %d", v, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		} else {
+			if v := deque.PopFront(); v != model[0] {
+				t.Fatalf("pop front %d, want %d", v, model[0])
+			}
+			model = model[1:]
+		}
+		dequeModel(t, deque, model)
+	}
+	if !deque.IsEmpty() {
+		t.Fatalf("deque should be empty")
+	}
+}
+
+func TestDequePushFrontOnZeroValue(t *testing.T) {
+	var deque Deque[int]
+	for i := 0; i < 9; i++ {
+		deque.PushFront(i)
+	}
+	for i := 0; i < 9; i++ {
+		if v := deque.PopBack(); v != i {
+			t.Fatalf("pop back %d, want %d", v, i)
+		}
+	}
+}
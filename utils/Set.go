@@ -0,0 +1,205 @@
+package utils
+
+/*
+Set
+
+Unordered set backed by a map.
+The zero value is an empty set ready to use.
+*/
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+func NewSet[T comparable](values ...T) *Set[T] {
+	set := &Set[T]{items: make(map[T]struct{}, len(values))}
+	for _, v := range values {
+		set.items[v] = struct{}{}
+	}
+	return set
+}
+
+func (set *Set[T]) Size() int {
+	return len(set.items)
+}
+
+func (set *Set[T]) IsEmpty() bool {
+	return len(set.items) == 0
+}
+
+/*
+Add
+
+Adds the value to the set; returns false if it was already present.
+*/
+func (set *Set[T]) Add(value T) bool {
+	if set.items == nil {
+		set.items = map[T]struct{}{}
+	}
+	if _, ok := set.items[value]; ok {
+		return false
+	}
+	set.items[value] = struct{}{}
+	return true
+}
+
+func (set *Set[T]) AddAll(values ...T) {
+	for _, v := range values {
+		set.Add(v)
+	}
+}
+
+func (set *Set[T]) Remove(value T) {
+	delete(set.items, value)
+}
+
+func (set *Set[T]) Contains(value T) bool {
+	_, ok := set.items[value]
+	return ok
+}
+
+/*
+Values
+
+Returns the members of the set in no particular order.
+*/
+func (set *Set[T]) Values() []T {
+	values := make([]T, 0, len(set.items))
+	for v := range set.items {
+		values = append(values, v)
+	}
+	return values
+}
+
+func (set *Set[T]) Copy() *Set[T] {
+	c := &Set[T]{items: make(map[T]struct{}, len(set.items))}
+	for v := range set.items {
+		c.items[v] = struct{}{}
+	}
+	return c
+}
+
+func (set *Set[T]) Union(other *Set[T]) *Set[T] {
+	union := set.Copy()
+	for v := range other.items {
+		union.items[v] = struct{}{}
+	}
+	return union
+}
+
+func (set *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	intersection := NewSet[T]()
+	for v := range set.items {
+		if other.Contains(v) {
+			intersection.items[v] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+func (set *Set[T]) Difference(other *Set[T]) *Set[T] {
+	difference := NewSet[T]()
+	for v := range set.items {
+		if !other.Contains(v) {
+			difference.items[v] = struct{}{}
+		}
+	}
+	return difference
+}
+
+func (set *Set[T]) IsSubsetOf(other *Set[T]) bool {
+	for v := range set.items {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+OrderedSet
+
+Set that remembers the order in which members were first added.
+Iteration over Values is deterministic, which keeps graph traversals reproducible.
+The zero value is an empty set ready to use.
+*/
+type OrderedSet[T comparable] struct {
+	values []T
+	index  map[T]int
+}
+
+func NewOrderedSet[T comparable](values ...T) *OrderedSet[T] {
+	set := &OrderedSet[T]{}
+	for _, v := range values {
+		set.Add(v)
+	}
+	return set
+}
+
+func (set *OrderedSet[T]) Size() int {
+	return len(set.values)
+}
+
+func (set *OrderedSet[T]) IsEmpty() bool {
+	return len(set.values) == 0
+}
+
+/*
+Add
+
+Appends the value to the set; returns false if it was already present.
+*/
+func (set *OrderedSet[T]) Add(value T) bool {
+	if set.index == nil {
+		set.index = map[T]int{}
+	}
+	if _, ok := set.index[value]; ok {
+		return false
+	}
+	set.index[value] = len(set.values)
+	set.values = append(set.values, value)
+	return true
+}
+
+func (set *OrderedSet[T]) AddAll(values ...T) {
+	for _, v := range values {
+		set.Add(v)
+	}
+}
+
+/*
+Remove
+
+Removes the value from the set, preserving the order of the remaining members.
+*/
+func (set *OrderedSet[T]) Remove(value T) {
+	i, ok := set.index[value]
+	if !ok {
+		return
+	}
+	delete(set.index, value)
+	set.values = append(set.values[:i], set.values[i+1:]...)
+	for j := i; j < len(set.values); j++ {
+		set.index[set.values[j]] = j
+	}
+}
+
+func (set *OrderedSet[T]) Contains(value T) bool {
+	_, ok := set.index[value]
+	return ok
+}
+
+/*
+Values
+
+Returns the members of the set in insertion order.
+The returned slice is a copy and may be modified by the caller.
+*/
+func (set *OrderedSet[T]) Values() []T {
+	values := make([]T, len(set.values))
+	copy(values, set.values)
+	return values
+}
+
+func (set *OrderedSet[T]) Copy() *OrderedSet[T] {
+	return NewOrderedSet(set.values...)
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSetOperations(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 4)
+	if a.Add(2) || !a.Add(5) {
+		t.Fatalf("Add should report whether the value is new")
+	}
+	cases := []struct {
+		name string
+		set  *Set[int]
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersection", a.Intersection(b), []int{3}},
+		{"difference", a.Difference(b), []int{1, 2, 5}},
+	}
+	for _, c := range cases {
+		got := c.set.Values()
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+	if !NewSet(3).IsSubsetOf(b) || a.IsSubsetOf(b) {
+		t.Errorf("IsSubsetOf is wrong")
+	}
+	var zero Set[string]
+	if !zero.IsEmpty() || zero.Contains("x") || !zero.Add("x") || !zero.Contains("x") {
+		t.Errorf("the zero value should be an empty set ready to use")
+	}
+}
+
+func TestOrderedSetKeepsInsertionOrder(t *testing.T) {
+	set := NewOrderedSet("d", "b", "a", "c", "b")
+	if got, want := set.Values(), []string{"d", "b", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	set.Remove("b")
+	set.Remove("z")
+	set.Add("e")
+	set.Add("b")
+	if got, want := set.Values(), []string{"d", "a", "c", "e", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after removal: got %v, want %v", got, want)
+	}
+	// the index must follow the shifted members, so removing one after the gap works
+	set.Remove("c")
+	if got, want := set.Values(), []string{"d", "a", "e", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after second removal: got %v, want %v", got, want)
+	}
+	values := set.Values()
+	values[0] = "x"
+	if set.Contains("x") || set.Values()[0] != "d" {
+		t.Fatalf("Values should return a copy")
+	}
+	if c := set.Copy(); !reflect.DeepEqual(c.Values(), set.Values()) {
+		t.Fatalf("copy: got %v, want %v", c.Values(), set.Values())
+	}
+}